@@ -8,6 +8,41 @@ import (
 type Config struct {
 	DatabaseURL string `json:"database_url"`
 	Port string `json:"port"`
+	Reminders ReminderConfig `json:"reminders"`
+	// JWTSecret is the HMAC key of the tokens WithAuthentication accepts.
+	// When empty every token is rejected; it must be set, and be the same
+	// on every instance, for authenticated endpoints to work.
+	JWTSecret string `json:"jwt_secret"`
+	Phone PhoneConfig `json:"phone"`
+	Trash TrashConfig `json:"trash"`
+	Pagination PaginationConfig `json:"pagination"`
+}
+
+// PaginationConfig controls list cursors
+type PaginationConfig struct {
+	// CursorSecret is the HMAC key of list cursors. When empty a random key
+	// is used per start, so cursors only work on the instance that issued
+	// them; deployments with several instances must set the same secret on
+	// each.
+	CursorSecret string `json:"cursor_secret"`
+}
+
+// TrashConfig controls how long deleted contacts can be restored
+type TrashConfig struct {
+	RetentionDays        int `json:"retention_days"`         // days before a deleted contact is purged (default 30)
+	PurgeIntervalSeconds int `json:"purge_interval_seconds"` // how often the trash is checked (default 3600)
+}
+
+// PhoneConfig controls how phone numbers are normalized
+type PhoneConfig struct {
+	DefaultRegion string `json:"default_region"` // region of numbers without a country code, e.g. "TH"
+}
+
+// ReminderConfig selects how event reminders are delivered
+type ReminderConfig struct {
+	Notifier        string `json:"notifier"`         // "log" (default) or "file"
+	File            string `json:"file"`             // output of the file notifier
+	IntervalSeconds int    `json:"interval_seconds"` // how often due reminders are checked
 }
 
 func LoadConfig(filePath string) (*Config, error) {
@@ -26,4 +26,10 @@ type Contact struct {
 	Phone         sql.NullString `json:"phone" db:"phone" validate:"omitempty,e164"`
 	ContactTypeID sql.NullInt64  `json:"contact_type_id" db:"contact_type_id" validate:"required,min=1"`
 	CategoryID    sql.NullInt64  `json:"category_id" db:"category_id" validate:"required,min=1"`
+
+	// Labeled entries stored in their own tables; Email and Phone mirror the
+	// primary ones
+	Emails    []ContactEmail   `json:"emails,omitempty" db:"-" gorm:"-" bson:"-"`
+	Phones    []ContactPhone   `json:"phones,omitempty" db:"-" gorm:"-" bson:"-"`
+	Addresses []ContactAddress `json:"addresses,omitempty" db:"-" gorm:"-" bson:"-"`
 }
@@ -0,0 +1,57 @@
+package contact
+
+import (
+	"apidesign/internal/models"
+)
+
+// ContactCategoryTree is a category with its nested subcategories
+type ContactCategoryTree struct {
+	ContactCategory
+	Children []ContactCategoryTree `json:"children"`
+}
+
+// TreeNode returns the parent link of the category
+func (c *ContactCategory) TreeNode() models.TreeNode {
+	node := models.TreeNode{ID: c.ID}
+	if c.ParentID.Valid {
+		node.ParentID = int(c.ParentID.Int64)
+	}
+	return node
+}
+
+// CategoryNodes returns the parent links of the categories
+func CategoryNodes(categories []ContactCategory) []models.TreeNode {
+	nodes := make([]models.TreeNode, len(categories))
+	for i := range categories {
+		nodes[i] = categories[i].TreeNode()
+	}
+	return nodes
+}
+
+// BuildCategoryTree nests the subtree rooted at rootID
+func BuildCategoryTree(categories []ContactCategory, rootID int) (ContactCategoryTree, bool) {
+	byID := make(map[int]ContactCategory, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+	root, ok := byID[rootID]
+	if !ok {
+		return ContactCategoryTree{}, false
+	}
+
+	children := models.Children(CategoryNodes(categories))
+	visited := make(map[int]bool)
+
+	var build func(c ContactCategory) ContactCategoryTree
+	build = func(c ContactCategory) ContactCategoryTree {
+		visited[c.ID] = true
+		tree := ContactCategoryTree{ContactCategory: c, Children: []ContactCategoryTree{}}
+		for _, childID := range children[c.ID] {
+			if !visited[childID] {
+				tree.Children = append(tree.Children, build(byID[childID]))
+			}
+		}
+		return tree
+	}
+	return build(root), true
+}
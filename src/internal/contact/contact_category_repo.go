@@ -0,0 +1,55 @@
+package contact
+
+import (
+	"context"
+
+	"apidesign/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const contactCategoriesCollection = "contact_categories"
+
+type ContactCategoryRepo struct {
+	db database.Database // Reference to the Database interface
+}
+
+// NewContactCategoryRepo creates a new ContactCategoryRepo backed by the given database
+func NewContactCategoryRepo(db database.Database) *ContactCategoryRepo {
+	return &ContactCategoryRepo{db: db}
+}
+
+// CreateCategory adds a new contact category
+func (repo *ContactCategoryRepo) CreateCategory(ctx context.Context, category *ContactCategory) error {
+	return repo.db.Create(ctx, contactCategoriesCollection, category)
+}
+
+// GetCategory retrieves a contact category by ID
+func (repo *ContactCategoryRepo) GetCategory(ctx context.Context, id int) (ContactCategory, error) {
+	var category ContactCategory
+	err := repo.db.FindOne(ctx, contactCategoriesCollection, database.NotDeleted(bson.M{"id": id}), &category)
+	return category, err
+}
+
+// UpdateCategory updates an existing contact category. Fields are written
+// explicitly so that clearing the parent moves the category to the root.
+func (repo *ContactCategoryRepo) UpdateCategory(ctx context.Context, category *ContactCategory) error {
+	return repo.db.Update(ctx, contactCategoriesCollection, bson.M{"id": category.ID}, map[string]interface{}{
+		"name":        category.Name,
+		"description": category.Description,
+		"parent_id":   category.ParentID,
+		"updated_at":  category.UpdatedAt,
+	})
+}
+
+// DeleteCategory removes a contact category
+func (repo *ContactCategoryRepo) DeleteCategory(ctx context.Context, id int) error {
+	return repo.db.Delete(ctx, contactCategoriesCollection, bson.M{"id": id})
+}
+
+// FindCategories retrieves contact categories based on conditions, limit, and offset
+func (repo *ContactCategoryRepo) FindCategories(ctx context.Context, filter bson.M, limit int64, offset int64) ([]ContactCategory, error) {
+	var categories []ContactCategory
+	err := repo.db.Find(ctx, contactCategoriesCollection, database.NotDeleted(filter), &categories, limit, offset)
+	return categories, err
+}
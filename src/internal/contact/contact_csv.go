@@ -0,0 +1,259 @@
+package contact
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// CSVContentType is the MIME type of CSV documents
+const CSVContentType = "text/csv"
+
+// FieldFullName is a pseudo field for a single name column, split into
+// first and last name on the first space
+const FieldFullName = "full_name"
+
+var ErrInvalidCSV = errors.New("invalid CSV data")
+
+// csvAliases maps normalised header names onto Contact fields for
+// auto-detection
+var csvAliases = map[string]string{
+	"firstname":     "first_name",
+	"first":         "first_name",
+	"givenname":     "first_name",
+	"lastname":      "last_name",
+	"last":          "last_name",
+	"surname":       "last_name",
+	"familyname":    "last_name",
+	"name":          FieldFullName,
+	"fullname":      FieldFullName,
+	"displayname":   FieldFullName,
+	"email":         "email",
+	"emailaddress":  "email",
+	"mail":          "email",
+	"phone":         "phone",
+	"phonenumber":   "phone",
+	"mobile":        "phone",
+	"mobilephone":   "phone",
+	"tel":           "phone",
+	"telephone":     "phone",
+	"contacttypeid": "contact_type_id",
+	"contacttype":   "contact_type_id",
+	"typeid":        "contact_type_id",
+	"categoryid":    "category_id",
+	"category":      "category_id",
+}
+
+// CSVRow is a data row read from a CSV document
+type CSVRow struct {
+	Index   int
+	Line    int
+	Record  []string
+	Contact Contact
+	Err     error
+}
+
+// CSVReader streams contacts from a CSV document whose first row is a header
+type CSVReader struct {
+	reader  *csv.Reader
+	header  []string
+	columns map[int]string
+	index   int
+}
+
+// NewCSVReader reads the header and resolves the columns. mapping goes from
+// Contact field (or full_name) to header name; with an empty mapping the
+// columns are detected from the header names.
+func NewCSVReader(r io.Reader, mapping map[string]string) (*CSVReader, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidCSV)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	columns, err := csvColumns(header, mapping)
+	if err != nil {
+		return nil, err
+	}
+	return &CSVReader{reader: reader, header: header, columns: columns}, nil
+}
+
+// Header returns the header row of the document
+func (cr *CSVReader) Header() []string {
+	return cr.header
+}
+
+// Next returns the next data row, or io.EOF at the end of the document.
+// A row that cannot be mapped onto a Contact carries the reason in Err.
+func (cr *CSVReader) Next() (CSVRow, error) {
+	for {
+		record, err := cr.reader.Read()
+		if err != nil {
+			if err != io.EOF {
+				err = fmt.Errorf("%w: %v", ErrInvalidCSV, err)
+			}
+			return CSVRow{}, err
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		line, _ := cr.reader.FieldPos(0)
+		row := CSVRow{Index: cr.index, Line: line, Record: record}
+		cr.index++
+		row.Contact, row.Err = cr.contact(record)
+		return row, nil
+	}
+}
+
+func (cr *CSVReader) contact(record []string) (Contact, error) {
+	// Columns are read in order, full_name first so that first_name and
+	// last_name override it; the same file always imports the same way
+	indexes := make([]int, 0, len(cr.columns))
+	for i := range cr.columns {
+		indexes = append(indexes, i)
+	}
+	sort.Slice(indexes, func(a, b int) bool {
+		fullA, fullB := cr.columns[indexes[a]] == FieldFullName, cr.columns[indexes[b]] == FieldFullName
+		if fullA != fullB {
+			return fullA
+		}
+		return indexes[a] < indexes[b]
+	})
+
+	var c Contact
+	for _, i := range indexes {
+		field := cr.columns[i]
+		if i >= len(record) {
+			continue
+		}
+		value := strings.TrimSpace(record[i])
+		if value == "" {
+			continue
+		}
+		switch field {
+		case FieldFullName:
+			first, last, _ := strings.Cut(value, " ")
+			c.FirstName = nullString(first)
+			c.LastName = nullString(last)
+		case "first_name":
+			c.FirstName = nullString(value)
+		case "last_name":
+			c.LastName = nullString(value)
+		case "email":
+			c.Email = nullString(value)
+		case "phone":
+			c.Phone = nullString(value)
+		case "contact_type_id", "category_id":
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || id <= 0 {
+				return c, fmt.Errorf("%s: invalid ID %q", cr.header[i], value)
+			}
+			if field == "contact_type_id" {
+				c.ContactTypeID = sql.NullInt64{Int64: id, Valid: true}
+			} else {
+				c.CategoryID = sql.NullInt64{Int64: id, Valid: true}
+			}
+		}
+	}
+	return c, nil
+}
+
+// csvColumns maps column positions onto Contact fields
+func csvColumns(header []string, mapping map[string]string) (map[int]string, error) {
+	columns := make(map[int]string)
+	mapped := make(map[string]bool)
+
+	if len(mapping) > 0 {
+		for field, name := range mapping {
+			if field != FieldFullName && !containsField(field) {
+				return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidCSV, field)
+			}
+			found := false
+			for i, h := range header {
+				if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(name)) {
+					columns[i] = field
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("%w: column %q for %s not found", ErrInvalidCSV, name, field)
+			}
+			mapped[field] = true
+		}
+	} else {
+		for i, h := range header {
+			field, ok := csvAliases[normalizeHeader(h)]
+			if !ok || mapped[field] {
+				continue
+			}
+			columns[i] = field
+			mapped[field] = true
+		}
+	}
+
+	if !mapped["email"] {
+		return nil, fmt.Errorf("%w: no column for email", ErrInvalidCSV)
+	}
+	if !mapped[FieldFullName] && (!mapped["first_name"] || !mapped["last_name"]) {
+		return nil, fmt.Errorf("%w: no columns for first and last name", ErrInvalidCSV)
+	}
+	return columns, nil
+}
+
+// WriteImportErrorsCSV writes the rejected rows as CSV: the line and reason
+// followed by the original columns
+func WriteImportErrorsCSV(w io.Writer, header []string, errs []ImportError) error {
+	cw := csv.NewWriter(w)
+	cw.Write(append([]string{"line", "error"}, header...))
+	for _, e := range errs {
+		cw.Write(append([]string{strconv.Itoa(e.Line), e.Error}, e.Record...))
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func containsField(field string) bool {
+	for _, f := range MergeFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizeHeader(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
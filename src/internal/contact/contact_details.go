@@ -0,0 +1,367 @@
+package contact
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"apidesign/internal/phone"
+)
+
+// Common labels of emails, phones and addresses. Other labels are accepted.
+const (
+	LabelWork   = "work"
+	LabelHome   = "home"
+	LabelMobile = "mobile"
+	LabelOther  = "other"
+)
+
+// ContactEmail is one of the email addresses of a contact (contact_emails)
+type ContactEmail struct {
+	ID        int    `json:"id" db:"id"`
+	ContactID int    `json:"contact_id" db:"contact_id"`
+	Label     string `json:"label" db:"label"`
+	Email     string `json:"email" db:"email"`
+	IsPrimary bool   `json:"primary" db:"is_primary"`
+}
+
+// ContactPhone is one of the phone numbers of a contact (contact_phones)
+type ContactPhone struct {
+	ID        int    `json:"id" db:"id"`
+	ContactID int    `json:"contact_id" db:"contact_id"`
+	Label     string `json:"label" db:"label"`
+	Number    string `json:"number" db:"number"`       // E.164
+	Type      string `json:"type,omitempty" db:"type"` // mobile, landline, ... when known
+	IsPrimary bool   `json:"primary" db:"is_primary"`
+}
+
+// ContactAddress is one of the postal addresses of a contact (contact_addresses)
+type ContactAddress struct {
+	ID         int    `json:"id" db:"id"`
+	ContactID  int    `json:"contact_id" db:"contact_id"`
+	Label      string `json:"label" db:"label"`
+	Street     string `json:"street,omitempty" db:"street"`
+	City       string `json:"city,omitempty" db:"city"`
+	Region     string `json:"region,omitempty" db:"region"`
+	PostalCode string `json:"postal_code,omitempty" db:"postal_code"`
+	Country    string `json:"country,omitempty" db:"country"`
+	IsPrimary  bool   `json:"primary" db:"is_primary"`
+}
+
+var phoneCleanup = regexp.MustCompile(`[^\+\d]`)
+
+// NormalizeDetails keeps Email and Phone in step with the primary entries.
+// A contact without entries gets one from Email and Phone; a collection
+// without a primary entry has its first entry made primary.
+func (c *Contact) NormalizeDetails() {
+	c.sanitizeDetails()
+	if len(c.Emails) == 0 && c.Email.Valid && c.Email.String != "" {
+		c.Emails = []ContactEmail{{Label: LabelOther, Email: c.Email.String, IsPrimary: true}}
+	}
+	if len(c.Phones) == 0 && c.Phone.Valid && c.Phone.String != "" {
+		c.Phones = []ContactPhone{{Label: LabelOther, Number: c.Phone.String, IsPrimary: true}}
+	}
+
+	for i := range c.Emails {
+		c.Emails[i].Label = normalizeLabel(c.Emails[i].Label)
+	}
+	for i := range c.Phones {
+		c.Phones[i].Label = normalizeLabel(c.Phones[i].Label)
+	}
+	for i := range c.Addresses {
+		c.Addresses[i].Label = normalizeLabel(c.Addresses[i].Label)
+	}
+
+	if i := ensurePrimary(len(c.Emails), func(i int) bool { return c.Emails[i].IsPrimary }); i >= 0 {
+		c.Emails[i].IsPrimary = true
+		c.Email = nullString(c.Emails[i].Email)
+	}
+	if i := ensurePrimary(len(c.Phones), func(i int) bool { return c.Phones[i].IsPrimary }); i >= 0 {
+		c.Phones[i].IsPrimary = true
+		c.Phone = nullString(c.Phones[i].Number)
+	}
+	if i := ensurePrimary(len(c.Addresses), func(i int) bool { return c.Addresses[i].IsPrimary }); i >= 0 {
+		c.Addresses[i].IsPrimary = true
+	}
+}
+
+// NormalizePhones rewrites Phone and every phone entry to E.164. Numbers
+// without a country code are read as numbers of region. Numbers of
+// countries the phone metadata does not cover are kept as written.
+func (c *Contact) NormalizePhones(region string) error {
+	if c.Phone.Valid && c.Phone.String != "" {
+		n, err := phone.Parse(c.Phone.String, region)
+		switch {
+		case err == nil:
+			c.Phone.String = n.E164
+		case !errors.Is(err, phone.ErrUnknownCountryCode):
+			return fmt.Errorf("phone: %w", err)
+		}
+	}
+	for i := range c.Phones {
+		n, err := phone.Parse(c.Phones[i].Number, region)
+		switch {
+		case err == nil:
+			c.Phones[i].Number = n.E164
+			c.Phones[i].Type = n.Type
+		case !errors.Is(err, phone.ErrUnknownCountryCode):
+			return fmt.Errorf("phones[%d]: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// SetPrimaryEmail makes email the primary address: an existing entry with
+// that address is flagged, otherwise the primary entry takes the address
+func (c *Contact) SetPrimaryEmail(email string) {
+	email = strings.TrimSpace(strings.ToLower(email))
+	if email == "" {
+		return
+	}
+	found := -1
+	for i := range c.Emails {
+		if c.Emails[i].Email == email {
+			found = i
+		}
+	}
+	if found < 0 {
+		for i := range c.Emails {
+			if c.Emails[i].IsPrimary {
+				c.Emails[i].Email = email
+				return
+			}
+		}
+		c.Emails = append(c.Emails, ContactEmail{Label: LabelOther, Email: email, IsPrimary: true})
+		return
+	}
+	for i := range c.Emails {
+		c.Emails[i].IsPrimary = i == found
+	}
+}
+
+// SetPrimaryPhone is SetPrimaryEmail for phone numbers
+func (c *Contact) SetPrimaryPhone(number string) {
+	number = phoneCleanup.ReplaceAllString(number, "")
+	if number == "" {
+		return
+	}
+	found := -1
+	for i := range c.Phones {
+		if c.Phones[i].Number == number {
+			found = i
+		}
+	}
+	if found < 0 {
+		for i := range c.Phones {
+			if c.Phones[i].IsPrimary {
+				c.Phones[i].Number = number
+				return
+			}
+		}
+		c.Phones = append(c.Phones, ContactPhone{Label: LabelOther, Number: number, IsPrimary: true})
+		return
+	}
+	for i := range c.Phones {
+		c.Phones[i].IsPrimary = i == found
+	}
+}
+
+// AllEmails returns Email and every email entry, without duplicates
+func (c *Contact) AllEmails() []string {
+	var emails []string
+	seen := make(map[string]bool)
+	add := func(email string) {
+		email = strings.TrimSpace(strings.ToLower(email))
+		if email != "" && !seen[email] {
+			seen[email] = true
+			emails = append(emails, email)
+		}
+	}
+	if c.Email.Valid {
+		add(c.Email.String)
+	}
+	for _, e := range c.Emails {
+		add(e.Email)
+	}
+	return emails
+}
+
+// AbsorbDetails appends the entries of other that c does not have yet. The
+// appended entries are never primary.
+func (c *Contact) AbsorbDetails(other *Contact) {
+	for _, e := range other.Emails {
+		if !c.hasEmail(e.Email) {
+			c.Emails = append(c.Emails, ContactEmail{Label: e.Label, Email: e.Email})
+		}
+	}
+	for _, p := range other.Phones {
+		if !c.hasPhone(p.Number) {
+			c.Phones = append(c.Phones, ContactPhone{Label: p.Label, Number: p.Number, Type: p.Type})
+		}
+	}
+	for _, a := range other.Addresses {
+		if !c.hasAddress(a) {
+			a.ID, a.ContactID, a.IsPrimary = 0, 0, false
+			c.Addresses = append(c.Addresses, a)
+		}
+	}
+}
+
+func (c *Contact) hasEmail(email string) bool {
+	for _, e := range c.Emails {
+		if e.Email == email {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Contact) hasPhone(number string) bool {
+	for _, p := range c.Phones {
+		if p.Number == number {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Contact) hasAddress(a ContactAddress) bool {
+	for _, b := range c.Addresses {
+		if strings.EqualFold(a.Street, b.Street) && strings.EqualFold(a.City, b.City) &&
+			strings.EqualFold(a.PostalCode, b.PostalCode) && strings.EqualFold(a.Country, b.Country) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeDetails trims the entries like Sanitize does the contact
+func (c *Contact) sanitizeDetails() {
+	for i := range c.Emails {
+		c.Emails[i].Email = strings.TrimSpace(strings.ToLower(c.Emails[i].Email))
+	}
+	for i := range c.Phones {
+		c.Phones[i].Number = phoneCleanup.ReplaceAllString(strings.TrimSpace(c.Phones[i].Number), "")
+	}
+	for i := range c.Addresses {
+		a := &c.Addresses[i]
+		a.Street = strings.TrimSpace(a.Street)
+		a.City = strings.TrimSpace(a.City)
+		a.Region = strings.TrimSpace(a.Region)
+		a.PostalCode = strings.TrimSpace(a.PostalCode)
+		a.Country = strings.TrimSpace(a.Country)
+	}
+}
+
+// validateDetails checks every entry and that each collection has at most
+// one primary entry
+func (c *Contact) validateDetails() error {
+	primaries := 0
+	seen := make(map[string]bool)
+	for i, e := range c.Emails {
+		if err := validateEmail(nullString(e.Email)); err != nil {
+			return fmt.Errorf("emails[%d]: %w", i, err)
+		}
+		if err := validateLabel(e.Label); err != nil {
+			return fmt.Errorf("emails[%d]: %w", i, err)
+		}
+		if seen[e.Email] {
+			return fmt.Errorf("emails[%d]: %s is listed twice", i, e.Email)
+		}
+		seen[e.Email] = true
+		if e.IsPrimary {
+			primaries++
+		}
+	}
+	if primaries > 1 {
+		return errors.New("only one email can be primary")
+	}
+
+	primaries = 0
+	seen = make(map[string]bool)
+	for i, p := range c.Phones {
+		if p.Number == "" {
+			return fmt.Errorf("phones[%d]: number is required", i)
+		}
+		if err := validatePhone(nullString(p.Number)); err != nil {
+			return fmt.Errorf("phones[%d]: %w", i, err)
+		}
+		if err := validateLabel(p.Label); err != nil {
+			return fmt.Errorf("phones[%d]: %w", i, err)
+		}
+		if seen[p.Number] {
+			return fmt.Errorf("phones[%d]: %s is listed twice", i, p.Number)
+		}
+		seen[p.Number] = true
+		if p.IsPrimary {
+			primaries++
+		}
+	}
+	if primaries > 1 {
+		return errors.New("only one phone can be primary")
+	}
+
+	primaries = 0
+	for i, a := range c.Addresses {
+		if err := a.Validate(); err != nil {
+			return fmt.Errorf("addresses[%d]: %w", i, err)
+		}
+		if a.IsPrimary {
+			primaries++
+		}
+	}
+	if primaries > 1 {
+		return errors.New("only one address can be primary")
+	}
+	return nil
+}
+
+// Validate performs validation on the ContactAddress struct
+func (a *ContactAddress) Validate() error {
+	if a.Street == "" && a.City == "" && a.PostalCode == "" && a.Country == "" {
+		return errors.New("address is empty")
+	}
+	fields := []struct{ name, value string }{
+		{"street", a.Street},
+		{"city", a.City},
+		{"region", a.Region},
+		{"postal code", a.PostalCode},
+		{"country", a.Country},
+	}
+	for _, f := range fields {
+		if len(f.value) > 255 {
+			return fmt.Errorf("%s must be at most 255 characters", f.name)
+		}
+	}
+	return validateLabel(a.Label)
+}
+
+// ensurePrimary returns the index of the first primary entry, or 0 when no
+// entry is primary, or -1 for an empty collection
+func ensurePrimary(n int, primary func(i int) bool) int {
+	for i := 0; i < n; i++ {
+		if primary(i) {
+			return i
+		}
+	}
+	if n > 0 {
+		return 0
+	}
+	return -1
+}
+
+func normalizeLabel(label string) string {
+	label = strings.ToLower(strings.TrimSpace(label))
+	if label == "" {
+		return LabelOther
+	}
+	return label
+}
+
+func validateLabel(label string) error {
+	if len(label) > 50 {
+		return errors.New("label must be at most 50 characters")
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package contact
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	contactEmailsCollection    = "contact_emails"
+	contactPhonesCollection    = "contact_phones"
+	contactAddressesCollection = "contact_addresses"
+)
+
+// FindEmails retrieves email entries based on conditions
+func (repo *ContactRepo) FindEmails(ctx context.Context, filter bson.M) ([]ContactEmail, error) {
+	var emails []ContactEmail
+	err := repo.db.Find(ctx, contactEmailsCollection, filter, &emails, 0, 0)
+	return emails, err
+}
+
+// FindPhones retrieves phone entries based on conditions
+func (repo *ContactRepo) FindPhones(ctx context.Context, filter bson.M) ([]ContactPhone, error) {
+	var phones []ContactPhone
+	err := repo.db.Find(ctx, contactPhonesCollection, filter, &phones, 0, 0)
+	return phones, err
+}
+
+// FindAddresses retrieves address entries based on conditions
+func (repo *ContactRepo) FindAddresses(ctx context.Context, filter bson.M) ([]ContactAddress, error) {
+	var addresses []ContactAddress
+	err := repo.db.Find(ctx, contactAddressesCollection, filter, &addresses, 0, 0)
+	return addresses, err
+}
+
+// LoadDetails fills in the emails, phones and addresses of the contacts
+func (repo *ContactRepo) LoadDetails(ctx context.Context, contacts []Contact) error {
+	if len(contacts) == 0 {
+		return nil
+	}
+	index := make(map[int]int, len(contacts))
+	ids := make([]int, len(contacts))
+	for i, c := range contacts {
+		index[c.ID] = i
+		ids[i] = c.ID
+		contacts[i].Emails = []ContactEmail{}
+		contacts[i].Phones = []ContactPhone{}
+		contacts[i].Addresses = []ContactAddress{}
+	}
+	filter := bson.M{"contact_id": bson.M{"$in": ids}}
+
+	emails, err := repo.FindEmails(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, e := range emails {
+		c := &contacts[index[e.ContactID]]
+		c.Emails = append(c.Emails, e)
+	}
+	phones, err := repo.FindPhones(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, p := range phones {
+		c := &contacts[index[p.ContactID]]
+		c.Phones = append(c.Phones, p)
+	}
+	addresses, err := repo.FindAddresses(ctx, filter)
+	if err != nil {
+		return err
+	}
+	for _, a := range addresses {
+		c := &contacts[index[a.ContactID]]
+		c.Addresses = append(c.Addresses, a)
+	}
+	return nil
+}
+
+// ReplaceDetails stores the emails, phones and addresses of the contact in
+// place of the ones it had. Run it in a transaction.
+func (repo *ContactRepo) ReplaceDetails(ctx context.Context, c *Contact) error {
+	if err := repo.DeleteDetails(ctx, c.ID); err != nil {
+		return err
+	}
+	for i := range c.Emails {
+		c.Emails[i].ID = 0
+		c.Emails[i].ContactID = c.ID
+		if err := repo.db.Create(ctx, contactEmailsCollection, &c.Emails[i]); err != nil {
+			return err
+		}
+	}
+	for i := range c.Phones {
+		c.Phones[i].ID = 0
+		c.Phones[i].ContactID = c.ID
+		if err := repo.db.Create(ctx, contactPhonesCollection, &c.Phones[i]); err != nil {
+			return err
+		}
+	}
+	for i := range c.Addresses {
+		c.Addresses[i].ID = 0
+		c.Addresses[i].ContactID = c.ID
+		if err := repo.db.Create(ctx, contactAddressesCollection, &c.Addresses[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteDetails removes the emails, phones and addresses of a contact
+func (repo *ContactRepo) DeleteDetails(ctx context.Context, contactID int) error {
+	filter := bson.M{"contact_id": contactID}
+	for _, collection := range []string{contactEmailsCollection, contactPhonesCollection, contactAddressesCollection} {
+		if err := repo.db.Delete(ctx, collection, filter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
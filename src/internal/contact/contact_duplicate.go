@@ -0,0 +1,255 @@
+package contact
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Duplicate match reasons
+const (
+	MatchName  = "name"
+	MatchEmail = "email"
+	MatchPhone = "phone"
+)
+
+// Score weights; a pair scores 1.0 when name, email and phone all match
+const (
+	nameWeight  = 0.4
+	emailWeight = 0.3
+	phoneWeight = 0.3
+
+	// phoneSuffixLength digits are compared so that national and
+	// international forms of a number match
+	phoneSuffixLength = 9
+)
+
+// MergeFields lists the fields a merge can take from any of the contacts
+var MergeFields = []string{"first_name", "last_name", "email", "phone", "contact_type_id", "category_id"}
+
+// DuplicatePair is two contacts that probably describe the same person
+type DuplicatePair struct {
+	Contacts [2]Contact `json:"contacts"`
+	Score    float64    `json:"score"`
+	Reasons  []string   `json:"reasons"`
+}
+
+// NormalizeName lowercases a name and keeps only letters and single spaces
+func NormalizeName(name string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(strings.TrimSpace(name)) {
+		switch {
+		case unicode.IsLetter(r):
+			if space && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			space = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '.':
+			space = true
+		}
+	}
+	return b.String()
+}
+
+// NormalizePhone keeps the trailing digits of a phone number
+func NormalizePhone(phone string) string {
+	var digits []rune
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, r)
+		}
+	}
+	if len(digits) > phoneSuffixLength {
+		digits = digits[len(digits)-phoneSuffixLength:]
+	}
+	return string(digits)
+}
+
+// EmailLocalPart returns the lowercased part before @, without +tags or dots
+func EmailLocalPart(email string) string {
+	local := strings.ToLower(strings.TrimSpace(email))
+	if at := strings.LastIndex(local, "@"); at >= 0 {
+		local = local[:at]
+	}
+	if plus := strings.Index(local, "+"); plus >= 0 {
+		local = local[:plus]
+	}
+	return strings.ReplaceAll(local, ".", "")
+}
+
+// fullName is the normalized "first last" of the contact
+func (c *Contact) fullName() string {
+	return strings.TrimSpace(NormalizeName(c.FirstName.String) + " " + NormalizeName(c.LastName.String))
+}
+
+// DuplicateScore rates how likely a and b are the same person, from 0 to 1
+func DuplicateScore(a, b *Contact) (float64, []string) {
+	var score float64
+	var reasons []string
+
+	nameA, nameB := a.fullName(), b.fullName()
+	if nameA != "" && nameB != "" {
+		similarity := stringSimilarity(nameA, nameB)
+		// First and last name entered the other way round
+		swapped := strings.TrimSpace(NormalizeName(b.LastName.String) + " " + NormalizeName(b.FirstName.String))
+		if s := stringSimilarity(nameA, swapped); s > similarity {
+			similarity = s
+		}
+		if similarity >= 0.8 {
+			score += nameWeight * similarity
+			reasons = append(reasons, MatchName)
+		}
+	}
+
+	localA, localB := EmailLocalPart(a.Email.String), EmailLocalPart(b.Email.String)
+	if localA != "" && localA == localB {
+		score += emailWeight
+		reasons = append(reasons, MatchEmail)
+	}
+
+	phoneA, phoneB := NormalizePhone(a.Phone.String), NormalizePhone(b.Phone.String)
+	if len(phoneA) >= 6 && phoneA == phoneB {
+		score += phoneWeight
+		reasons = append(reasons, MatchPhone)
+	}
+
+	return float64(int(score*100+0.5)) / 100, reasons
+}
+
+// FindDuplicates returns the pairs scoring at least threshold, best first.
+// Only contacts sharing a name prefix, email local part or phone are compared.
+func FindDuplicates(contacts []Contact, threshold float64) []DuplicatePair {
+	blocks := make(map[string][]int)
+	for i := range contacts {
+		for _, key := range blockingKeys(&contacts[i]) {
+			blocks[key] = append(blocks[key], i)
+		}
+	}
+
+	seen := make(map[[2]int]bool)
+	pairs := []DuplicatePair{}
+	for _, members := range blocks {
+		for x := 0; x < len(members); x++ {
+			for y := x + 1; y < len(members); y++ {
+				i, j := members[x], members[y]
+				if i > j {
+					i, j = j, i
+				}
+				if i == j || seen[[2]int{i, j}] {
+					continue
+				}
+				seen[[2]int{i, j}] = true
+
+				score, reasons := DuplicateScore(&contacts[i], &contacts[j])
+				if score >= threshold && len(reasons) > 0 {
+					pairs = append(pairs, DuplicatePair{
+						Contacts: [2]Contact{contacts[i], contacts[j]},
+						Score:    score,
+						Reasons:  reasons,
+					})
+				}
+			}
+		}
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Score != pairs[j].Score {
+			return pairs[i].Score > pairs[j].Score
+		}
+		return pairs[i].Contacts[0].ID < pairs[j].Contacts[0].ID
+	})
+	return pairs
+}
+
+// blockingKeys groups contacts that are worth comparing
+func blockingKeys(c *Contact) []string {
+	var keys []string
+	first, last := NormalizeName(c.FirstName.String), NormalizeName(c.LastName.String)
+	if first != "" && last != "" {
+		keys = append(keys, "n:"+prefix(last, 3)+"/"+prefix(first, 1), "n:"+prefix(first, 3)+"/"+prefix(last, 1))
+	}
+	if local := EmailLocalPart(c.Email.String); local != "" {
+		keys = append(keys, "e:"+local)
+	}
+	if phone := NormalizePhone(c.Phone.String); len(phone) >= 6 {
+		keys = append(keys, "p:"+phone)
+	}
+	return keys
+}
+
+func prefix(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) > n {
+		runes = runes[:n]
+	}
+	return string(runes)
+}
+
+// stringSimilarity is 1 minus the Levenshtein distance relative to the longer string
+func stringSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	longest := max(len(ra), len(rb))
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+// IsFieldEmpty reports whether a merge field has no value
+func (c *Contact) IsFieldEmpty(field string) bool {
+	switch field {
+	case "first_name":
+		return !c.FirstName.Valid || strings.TrimSpace(c.FirstName.String) == ""
+	case "last_name":
+		return !c.LastName.Valid || strings.TrimSpace(c.LastName.String) == ""
+	case "email":
+		return !c.Email.Valid || strings.TrimSpace(c.Email.String) == ""
+	case "phone":
+		return !c.Phone.Valid || strings.TrimSpace(c.Phone.String) == ""
+	case "contact_type_id":
+		return !c.ContactTypeID.Valid || c.ContactTypeID.Int64 == 0
+	case "category_id":
+		return !c.CategoryID.Valid || c.CategoryID.Int64 == 0
+	}
+	return true
+}
+
+// CopyField copies one merge field from another contact
+func (c *Contact) CopyField(from *Contact, field string) error {
+	switch field {
+	case "first_name":
+		c.FirstName = from.FirstName
+	case "last_name":
+		c.LastName = from.LastName
+	case "email":
+		c.Email = from.Email
+	case "phone":
+		c.Phone = from.Phone
+	case "contact_type_id":
+		c.ContactTypeID = from.ContactTypeID
+	case "category_id":
+		c.CategoryID = from.CategoryID
+	default:
+		return fmt.Errorf("unknown field %q", field)
+	}
+	return nil
+}
@@ -2,6 +2,7 @@
 package contact
 import (
 	"context"
+	"time"
 	"apidesign/internal/database"
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -10,32 +11,108 @@ type ContactRepo struct {
     db database.Database // Reference to the Database interface
 }
 
+// NewContactRepo creates a new ContactRepo backed by the given database
+func NewContactRepo(db database.Database) *ContactRepo {
+    return &ContactRepo{db: db}
+}
+
+// WithTx returns a copy of the repo that runs inside the given transaction
+func (repo *ContactRepo) WithTx(tx database.Database) *ContactRepo {
+    return &ContactRepo{db: tx}
+}
+
+// Transaction runs fn in a database transaction
+func (repo *ContactRepo) Transaction(ctx context.Context, fn func(ctx context.Context, tx database.Database) error) error {
+    return repo.db.Transaction(ctx, fn)
+}
+
 
-// CreateContact adds a new contact to the repository
-func (repo *ContactRepo) CreateContact(ctx context.Context, contact Contact) error {
+// CreateContact adds a new contact to the repository and sets its ID
+func (repo *ContactRepo) CreateContact(ctx context.Context, contact *Contact) error {
     return repo.db.Create(ctx, "contacts", contact) // Call Create from Database interface
 }
 
-// GetContact retrieves a contact by ID
+// GetContact retrieves a contact by ID, unless it is in the trash
 func (repo *ContactRepo) GetContact(ctx context.Context, id int) (Contact, error) {
     var contact Contact
-    err := repo.db.FindOne(ctx, "contacts", bson.M{"id": id}, &contact) // Call FindOne from Database interface
+    err := repo.db.FindOne(ctx, "contacts", database.NotDeleted(bson.M{"id": id}), &contact) // Call FindOne from Database interface
     return contact, err
 }
 
-// UpdateContact updates an existing contact
+// GetContactWith retrieves a contact outside the trash reading only the
+// given columns, all when nil
+func (repo *ContactRepo) GetContactWith(ctx context.Context, id int, fields []string) (Contact, error) {
+    var contact Contact
+    err := repo.db.FindOneWith(ctx, "contacts", database.NotDeleted(bson.M{"id": id}), &contact, fields)
+    return contact, err
+}
+
+// UpdateContact updates an existing contact. Columns are listed explicitly
+// so that cleared (NULL) values are written too.
 func (repo *ContactRepo) UpdateContact(ctx context.Context, contact Contact) error {
-    return repo.db.Update(ctx, "contacts", bson.M{"id": contact.ID}, contact) // Call Update from Database interface
+    return repo.db.Update(ctx, "contacts", bson.M{"id": contact.ID}, map[string]interface{}{
+        "first_name":      contact.FirstName,
+        "last_name":       contact.LastName,
+        "email":           contact.Email,
+        "phone":           contact.Phone,
+        "contact_type_id": contact.ContactTypeID,
+        "category_id":     contact.CategoryID,
+        "updated_at":      contact.UpdatedAt,
+    }) // Call Update from Database interface
+}
+
+// SoftDeleteContact moves a contact to the trash
+func (repo *ContactRepo) SoftDeleteContact(ctx context.Context, id int, at time.Time) error {
+    return repo.db.Update(ctx, "contacts", bson.M{"id": id}, map[string]interface{}{database.DeletedAtField: at})
 }
 
-// DeleteContact removes a contact from the repository
+// RestoreContact takes a contact out of the trash
+func (repo *ContactRepo) RestoreContact(ctx context.Context, id int, at time.Time) error {
+    return repo.db.Update(ctx, "contacts", bson.M{"id": id}, map[string]interface{}{
+        database.DeletedAtField: nil,
+        "updated_at":            at,
+    })
+}
+
+// DeleteContact permanently removes a contact from the repository
 func (repo *ContactRepo) DeleteContact(ctx context.Context, id int) error {
     return repo.db.Delete(ctx, "contacts", bson.M{"id": id}) // Call Delete from Database interface
 }
 
-// FindContacts retrieves contacts based on conditions, limit, and offset
+// FindContacts retrieves contacts outside the trash based on conditions, limit, and offset
 func (repo *ContactRepo) FindContacts(ctx context.Context, filter bson.M, limit int64, offset int64) ([]Contact, error) {
     var contacts []Contact
-    err := repo.db.Find(ctx, "contacts", filter, &contacts, limit, offset) // Call Find from Database interface
+    err := repo.db.Find(ctx, "contacts", database.NotDeleted(filter), &contacts, limit, offset) // Call Find from Database interface
+    return contacts, err
+}
+
+// FindContactsWith retrieves contacts outside the trash in the given order
+func (repo *ContactRepo) FindContactsWith(ctx context.Context, filter bson.M, opts database.FindOptions) ([]Contact, error) {
+    var contacts []Contact
+    err := repo.db.FindWith(ctx, "contacts", database.NotDeleted(filter), &contacts, opts)
+    return contacts, err
+}
+
+// CountContacts returns the number of contacts outside the trash matching filter
+func (repo *ContactRepo) CountContacts(ctx context.Context, filter bson.M) (int64, error) {
+    return repo.db.Count(ctx, "contacts", database.NotDeleted(filter))
+}
+
+// FindDeletedContactsWith retrieves contacts in the trash in the given order
+func (repo *ContactRepo) FindDeletedContactsWith(ctx context.Context, filter bson.M, opts database.FindOptions) ([]Contact, error) {
+    var contacts []Contact
+    err := repo.db.FindWith(ctx, "contacts", database.OnlyDeleted(filter), &contacts, opts)
+    return contacts, err
+}
+
+// CountDeletedContacts returns the number of contacts in the trash matching filter
+func (repo *ContactRepo) CountDeletedContacts(ctx context.Context, filter bson.M) (int64, error) {
+    return repo.db.Count(ctx, "contacts", database.OnlyDeleted(filter))
+}
+
+// FindDeletedContacts retrieves contacts in the trash based on conditions, limit, and offset
+func (repo *ContactRepo) FindDeletedContacts(ctx context.Context, filter bson.M, limit int64, offset int64) ([]Contact, error) {
+    var contacts []Contact
+    err := repo.db.Find(ctx, "contacts", database.OnlyDeleted(filter), &contacts, limit, offset)
     return contacts, err
 }
\ No newline at end of file
@@ -0,0 +1,138 @@
+package contact
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+)
+
+// ContactRevision is a versioned snapshot of a contact taken after each
+// change (contact_revisions)
+type ContactRevision struct {
+	ID        int `json:"id" db:"id"`
+	ContactID int `json:"contact_id" db:"contact_id"`
+	// Revision counts the contact's versions from 1
+	Revision int `json:"revision" db:"revision"`
+	// Actor is the JWT subject of the user who made the change, if known
+	Actor     string    `json:"actor,omitempty" db:"actor"`
+	Data      string    `json:"-" db:"data"` // JSON snapshot
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// Contact is the decoded snapshot
+	Contact *Contact `json:"contact,omitempty" db:"-" gorm:"-" bson:"-"`
+}
+
+// FieldChange is a field that differs between two revisions
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+// RevisionFields lists the fields compared by DiffContacts
+var RevisionFields = append(append([]string{}, MergeFields...), "emails", "phones", "addresses")
+
+// NewContactRevision snapshots c. Entry IDs are left out since they change
+// whenever the entries are rewritten.
+func NewContactRevision(c Contact, revision int, actor string, at time.Time) (ContactRevision, error) {
+	snapshot := c
+	snapshot.Emails = append([]ContactEmail{}, c.Emails...)
+	for i := range snapshot.Emails {
+		snapshot.Emails[i].ID, snapshot.Emails[i].ContactID = 0, 0
+	}
+	snapshot.Phones = append([]ContactPhone{}, c.Phones...)
+	for i := range snapshot.Phones {
+		snapshot.Phones[i].ID, snapshot.Phones[i].ContactID = 0, 0
+	}
+	snapshot.Addresses = append([]ContactAddress{}, c.Addresses...)
+	for i := range snapshot.Addresses {
+		snapshot.Addresses[i].ID, snapshot.Addresses[i].ContactID = 0, 0
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return ContactRevision{}, err
+	}
+	return ContactRevision{
+		ContactID: c.ID,
+		Revision:  revision,
+		Actor:     actor,
+		Data:      string(data),
+		CreatedAt: at,
+	}, nil
+}
+
+// Decode fills in Contact from the stored snapshot
+func (r *ContactRevision) Decode() error {
+	var c Contact
+	if err := json.Unmarshal([]byte(r.Data), &c); err != nil {
+		return err
+	}
+	// Empty collections are omitted from the JSON
+	if c.Emails == nil {
+		c.Emails = []ContactEmail{}
+	}
+	if c.Phones == nil {
+		c.Phones = []ContactPhone{}
+	}
+	if c.Addresses == nil {
+		c.Addresses = []ContactAddress{}
+	}
+	r.Contact = &c
+	return nil
+}
+
+// DiffContacts lists the fields of RevisionFields that differ from a to b
+func DiffContacts(a, b *Contact) []FieldChange {
+	return DiffFields(a, b, RevisionFields)
+}
+
+// DiffFields lists the given fields that differ from a to b
+func DiffFields(a, b *Contact, fields []string) []FieldChange {
+	changes := []FieldChange{}
+	for _, field := range fields {
+		from, to := a.fieldValue(field), b.fieldValue(field)
+		if !reflect.DeepEqual(from, to) {
+			changes = append(changes, FieldChange{Field: field, From: from, To: to})
+		}
+	}
+	return changes
+}
+
+// fieldValue returns a field as a plain value, nil when unset
+func (c *Contact) fieldValue(field string) interface{} {
+	switch field {
+	case "emails", "phones", "addresses":
+	default:
+		if c.IsFieldEmpty(field) {
+			return nil
+		}
+	}
+	switch field {
+	case "first_name":
+		return c.FirstName.String
+	case "last_name":
+		return c.LastName.String
+	case "email":
+		return c.Email.String
+	case "phone":
+		return c.Phone.String
+	case "contact_type_id":
+		return c.ContactTypeID.Int64
+	case "category_id":
+		return c.CategoryID.Int64
+	case "emails":
+		if len(c.Emails) > 0 {
+			return c.Emails
+		}
+	case "phones":
+		if len(c.Phones) > 0 {
+			return c.Phones
+		}
+	case "addresses":
+		if len(c.Addresses) > 0 {
+			return c.Addresses
+		}
+	}
+	return nil
+}
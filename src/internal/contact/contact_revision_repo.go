@@ -0,0 +1,69 @@
+package contact
+
+import (
+	"context"
+
+	"apidesign/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const revisionsCollection = "contact_revisions"
+
+type RevisionRepo struct {
+	db database.Database // Reference to the Database interface
+}
+
+// NewRevisionRepo creates a new RevisionRepo backed by the given database
+func NewRevisionRepo(db database.Database) *RevisionRepo {
+	return &RevisionRepo{db: db}
+}
+
+// WithTx returns a copy of the repo that runs inside the given transaction
+func (repo *RevisionRepo) WithTx(tx database.Database) *RevisionRepo {
+	return &RevisionRepo{db: tx}
+}
+
+// CreateRevision stores a revision
+func (repo *RevisionRepo) CreateRevision(ctx context.Context, revision *ContactRevision) error {
+	return repo.db.Create(ctx, revisionsCollection, revision)
+}
+
+// GetRevision retrieves revision number rev of a contact
+func (repo *RevisionRepo) GetRevision(ctx context.Context, contactID, rev int) (ContactRevision, error) {
+	var revision ContactRevision
+	err := repo.db.FindOne(ctx, revisionsCollection, bson.M{"contact_id": contactID, "revision": rev}, &revision)
+	return revision, err
+}
+
+// FindRevisions retrieves revisions based on conditions
+func (repo *RevisionRepo) FindRevisions(ctx context.Context, filter bson.M) ([]ContactRevision, error) {
+	var revisions []ContactRevision
+	err := repo.db.Find(ctx, revisionsCollection, filter, &revisions, 0, 0)
+	return revisions, err
+}
+
+// LatestRevision retrieves the newest revision of a contact; ID is 0 when
+// the contact has none
+func (repo *RevisionRepo) LatestRevision(ctx context.Context, contactID int) (ContactRevision, error) {
+	var revisions []ContactRevision
+	err := repo.db.FindWith(ctx, revisionsCollection, bson.M{"contact_id": contactID}, &revisions, database.FindOptions{
+		Limit: 1,
+		Sort:  []database.SortField{{Field: "revision", Desc: true}},
+	})
+	if err != nil || len(revisions) == 0 {
+		return ContactRevision{}, err
+	}
+	return revisions[0], nil
+}
+
+// DeleteRevisions removes the revisions matching filter
+func (repo *RevisionRepo) DeleteRevisions(ctx context.Context, filter bson.M) error {
+	return repo.db.Delete(ctx, revisionsCollection, filter)
+}
+
+// LockContact serializes revision numbering of a contact until the
+// surrounding transaction ends
+func (repo *RevisionRepo) LockContact(ctx context.Context, contactID int) error {
+	return repo.db.Lock(ctx, "contacts", bson.M{"id": contactID})
+}
@@ -0,0 +1,52 @@
+package contact
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"apidesign/internal/models"
+)
+
+// MaxTagLength is the longest tag name accepted
+const MaxTagLength = 50
+
+// Tag is a label on contacts. Curated tags are managed through /tags;
+// free-form tags are created the first time a contact is tagged with a new name.
+type Tag struct {
+	models.BaseModel
+	Name    string `json:"name" db:"name"`
+	Curated bool   `json:"curated" db:"curated"`
+}
+
+// ContactTag links a contact to a tag (contact_tags)
+type ContactTag struct {
+	ID        int       `json:"id" db:"id"`
+	ContactID int       `json:"contact_id" db:"contact_id"`
+	TagID     int       `json:"tag_id" db:"tag_id"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TagUsage is a tag with the number of contacts carrying it
+type TagUsage struct {
+	Tag
+	Count int `json:"count"`
+}
+
+// NormalizeTagName lower-cases a tag name and collapses its whitespace, so
+// "VIP " and "vip" are the same tag
+func NormalizeTagName(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+// Validate performs validation on the Tag struct
+func (t *Tag) Validate() error {
+	if t.Name == "" {
+		return errors.New("tag name is required")
+	}
+	if utf8.RuneCountInString(t.Name) > MaxTagLength {
+		return errors.New("tag name must be at most 50 characters")
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package contact
+
+import (
+	"context"
+
+	"apidesign/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	tagsCollection        = "tags"
+	contactTagsCollection = "contact_tags"
+)
+
+// TagRepo stores tags and the contact_tags join rows. It only relies on
+// the Database interface, so the join works on every backend.
+type TagRepo struct {
+	db database.Database // Reference to the Database interface
+}
+
+// NewTagRepo creates a new TagRepo backed by the given database
+func NewTagRepo(db database.Database) *TagRepo {
+	return &TagRepo{db: db}
+}
+
+// WithTx returns a copy of the repo that runs inside the given transaction
+func (repo *TagRepo) WithTx(tx database.Database) *TagRepo {
+	return &TagRepo{db: tx}
+}
+
+// Transaction runs fn in a database transaction
+func (repo *TagRepo) Transaction(ctx context.Context, fn func(ctx context.Context, tx database.Database) error) error {
+	return repo.db.Transaction(ctx, fn)
+}
+
+// CreateTag adds a new tag
+func (repo *TagRepo) CreateTag(ctx context.Context, tag *Tag) error {
+	return repo.db.Create(ctx, tagsCollection, tag)
+}
+
+// GetTag retrieves a tag by ID
+func (repo *TagRepo) GetTag(ctx context.Context, id int) (Tag, error) {
+	var tag Tag
+	err := repo.db.FindOne(ctx, tagsCollection, database.NotDeleted(bson.M{"id": id}), &tag)
+	return tag, err
+}
+
+// UpdateTag updates an existing tag
+func (repo *TagRepo) UpdateTag(ctx context.Context, tag *Tag) error {
+	return repo.db.Update(ctx, tagsCollection, bson.M{"id": tag.ID}, map[string]interface{}{
+		"name":       tag.Name,
+		"curated":    tag.Curated,
+		"updated_at": tag.UpdatedAt,
+	})
+}
+
+// DeleteTag removes a tag and its links to contacts
+func (repo *TagRepo) DeleteTag(ctx context.Context, id int) error {
+	if err := repo.db.Delete(ctx, contactTagsCollection, bson.M{"tag_id": id}); err != nil {
+		return err
+	}
+	return repo.db.Delete(ctx, tagsCollection, bson.M{"id": id})
+}
+
+// FindTags retrieves tags based on conditions, limit, and offset
+func (repo *TagRepo) FindTags(ctx context.Context, filter bson.M, limit int64, offset int64) ([]Tag, error) {
+	var tags []Tag
+	err := repo.db.Find(ctx, tagsCollection, database.NotDeleted(filter), &tags, limit, offset)
+	return tags, err
+}
+
+// AddContactTag links a contact to a tag
+func (repo *TagRepo) AddContactTag(ctx context.Context, link *ContactTag) error {
+	return repo.db.Create(ctx, contactTagsCollection, link)
+}
+
+// DeleteContactTags removes the links matching filter
+func (repo *TagRepo) DeleteContactTags(ctx context.Context, filter bson.M) error {
+	return repo.db.Delete(ctx, contactTagsCollection, filter)
+}
+
+// FindContactTags retrieves contact/tag links based on conditions
+func (repo *TagRepo) FindContactTags(ctx context.Context, filter bson.M) ([]ContactTag, error) {
+	var links []ContactTag
+	err := repo.db.Find(ctx, contactTagsCollection, filter, &links, 0, 0)
+	return links, err
+}
@@ -0,0 +1,103 @@
+package contact
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Kinds of timeline entries
+const (
+	TimelineNote    = "note"
+	TimelineCall    = "call"
+	TimelineMeeting = "meeting"
+	TimelineEvent   = "event"
+)
+
+// Timeline orders
+const (
+	TimelineNewestFirst = "desc"
+	TimelineOldestFirst = "asc"
+)
+
+// TimelineEntry is an activity recorded against a contact (contact_timeline)
+type TimelineEntry struct {
+	ID         int       `json:"id" db:"id"`
+	ContactID  int       `json:"contact_id" db:"contact_id"`
+	Kind       string    `json:"kind" db:"kind"`
+	Subject    string    `json:"subject,omitempty" db:"subject"`
+	Body       string    `json:"body,omitempty" db:"body"`
+	OccurredAt time.Time `json:"occurred_at" db:"occurred_at"`
+
+	// DurationMinutes is the length of a call or meeting
+	DurationMinutes int `json:"duration_minutes,omitempty" db:"duration_minutes"`
+	// EventID is the event a participation entry refers to
+	EventID int `json:"event_id,omitempty" db:"event_id"`
+
+	// Author is the JWT subject of the user who wrote the entry
+	Author    string    `json:"author" db:"author"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// TimelineParams holds the filters for reading a timeline
+type TimelineParams struct {
+	Kind   string
+	From   time.Time
+	To     time.Time
+	Order  string
+	Limit  int64
+	Offset int64
+}
+
+// IsValidTimelineKind reports whether kind is one of the timeline kinds
+func IsValidTimelineKind(kind string) bool {
+	switch kind {
+	case TimelineNote, TimelineCall, TimelineMeeting, TimelineEvent:
+		return true
+	}
+	return false
+}
+
+// Validate performs validation on the TimelineEntry struct
+func (e *TimelineEntry) Validate() error {
+	if !IsValidTimelineKind(e.Kind) {
+		return fmt.Errorf("invalid kind %q", e.Kind)
+	}
+	if len(e.Subject) > 255 {
+		return errors.New("subject must be at most 255 characters")
+	}
+	if len(e.Body) > 10000 {
+		return errors.New("body must be at most 10000 characters")
+	}
+	if e.Kind == TimelineNote && e.Subject == "" && e.Body == "" {
+		return errors.New("a note needs a subject or a body")
+	}
+	if e.DurationMinutes < 0 {
+		return errors.New("duration must be non-negative")
+	}
+	if e.Kind == TimelineEvent && e.EventID <= 0 {
+		return errors.New("event ID is required for event entries")
+	}
+	if e.Kind != TimelineEvent && e.EventID != 0 {
+		return errors.New("only event entries refer to an event")
+	}
+	return nil
+}
+
+// Validate checks the timeline parameters
+func (p TimelineParams) Validate() error {
+	if p.Kind != "" && !IsValidTimelineKind(p.Kind) {
+		return fmt.Errorf("invalid kind %q", p.Kind)
+	}
+	if p.Order != "" && p.Order != TimelineNewestFirst && p.Order != TimelineOldestFirst {
+		return fmt.Errorf("order must be %s or %s", TimelineNewestFirst, TimelineOldestFirst)
+	}
+	if p.Limit < 0 || p.Offset < 0 {
+		return errors.New("limit and offset must be non-negative")
+	}
+	if !p.From.IsZero() && !p.To.IsZero() && p.To.Before(p.From) {
+		return errors.New("to must be after from")
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package contact
+
+import (
+	"context"
+
+	"apidesign/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const timelineCollection = "contact_timeline"
+
+type TimelineRepo struct {
+	db database.Database // Reference to the Database interface
+}
+
+// NewTimelineRepo creates a new TimelineRepo backed by the given database
+func NewTimelineRepo(db database.Database) *TimelineRepo {
+	return &TimelineRepo{db: db}
+}
+
+// WithTx returns a copy of the repo that runs inside the given transaction
+func (repo *TimelineRepo) WithTx(tx database.Database) *TimelineRepo {
+	return &TimelineRepo{db: tx}
+}
+
+// CreateEntry adds a timeline entry
+func (repo *TimelineRepo) CreateEntry(ctx context.Context, entry *TimelineEntry) error {
+	return repo.db.Create(ctx, timelineCollection, entry)
+}
+
+// GetEntry retrieves a timeline entry by ID
+func (repo *TimelineRepo) GetEntry(ctx context.Context, id int) (TimelineEntry, error) {
+	var entry TimelineEntry
+	err := repo.db.FindOne(ctx, timelineCollection, bson.M{"id": id}, &entry)
+	return entry, err
+}
+
+// UpdateEntry updates an existing timeline entry
+func (repo *TimelineRepo) UpdateEntry(ctx context.Context, entry *TimelineEntry) error {
+	return repo.db.Update(ctx, timelineCollection, bson.M{"id": entry.ID}, map[string]interface{}{
+		"contact_id":       entry.ContactID,
+		"kind":             entry.Kind,
+		"subject":          entry.Subject,
+		"body":             entry.Body,
+		"occurred_at":      entry.OccurredAt,
+		"duration_minutes": entry.DurationMinutes,
+		"event_id":         entry.EventID,
+		"updated_at":       entry.UpdatedAt,
+	})
+}
+
+// DeleteEntries removes the entries matching filter
+func (repo *TimelineRepo) DeleteEntries(ctx context.Context, filter bson.M) error {
+	return repo.db.Delete(ctx, timelineCollection, filter)
+}
+
+// FindEntries retrieves timeline entries based on conditions
+func (repo *TimelineRepo) FindEntries(ctx context.Context, filter bson.M) ([]TimelineEntry, error) {
+	var entries []TimelineEntry
+	err := repo.db.Find(ctx, timelineCollection, filter, &entries, 0, 0)
+	return entries, err
+}
+
+// FindEntriesWith retrieves timeline entries in the given order
+func (repo *TimelineRepo) FindEntriesWith(ctx context.Context, filter bson.M, opts database.FindOptions) ([]TimelineEntry, error) {
+	var entries []TimelineEntry
+	err := repo.db.FindWith(ctx, timelineCollection, filter, &entries, opts)
+	return entries, err
+}
@@ -0,0 +1,53 @@
+package contact
+
+import (
+	"context"
+
+	"apidesign/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const contactTypesCollection = "contact_types"
+
+type ContactTypeRepo struct {
+	db database.Database // Reference to the Database interface
+}
+
+// NewContactTypeRepo creates a new ContactTypeRepo backed by the given database
+func NewContactTypeRepo(db database.Database) *ContactTypeRepo {
+	return &ContactTypeRepo{db: db}
+}
+
+// CreateContactType adds a new contact type
+func (repo *ContactTypeRepo) CreateContactType(ctx context.Context, contactType *ContactType) error {
+	return repo.db.Create(ctx, contactTypesCollection, contactType)
+}
+
+// GetContactType retrieves a contact type by ID
+func (repo *ContactTypeRepo) GetContactType(ctx context.Context, id int) (ContactType, error) {
+	var contactType ContactType
+	err := repo.db.FindOne(ctx, contactTypesCollection, database.NotDeleted(bson.M{"id": id}), &contactType)
+	return contactType, err
+}
+
+// UpdateContactType updates an existing contact type
+func (repo *ContactTypeRepo) UpdateContactType(ctx context.Context, contactType *ContactType) error {
+	return repo.db.Update(ctx, contactTypesCollection, bson.M{"id": contactType.ID}, map[string]interface{}{
+		"name":        contactType.Name,
+		"description": contactType.Description,
+		"updated_at":  contactType.UpdatedAt,
+	})
+}
+
+// DeleteContactType removes a contact type
+func (repo *ContactTypeRepo) DeleteContactType(ctx context.Context, id int) error {
+	return repo.db.Delete(ctx, contactTypesCollection, bson.M{"id": id})
+}
+
+// FindContactTypes retrieves contact types based on conditions, limit, and offset
+func (repo *ContactTypeRepo) FindContactTypes(ctx context.Context, filter bson.M, limit int64, offset int64) ([]ContactType, error) {
+	var contactTypes []ContactType
+	err := repo.db.Find(ctx, contactTypesCollection, database.NotDeleted(filter), &contactTypes, limit, offset)
+	return contactTypes, err
+}
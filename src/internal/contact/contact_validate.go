@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"strings"
 
+	"apidesign/internal/database"
+
 	"github.com/go-playground/validator/v10"
 )
 
@@ -19,13 +21,56 @@ var (
 	ErrInvalidCategory    = errors.New("category ID is required")
 )
 
+// MaxSearchLimit is the largest page of a contact search
+const MaxSearchLimit = 100
+
+// Contact fields that are stored in their own tables
+const (
+	FieldEmails    = "emails"
+	FieldPhones    = "phones"
+	FieldAddresses = "addresses"
+)
+
+// ContactColumns are the columns of the contacts table
+var ContactColumns = database.Columns(Contact{})
+
+// ContactSortFields are the fields contacts can be sorted by
+var ContactSortFields = ContactColumns
+
+// ContactFields are the fields ?fields= can select: the columns and the
+// labeled emails, phones and addresses
+var ContactFields = append(database.Columns(Contact{}), FieldEmails, FieldPhones, FieldAddresses)
+
+// WantsDetails reports whether fields select emails, phones or addresses;
+// no fields select everything
+func WantsDetails(fields []string) bool {
+	return len(fields) == 0 || hasField(fields, FieldEmails) || hasField(fields, FieldPhones) || hasField(fields, FieldAddresses)
+}
+
+// SearchContactsParams holds the filters, page and order of a contact search
 type SearchContactsParams struct {
-	Limit        int
-	Offset       int
-	Email        string
-	Phone        string
-	ContactType  int
-	Category     int
+	FirstName   string
+	LastName    string
+	Email       string
+	Phone       string
+	ContactType int64
+	Category    int64
+	Limit       int64
+	Offset      int64
+
+	// Sort orders the contacts; they are ordered by ID when empty
+	Sort []database.SortField
+	// Cursor continues from a previous page instead of skipping Offset
+	Cursor *database.Cursor
+	// Fields selects the fields read, all when empty
+	Fields []string
+
+	// IncludeSubcategories matches contacts in any subcategory of Category
+	IncludeSubcategories bool
+
+	// Tags matches contacts with any or all of the named tags, as TagMatch says
+	Tags     []string
+	TagMatch string
 }
 
 // Custom validator instance
@@ -81,6 +126,11 @@ func (c *Contact) Validate() error {
 		return err
 	}
 
+	// Every email, phone and address entry
+	if err := c.validateDetails(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -139,9 +189,15 @@ func ValidateSearchParams(params SearchContactsParams) error {
 	if params.Limit < 0 {
 		return errors.New("limit must be non-negative")
 	}
+	if params.Limit > MaxSearchLimit {
+		return fmt.Errorf("limit must be at most %d", MaxSearchLimit)
+	}
 	if params.Offset < 0 {
 		return errors.New("offset must be non-negative")
 	}
+	if params.Cursor != nil && params.Offset > 0 {
+		return errors.New("use either a cursor or an offset")
+	}
 
 	// Validate email format if provided
 	if params.Email != "" {
@@ -166,6 +222,21 @@ func ValidateSearchParams(params SearchContactsParams) error {
 	if params.Category < 0 {
 		return errors.New("category ID must be non-negative")
 	}
+	if params.IncludeSubcategories && params.Category == 0 {
+		return errors.New("subcategories need a category")
+	}
+
+	// Sort fields and fields are interpolated into queries
+	for _, f := range params.Sort {
+		if !hasField(ContactSortFields, f.Field) {
+			return fmt.Errorf("cannot sort by %q", f.Field)
+		}
+	}
+	for _, f := range params.Fields {
+		if !hasField(ContactFields, f) {
+			return fmt.Errorf("unknown field %q", f)
+		}
+	}
 
 	return nil
 }
@@ -202,6 +273,7 @@ func (c *Contact) Sanitize() {
 		phone = regexp.MustCompile(`[^\+\d]`).ReplaceAllString(phone, "")
 		c.Phone.String = phone
 	}
+	c.sanitizeDetails()
 }
 
 // IsComplete checks if all required fields are present
@@ -223,3 +295,37 @@ func (c *Contact) String() string {
 		c.Email.String,
 	)
 }
+
+// Validate performs validation on the ContactCategory struct
+func (c *ContactCategory) Validate() error {
+	if len(strings.TrimSpace(c.Name)) < 2 || len(strings.TrimSpace(c.Name)) > 100 {
+		return errors.New("name is required and must be between 2 and 100 characters")
+	}
+	if c.Description.Valid && len(c.Description.String) > 500 {
+		return errors.New("description must be at most 500 characters")
+	}
+	if c.ParentID.Valid && c.ParentID.Int64 < 1 {
+		return errors.New("parent ID must be positive")
+	}
+	return nil
+}
+
+// Validate performs validation on the ContactType struct
+func (t *ContactType) Validate() error {
+	if len(strings.TrimSpace(t.Name)) < 2 || len(strings.TrimSpace(t.Name)) > 100 {
+		return errors.New("name is required and must be between 2 and 100 characters")
+	}
+	if t.Description.Valid && len(t.Description.String) > 500 {
+		return errors.New("description must be at most 500 characters")
+	}
+	return nil
+}
+
+func hasField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
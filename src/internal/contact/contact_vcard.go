@@ -0,0 +1,469 @@
+package contact
+
+import (
+	"bufio"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// VCardContentType is the MIME type of vCard documents
+const VCardContentType = "text/vcard"
+
+// Supported vCard versions. 3.0 is the default for exports since it is the
+// one phones and mail clients import most reliably.
+const (
+	VCardVersion3 = "3.0"
+	VCardVersion4 = "4.0"
+)
+
+// Custom properties that carry fields vCard has no slot for
+const (
+	vcardPropContactType = "X-APIDESIGN-CONTACT-TYPE-ID"
+	vcardPropCategory    = "X-APIDESIGN-CATEGORY-ID"
+)
+
+var ErrInvalidVCard = errors.New("invalid vCard data")
+
+// VCard is a card read from a vCard document
+type VCard struct {
+	Index   int
+	Line    int
+	UID     string
+	Contact Contact
+}
+
+// ImportError reports a record of an import file that could not be imported
+type ImportError struct {
+	Index int    `json:"index"`
+	UID   string `json:"uid,omitempty"`
+	Line  int    `json:"line,omitempty"`
+	Error string `json:"error"`
+
+	// Record holds the original columns of a rejected CSV row
+	Record []string `json:"record,omitempty"`
+}
+
+// vcardProperty is a single content line: [group.]NAME;PARAM=VALUE:value
+type vcardProperty struct {
+	Name   string
+	Params map[string][]string
+	Value  string
+	Line   int
+}
+
+// IsValidVCardVersion reports whether version is one of the supported versions
+func IsValidVCardVersion(version string) bool {
+	return version == VCardVersion3 || version == VCardVersion4
+}
+
+// VCardUID is the UID written for a contact
+func (c *Contact) VCardUID() string {
+	return fmt.Sprintf("contact-%d@apidesign", c.ID)
+}
+
+// MarshalVCard writes contacts as a vCard document of the given version
+func MarshalVCard(w io.Writer, contacts []Contact, version string) error {
+	if version == "" {
+		version = VCardVersion3
+	}
+	if !IsValidVCardVersion(version) {
+		return fmt.Errorf("%w: unsupported version %q", ErrInvalidVCard, version)
+	}
+
+	bw := bufio.NewWriter(w)
+	for i := range contacts {
+		c := &contacts[i]
+		writeVCardLine(bw, "BEGIN:VCARD")
+		writeVCardLine(bw, "VERSION:"+version)
+		writeVCardLine(bw, "UID:"+c.VCardUID())
+		writeVCardLine(bw, "FN:"+escapeVCardText(strings.TrimSpace(c.FirstName.String+" "+c.LastName.String)))
+		writeVCardLine(bw, "N:"+escapeVCardText(c.LastName.String)+";"+escapeVCardText(c.FirstName.String)+";;;")
+		emails, phones := c.Emails, c.Phones
+		if len(emails) == 0 && c.Email.Valid && c.Email.String != "" {
+			emails = []ContactEmail{{Email: c.Email.String, IsPrimary: true}}
+		}
+		if len(phones) == 0 && c.Phone.Valid && c.Phone.String != "" {
+			phones = []ContactPhone{{Number: c.Phone.String, IsPrimary: true}}
+		}
+		for _, e := range emails {
+			params := vcardTypeParams(e.Label, e.IsPrimary, version)
+			if version != VCardVersion4 {
+				params = ";TYPE=INTERNET" + params
+			}
+			writeVCardLine(bw, "EMAIL"+params+":"+escapeVCardText(e.Email))
+		}
+		for _, p := range phones {
+			params := vcardTypeParams(p.Label, p.IsPrimary, version)
+			if version == VCardVersion4 {
+				writeVCardLine(bw, "TEL;VALUE=uri"+params+":tel:"+p.Number)
+			} else {
+				writeVCardLine(bw, "TEL"+params+":"+p.Number)
+			}
+		}
+		for _, a := range c.Addresses {
+			// ADR is pobox;extended;street;locality;region;code;country
+			writeVCardLine(bw, "ADR"+vcardTypeParams(a.Label, a.IsPrimary, version)+":;;"+
+				escapeVCardText(a.Street)+";"+escapeVCardText(a.City)+";"+escapeVCardText(a.Region)+";"+
+				escapeVCardText(a.PostalCode)+";"+escapeVCardText(a.Country))
+		}
+		if c.ContactTypeID.Valid {
+			writeVCardLine(bw, vcardPropContactType+":"+strconv.FormatInt(c.ContactTypeID.Int64, 10))
+		}
+		if c.CategoryID.Valid {
+			writeVCardLine(bw, vcardPropCategory+":"+strconv.FormatInt(c.CategoryID.Int64, 10))
+		}
+		if !c.UpdatedAt.IsZero() {
+			writeVCardLine(bw, "REV:"+c.UpdatedAt.UTC().Format("20060102T150405Z"))
+		}
+		writeVCardLine(bw, "END:VCARD")
+	}
+	return bw.Flush()
+}
+
+// ParseVCards reads every card of a vCard 3.0 or 4.0 document. Cards that
+// cannot be mapped onto a Contact are reported in the returned errors; the
+// error is only set when the document itself is unreadable.
+func ParseVCards(r io.Reader) ([]VCard, []ImportError, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []vcardProperty
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if lineNo == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+		if text == "" {
+			continue
+		}
+		// Lines starting with a space or tab continue the previous line
+		if (text[0] == ' ' || text[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1].Value += text[1:]
+			continue
+		}
+		lines = append(lines, vcardProperty{Value: text, Line: lineNo})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidVCard, err)
+	}
+
+	var cards []VCard
+	var errs []ImportError
+	var props []vcardProperty
+	var cardErr error
+	inCard := false
+	start := 0
+	index := 0
+
+	for _, raw := range lines {
+		prop, err := parseVCardProperty(raw.Value)
+		prop.Line = raw.Line
+		if err != nil {
+			if inCard && cardErr == nil {
+				cardErr = fmt.Errorf("line %d: %v", raw.Line, err)
+			}
+			continue
+		}
+
+		switch {
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VCARD"):
+			if inCard {
+				return nil, nil, fmt.Errorf("%w: line %d: nested BEGIN:VCARD", ErrInvalidVCard, raw.Line)
+			}
+			inCard = true
+			start = raw.Line
+			props = nil
+			cardErr = nil
+		case prop.Name == "END" && strings.EqualFold(prop.Value, "VCARD"):
+			if !inCard {
+				return nil, nil, fmt.Errorf("%w: line %d: END:VCARD without BEGIN", ErrInvalidVCard, raw.Line)
+			}
+			card := VCard{Index: index, Line: start, UID: firstVCardValue(props, "UID")}
+			if cardErr == nil {
+				card.Contact, cardErr = vcardContact(props)
+			}
+			if cardErr != nil {
+				errs = append(errs, ImportError{Index: index, UID: card.UID, Line: start, Error: cardErr.Error()})
+			} else {
+				cards = append(cards, card)
+			}
+			inCard = false
+			index++
+		case inCard:
+			props = append(props, prop)
+		}
+	}
+	if inCard {
+		return nil, nil, fmt.Errorf("%w: line %d: BEGIN:VCARD without END", ErrInvalidVCard, start)
+	}
+	if index == 0 {
+		return nil, nil, fmt.Errorf("%w: no cards found", ErrInvalidVCard)
+	}
+	return cards, errs, nil
+}
+
+// vcardContact maps the properties of a card onto a Contact
+func vcardContact(props []vcardProperty) (Contact, error) {
+	var c Contact
+
+	version := firstVCardValue(props, "VERSION")
+	if version != "" && !IsValidVCardVersion(version) {
+		return c, fmt.Errorf("unsupported vCard version %q", version)
+	}
+
+	// N is family;given;additional;prefixes;suffixes. FN is the fallback for
+	// cards that only carry a display name.
+	if n := findVCardProperty(props, "N"); n != nil {
+		parts := splitVCardValue(n.Value, ';')
+		if len(parts) > 1 {
+			c.FirstName = nullString(unescapeVCardText(parts[1]))
+		}
+		c.LastName = nullString(unescapeVCardText(parts[0]))
+	}
+	if !c.FirstName.Valid && !c.LastName.Valid {
+		fn := strings.Fields(unescapeVCardText(firstVCardValue(props, "FN")))
+		if len(fn) > 0 {
+			c.FirstName = nullString(fn[0])
+			c.LastName = nullString(strings.Join(fn[1:], " "))
+		}
+	}
+
+	// Every EMAIL, TEL and ADR becomes an entry; the preferred one is primary
+	preferredEmail := preferredVCardProperty(props, "EMAIL")
+	preferredTel := preferredVCardProperty(props, "TEL")
+	preferredAdr := preferredVCardProperty(props, "ADR")
+	for i := range props {
+		prop := &props[i]
+		if strings.TrimSpace(prop.Value) == "" {
+			continue
+		}
+		switch prop.Name {
+		case "EMAIL":
+			c.Emails = append(c.Emails, ContactEmail{
+				Label:     vcardLabel(prop),
+				Email:     strings.TrimSpace(unescapeVCardText(prop.Value)),
+				IsPrimary: prop == preferredEmail,
+			})
+		case "TEL":
+			c.Phones = append(c.Phones, ContactPhone{
+				Label:     vcardLabel(prop),
+				Number:    strings.TrimPrefix(strings.TrimSpace(unescapeVCardText(prop.Value)), "tel:"),
+				IsPrimary: prop == preferredTel,
+			})
+		case "ADR":
+			parts := splitVCardValue(prop.Value, ';')
+			for len(parts) < 7 {
+				parts = append(parts, "")
+			}
+			c.Addresses = append(c.Addresses, ContactAddress{
+				Label:      vcardLabel(prop),
+				Street:     unescapeVCardText(parts[2]),
+				City:       unescapeVCardText(parts[3]),
+				Region:     unescapeVCardText(parts[4]),
+				PostalCode: unescapeVCardText(parts[5]),
+				Country:    unescapeVCardText(parts[6]),
+				IsPrimary:  prop == preferredAdr,
+			})
+		}
+	}
+	if preferredEmail != nil {
+		c.Email = nullString(unescapeVCardText(preferredEmail.Value))
+	}
+	if preferredTel != nil {
+		c.Phone = nullString(strings.TrimPrefix(unescapeVCardText(preferredTel.Value), "tel:"))
+	}
+
+	var err error
+	if c.ContactTypeID, err = vcardID(props, vcardPropContactType); err != nil {
+		return c, err
+	}
+	if c.CategoryID, err = vcardID(props, vcardPropCategory); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// vcardID reads an optional positive ID property
+func vcardID(props []vcardProperty, name string) (sql.NullInt64, error) {
+	raw := firstVCardValue(props, name)
+	if raw == "" {
+		return sql.NullInt64{}, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id <= 0 {
+		return sql.NullInt64{}, fmt.Errorf("invalid %s %q", name, raw)
+	}
+	return sql.NullInt64{Int64: id, Valid: true}, nil
+}
+
+func parseVCardProperty(line string) (vcardProperty, error) {
+	prop := vcardProperty{Params: map[string][]string{}}
+
+	inQuotes := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		}
+		if c == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return prop, fmt.Errorf("malformed content line %q", line)
+	}
+
+	head := line[:colon]
+	prop.Value = line[colon+1:]
+
+	parts := splitVCardValue(head, ';')
+	name := parts[0]
+	// Drop the group prefix, e.g. item1.EMAIL
+	if dot := strings.LastIndex(name, "."); dot >= 0 {
+		name = name[dot+1:]
+	}
+	prop.Name = strings.ToUpper(name)
+	for _, param := range parts[1:] {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			// vCard 2.1 style bare parameters are types, e.g. TEL;CELL
+			name, value = "TYPE", param
+		}
+		name = strings.ToUpper(name)
+		for _, v := range splitVCardValue(value, ',') {
+			prop.Params[name] = append(prop.Params[name], strings.Trim(v, `"`))
+		}
+	}
+	return prop, nil
+}
+
+func findVCardProperty(props []vcardProperty, name string) *vcardProperty {
+	for i := range props {
+		if props[i].Name == name {
+			return &props[i]
+		}
+	}
+	return nil
+}
+
+func firstVCardValue(props []vcardProperty, name string) string {
+	if prop := findVCardProperty(props, name); prop != nil {
+		return strings.TrimSpace(prop.Value)
+	}
+	return ""
+}
+
+// preferredVCardProperty returns the property marked preferred (TYPE=PREF in
+// 3.0, PREF=1 in 4.0) or else the first one with a value
+func preferredVCardProperty(props []vcardProperty, name string) *vcardProperty {
+	var first *vcardProperty
+	for i := range props {
+		prop := &props[i]
+		if prop.Name != name || strings.TrimSpace(prop.Value) == "" {
+			continue
+		}
+		if first == nil {
+			first = prop
+		}
+		if len(prop.Params["PREF"]) > 0 {
+			return prop
+		}
+		for _, t := range prop.Params["TYPE"] {
+			if strings.EqualFold(t, "pref") {
+				return prop
+			}
+		}
+	}
+	return first
+}
+
+// vcardLabel maps the TYPE parameter of a property onto an entry label
+func vcardLabel(prop *vcardProperty) string {
+	for _, t := range prop.Params["TYPE"] {
+		switch strings.ToLower(t) {
+		case "work":
+			return LabelWork
+		case "home":
+			return LabelHome
+		case "cell":
+			return LabelMobile
+		}
+	}
+	return LabelOther
+}
+
+// vcardTypeParams writes the label and the primary flag as parameters
+func vcardTypeParams(label string, primary bool, version string) string {
+	var params string
+	switch label {
+	case LabelWork, LabelHome:
+		params = ";TYPE=" + strings.ToUpper(label)
+	case LabelMobile:
+		params = ";TYPE=CELL"
+	}
+	if primary {
+		if version == VCardVersion4 {
+			params += ";PREF=1"
+		} else {
+			params += ";TYPE=PREF"
+		}
+	}
+	return params
+}
+
+// splitVCardValue splits on sep, skipping escaped separators and quoted text
+func splitVCardValue(value string, sep byte) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\':
+			i++
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == sep && !inQuotes:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+func nullString(value string) sql.NullString {
+	value = strings.TrimSpace(value)
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func writeVCardLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Do not split a multi-byte UTF-8 sequence
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = 74
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func escapeVCardText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+func unescapeVCardText(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(value)
+}
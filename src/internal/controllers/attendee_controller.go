@@ -0,0 +1,192 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"apidesign/internal/event"
+	"apidesign/internal/services"
+)
+
+type AttendeeController struct {
+	Service *services.AttendeeService
+}
+
+// attendeeRequest is the body of attendee create and update requests
+type attendeeRequest struct {
+	ContactID  int    `json:"contact_id"`
+	RSVPStatus string `json:"rsvp_status"`
+	Role       string `json:"role"`
+}
+
+// ListAttendees handles GET /events/{id}/attendees?rsvp_status=
+func (ac *AttendeeController) ListAttendees(w http.ResponseWriter, r *http.Request) {
+	eventID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	attendees, err := ac.Service.ListAttendees(r.Context(), eventID, r.URL.Query().Get("rsvp_status"), limit, offset)
+	if err != nil {
+		writeAttendeeError(w, err)
+		return
+	}
+	if attendees == nil {
+		attendees = []event.Attendee{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attendees)
+}
+
+// AddAttendee handles POST /events/{id}/attendees
+func (ac *AttendeeController) AddAttendee(w http.ResponseWriter, r *http.Request) {
+	eventID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req attendeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	attendee := event.Attendee{
+		EventID:    eventID,
+		ContactID:  req.ContactID,
+		RSVPStatus: req.RSVPStatus,
+		Role:       req.Role,
+	}
+	if err := ac.Service.AddAttendee(r.Context(), &attendee); err != nil {
+		writeAttendeeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(attendee)
+}
+
+// UpdateAttendee handles PUT /events/{id}/attendees/{contactId}
+func (ac *AttendeeController) UpdateAttendee(w http.ResponseWriter, r *http.Request) {
+	eventID, contactID, err := attendeePath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req attendeeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	attendee, err := ac.Service.UpdateAttendee(r.Context(), eventID, contactID, req.RSVPStatus, req.Role)
+	if err != nil {
+		writeAttendeeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attendee)
+}
+
+// CheckIn handles POST /events/{id}/attendees/{contactId}/checkin
+func (ac *AttendeeController) CheckIn(w http.ResponseWriter, r *http.Request) {
+	eventID, contactID, err := attendeePath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	attendee, err := ac.Service.CheckIn(r.Context(), eventID, contactID)
+	if err != nil {
+		writeAttendeeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attendee)
+}
+
+// RemoveAttendee handles DELETE /events/{id}/attendees/{contactId}
+func (ac *AttendeeController) RemoveAttendee(w http.ResponseWriter, r *http.Request) {
+	eventID, contactID, err := attendeePath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := ac.Service.RemoveAttendee(r.Context(), eventID, contactID); err != nil {
+		writeAttendeeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListWaitlist handles GET /events/{id}/waitlist
+func (ac *AttendeeController) ListWaitlist(w http.ResponseWriter, r *http.Request) {
+	eventID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	attendees, err := ac.Service.ListWaitlist(r.Context(), eventID)
+	if err != nil {
+		writeAttendeeError(w, err)
+		return
+	}
+	if attendees == nil {
+		attendees = []event.Attendee{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attendees)
+}
+
+// ListContactEvents handles GET /contacts/{id}/events
+func (ac *AttendeeController) ListContactEvents(w http.ResponseWriter, r *http.Request) {
+	contactID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	events, err := ac.Service.ListContactEvents(r.Context(), contactID, limit, offset)
+	if err != nil {
+		writeAttendeeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(events)
+}
+
+// attendeePath reads the {id} and {contactId} route variables
+func attendeePath(r *http.Request) (int, int, error) {
+	eventID, err := pathID(r)
+	if err != nil {
+		return 0, 0, err
+	}
+	contactID, err := routeInt(r, "contactId")
+	if err != nil {
+		return 0, 0, err
+	}
+	return eventID, contactID, nil
+}
+
+// writeAttendeeError maps service errors to HTTP status codes
+func writeAttendeeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrEventNotFound),
+		errors.Is(err, services.ErrContactNotFound),
+		errors.Is(err, services.ErrAttendeeNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidAttendee):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrAttendeeExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
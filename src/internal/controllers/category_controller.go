@@ -0,0 +1,352 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"apidesign/internal/contact"
+	"apidesign/internal/event"
+	"apidesign/internal/services"
+)
+
+type ContactCategoryController struct {
+	Service  *services.ContactCategoryService
+	Contacts *services.ContactService
+}
+
+// CreateCategory handles POST /contact-categories
+func (cc *ContactCategoryController) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var c contact.ContactCategory
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := cc.Service.CreateCategory(r.Context(), &c); err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
+
+// GetCategory handles GET /contact-categories/{id}
+func (cc *ContactCategoryController) GetCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c, err := cc.Service.GetCategory(r.Context(), id)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// UpdateCategory handles PUT /contact-categories/{id}
+func (cc *ContactCategoryController) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var c contact.ContactCategory
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.ID = id
+	if err := cc.Service.UpdateCategory(r.Context(), &c); err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// DeleteCategory handles DELETE /contact-categories/{id}
+func (cc *ContactCategoryController) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := cc.Service.DeleteCategory(r.Context(), id); err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCategories handles GET /contact-categories?parent=&limit=&offset=
+func (cc *ContactCategoryController) ListCategories(w http.ResponseWriter, r *http.Request) {
+	parentID, err := queryInt(r.URL.Query(), "parent")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	categories, err := cc.Service.ListCategories(r.Context(), parentID, limit, offset)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	writeCategories(w, categories)
+}
+
+// GetTree handles GET /contact-categories/{id}/tree
+func (cc *ContactCategoryController) GetTree(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tree, err := cc.Service.GetTree(r.Context(), id)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tree)
+}
+
+// GetDescendants handles GET /contact-categories/{id}/descendants
+func (cc *ContactCategoryController) GetDescendants(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	categories, err := cc.Service.GetDescendants(r.Context(), id)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	writeCategories(w, categories)
+}
+
+// GetAncestors handles GET /contact-categories/{id}/ancestors
+func (cc *ContactCategoryController) GetAncestors(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	categories, err := cc.Service.GetAncestors(r.Context(), id)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	writeCategories(w, categories)
+}
+
+// ListContacts handles GET /contact-categories/{id}/contacts?include_subcategories=&tags=&tag_match=&limit=&offset=
+func (cc *ContactCategoryController) ListContacts(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err := cc.Service.GetCategory(r.Context(), id); err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	contacts, err := cc.Contacts.SearchContacts(r.Context(), contact.SearchContactsParams{
+		Category:             int64(id),
+		IncludeSubcategories: r.URL.Query().Get("include_subcategories") == "true",
+		Tags:                 queryList(r.URL.Query(), "tags"),
+		TagMatch:             r.URL.Query().Get("tag_match"),
+		Limit:                limit,
+		Offset:               offset,
+	})
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	if contacts == nil {
+		contacts = []contact.Contact{}
+	}
+	if ok, version := acceptsVCard(r); ok {
+		writeVCard(w, contacts, version, fmt.Sprintf("contact-category-%d.vcf", id))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(contacts)
+}
+
+type EventCategoryController struct {
+	Service *services.EventCategoryService
+}
+
+// CreateCategory handles POST /event-categories
+func (ec *EventCategoryController) CreateCategory(w http.ResponseWriter, r *http.Request) {
+	var c event.EventCategory
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := ec.Service.CreateCategory(r.Context(), &c); err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(c)
+}
+
+// GetCategory handles GET /event-categories/{id}
+func (ec *EventCategoryController) GetCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c, err := ec.Service.GetCategory(r.Context(), id)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// UpdateCategory handles PUT /event-categories/{id}
+func (ec *EventCategoryController) UpdateCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var c event.EventCategory
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	c.ID = id
+	if err := ec.Service.UpdateCategory(r.Context(), &c); err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(c)
+}
+
+// DeleteCategory handles DELETE /event-categories/{id}
+func (ec *EventCategoryController) DeleteCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := ec.Service.DeleteCategory(r.Context(), id); err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListCategories handles GET /event-categories?parent=&limit=&offset=
+func (ec *EventCategoryController) ListCategories(w http.ResponseWriter, r *http.Request) {
+	parentID, err := queryInt(r.URL.Query(), "parent")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	categories, err := ec.Service.ListCategories(r.Context(), parentID, limit, offset)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	writeCategories(w, categories)
+}
+
+// GetTree handles GET /event-categories/{id}/tree
+func (ec *EventCategoryController) GetTree(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tree, err := ec.Service.GetTree(r.Context(), id)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tree)
+}
+
+// GetDescendants handles GET /event-categories/{id}/descendants
+func (ec *EventCategoryController) GetDescendants(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	categories, err := ec.Service.GetDescendants(r.Context(), id)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	writeCategories(w, categories)
+}
+
+// GetAncestors handles GET /event-categories/{id}/ancestors
+func (ec *EventCategoryController) GetAncestors(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	categories, err := ec.Service.GetAncestors(r.Context(), id)
+	if err != nil {
+		writeCategoryError(w, err)
+		return
+	}
+	writeCategories(w, categories)
+}
+
+// writeCategories encodes a category list, rendering nil as []
+func writeCategories[T any](w http.ResponseWriter, categories []T) {
+	if categories == nil {
+		categories = []T{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(categories)
+}
+
+// writeCategoryError maps service errors to HTTP status codes
+func writeCategoryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrCategoryNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidCategory), errors.Is(err, services.ErrInvalidTag),
+		errors.Is(err, services.ErrInvalidSearch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrCategoryCycle),
+		errors.Is(err, services.ErrCategoryHasChildren),
+		errors.Is(err, services.ErrCategoryInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
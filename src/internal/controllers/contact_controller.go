@@ -3,56 +3,91 @@ package controllers
 import (
 	
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"github.com/gorilla/mux"
 	"apidesign/internal/services"
 	"apidesign/internal/contact"
+	"apidesign/internal/database"
+	"apidesign/internal/pagination"
 )
 
 type ContactController struct {
 	Service *services.ContactService
+	// Cursors signs the cursors of contact lists
+	Cursors *pagination.Codec
 }
 
 // Update the CreateContact method to use Gorilla Mux
 func (cc *ContactController) CreateContact(w http.ResponseWriter, r *http.Request) {
+	ctx, err := phoneContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	var newContact contact.Contact
 	if err := json.NewDecoder(r.Body).Decode(&newContact); err != nil { // Updated to use json decoder
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if err := cc.Service.CreateContact(r.Context(), newContact); err != nil { // Added context from request
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := cc.Service.CreateContact(ctx, &newContact); err != nil { // Added context from request
+		writeContactError(w, err)
 		return
 	}
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(newContact) // Updated to use json encoder
 }
 
-// Update the GetContact method to use Gorilla Mux
+// GetContact handles GET /contacts/{id}?fields=
 func (cc *ContactController) GetContact(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)               // Get variables from the request
 	id, _ := strconv.Atoi(vars["id"]) // Updated to use Gorilla Mux
-	contact, err := cc.Service.GetContactByID(uint(id))
+	fields, err := database.ParseFields(r.URL.Query().Get("fields"), contact.ContactFields)
 	if err != nil {
-		http.Error(w, "Contact not found", http.StatusNotFound)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	json.NewEncoder(w).Encode(contact) // Updated to use json encoder
+	// A vCard always carries the whole contact
+	isVCard, version := acceptsVCard(r)
+	if isVCard {
+		fields = nil
+	}
+	found, err := cc.Service.GetContactFields(r.Context(), id, fields)
+	if err != nil {
+		writeContactError(w, err)
+		return
+	}
+	if isVCard {
+		writeVCard(w, []contact.Contact{found}, version, fmt.Sprintf("contact-%d.vcf", found.ID))
+		return
+	}
+	body, err := selectFields(found, fields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	json.NewEncoder(w).Encode(body) // Updated to use json encoder
 }
 
 // Update the UpdateContact method to use the correct parameters
 func (cc *ContactController) UpdateContact(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)               // Get variables from the request
 	id, _ := strconv.Atoi(vars["id"]) // Convert to int
+	ctx, err := phoneContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 	var contact contact.Contact
 	if err := json.NewDecoder(r.Body).Decode(&contact); err != nil { // Updated to use json decoder
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	contact.ID = id
-	if err := cc.Service.UpdateContact(r.Context(), contact); err != nil { // Pass context and contact
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := cc.Service.UpdateContact(ctx, &contact); err != nil { // Pass context and contact
+		writeContactError(w, err)
 		return
 	}
 	json.NewEncoder(w).Encode(contact) // Updated to use json encoder
@@ -68,3 +103,283 @@ func (cc *ContactController) DeleteContact(w http.ResponseWriter, r *http.Reques
 	}
 	w.WriteHeader(http.StatusNoContent) // Updated to send no content response
 }
+
+// ListContacts handles GET /contacts?first_name=&last_name=&email=&phone=&contact_type=&category=&include_subcategories=&tags=&tag_match=&sort=&fields=&limit=&offset=&cursor=
+func (cc *ContactController) ListContacts(w http.ResponseWriter, r *http.Request) {
+	ctx, err := phoneContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params, err := parseSearchContactsParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if params.Cursor, err = queryCursor(r, cc.Cursors, params.Sort); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// A vCard always carries the whole contact
+	isVCard, version := acceptsVCard(r)
+	if isVCard {
+		params.Fields = nil
+	}
+	contacts, info, err := cc.Service.ListContacts(ctx, params)
+	if err != nil {
+		writeContactError(w, err)
+		return
+	}
+	if isVCard {
+		writeVCard(w, contacts, version, "contacts.vcf")
+		return
+	}
+	if params.Limit == 0 {
+		params.Limit = services.DefaultContactLimit
+	}
+	data, err := selectFields(contacts, params.Fields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeList(w, r, cc.Cursors, data, contactPage(contacts, params.Sort, params.Cursor, params.Offset, info), info, params.Limit)
+}
+
+// ListTrash handles GET /contacts/trash?limit=&offset=&cursor=
+func (cc *ContactController) ListTrash(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cursor, err := queryCursor(r, cc.Cursors, services.TrashSort)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	contacts, info, err := cc.Service.Trash(r.Context(), limit, offset, cursor)
+	if err != nil {
+		writeContactError(w, err)
+		return
+	}
+	if limit == 0 {
+		limit = services.DefaultContactLimit
+	}
+	writeList(w, r, cc.Cursors, contacts, contactPage(contacts, services.TrashSort, cursor, offset, info), info, limit)
+}
+
+// contactPage describes a page of contacts for its links
+func contactPage(contacts []contact.Contact, sort []database.SortField, cursor *database.Cursor, offset int64, info services.PageInfo) pagination.Page {
+	page := pagination.Page{Sort: sort, Cursor: cursor, Offset: offset, More: info.More}
+	if len(contacts) > 0 {
+		page.First, page.Last = contacts[0], contacts[len(contacts)-1]
+	}
+	return page
+}
+
+// RestoreContact handles POST /contacts/{id}/restore
+func (cc *ContactController) RestoreContact(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	restored, err := cc.Service.RestoreContact(r.Context(), id)
+	if err != nil {
+		writeContactError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(restored)
+}
+
+// FindDuplicates handles GET /contacts/duplicates?contact_id=&threshold=&limit=
+func (cc *ContactController) FindDuplicates(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	contactID, err := queryInt(query, "contact_id")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(query, "limit")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var threshold float64
+	if raw := query.Get("threshold"); raw != "" {
+		threshold, err = strconv.ParseFloat(raw, 64)
+		if err != nil || threshold < 0 || threshold > 1 {
+			http.Error(w, "threshold must be a number between 0 and 1", http.StatusBadRequest)
+			return
+		}
+	}
+
+	pairs, err := cc.Service.FindDuplicates(r.Context(), contactID, threshold, limit)
+	if err != nil {
+		writeContactError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(pairs)
+}
+
+// MergeContacts handles POST /contacts/merge
+func (cc *ContactController) MergeContacts(w http.ResponseWriter, r *http.Request) {
+	var req services.MergeContactsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	merged, err := cc.Service.MergeContacts(r.Context(), req)
+	if err != nil {
+		writeContactError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(merged)
+}
+
+// ImportContacts handles POST /contacts/import with a .vcf body or a
+// multipart "file" upload. ?type= and ?category= fill in IDs the cards do
+// not carry; ?region= reads phone numbers without a country code.
+func (cc *ContactController) ImportContacts(w http.ResponseWriter, r *http.Request) {
+	typeID, err := queryInt(r.URL.Query(), "type")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	categoryID, err := queryInt(r.URL.Query(), "category")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx, err := phoneContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body, closeBody, err := uploadedFile(r, "file")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer closeBody()
+
+	report, err := cc.Service.ImportVCards(ctx, body, typeID, categoryID)
+	if err != nil {
+		writeContactError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// ImportContactsCSV handles POST /contacts/import/csv with a CSV body or a
+// multipart "file" upload.
+//
+//	?dry_run=true      validate only
+//	?mode=partial      import the valid rows (default atomic: all or nothing)
+//	?mapping={...}     JSON object of field to CSV header, e.g.
+//	                   {"email":"E-mail Address"}; auto-detected when absent
+//	?type=&category=   IDs for rows that do not carry them
+//	?region=TH         region of phone numbers without a country code
+//
+// With Accept: text/csv or ?format=csv the rejected rows are returned as a
+// CSV report instead of JSON.
+func (cc *ContactController) ImportContactsCSV(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts := services.CSVImportOptions{
+		DryRun: query.Get("dry_run") == "true",
+		Atomic: true,
+	}
+	switch query.Get("mode") {
+	case "", "atomic":
+	case "partial":
+		opts.Atomic = false
+	default:
+		http.Error(w, "mode must be atomic or partial", http.StatusBadRequest)
+		return
+	}
+	var err error
+	if opts.DefaultTypeID, err = queryInt(query, "type"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if opts.DefaultCategoryID, err = queryInt(query, "category"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ctx, err := phoneContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body, closeBody, err := uploadedFile(r, "file")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer closeBody()
+
+	// The mapping may also come as a multipart form field
+	if raw := r.FormValue("mapping"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &opts.Mapping); err != nil {
+			http.Error(w, "invalid mapping: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	report, err := cc.Service.ImportCSV(ctx, body, opts)
+	if err != nil {
+		writeContactError(w, err)
+		return
+	}
+
+	status := http.StatusOK
+	if opts.Atomic && !opts.DryRun && len(report.Errors) > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	if query.Get("format") == "csv" || accepts(r, contact.CSVContentType) {
+		w.Header().Set("Content-Type", contact.CSVContentType+"; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="contact-import-errors.csv"`)
+		w.WriteHeader(status)
+		contact.WriteImportErrorsCSV(w, report.Header, report.Errors)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// writeVCard renders contacts as text/vcard
+func writeVCard(w http.ResponseWriter, contacts []contact.Contact, version, filename string) {
+	if version == "" {
+		version = contact.VCardVersion3
+	}
+	if !contact.IsValidVCardVersion(version) {
+		http.Error(w, fmt.Sprintf("unsupported vCard version %q", version), http.StatusNotAcceptable)
+		return
+	}
+	w.Header().Set("Content-Type", contact.VCardContentType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	contact.MarshalVCard(w, contacts, version)
+}
+
+// writeContactError maps service errors to HTTP status codes
+func writeContactError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrContactNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidContact), errors.Is(err, services.ErrInvalidMerge),
+		errors.Is(err, services.ErrInvalidSearch), errors.Is(err, services.ErrInvalidTag):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrEmailAlreadyExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"apidesign/internal/contact"
+	"apidesign/internal/services"
+)
+
+type ContactTypeController struct {
+	Service *services.ContactTypeService
+}
+
+// CreateContactType handles POST /contact-types
+func (tc *ContactTypeController) CreateContactType(w http.ResponseWriter, r *http.Request) {
+	var t contact.ContactType
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := tc.Service.CreateContactType(r.Context(), &t); err != nil {
+		writeContactTypeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(t)
+}
+
+// ListContactTypes handles GET /contact-types?limit=&offset=
+func (tc *ContactTypeController) ListContactTypes(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	types, err := tc.Service.ListContactTypes(r.Context(), limit, offset)
+	if err != nil {
+		writeContactTypeError(w, err)
+		return
+	}
+	if types == nil {
+		types = []contact.ContactType{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types)
+}
+
+// GetContactType handles GET /contact-types/{id}
+func (tc *ContactTypeController) GetContactType(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	t, err := tc.Service.GetContactType(r.Context(), id)
+	if err != nil {
+		writeContactTypeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// UpdateContactType handles PUT /contact-types/{id}
+func (tc *ContactTypeController) UpdateContactType(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var t contact.ContactType
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	t.ID = id
+	if err := tc.Service.UpdateContactType(r.Context(), &t); err != nil {
+		writeContactTypeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// DeleteContactType handles DELETE /contact-types/{id}
+func (tc *ContactTypeController) DeleteContactType(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := tc.Service.DeleteContactType(r.Context(), id); err != nil {
+		writeContactTypeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeContactTypeError maps service errors to HTTP status codes
+func writeContactTypeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrContactTypeNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidContactType):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrContactTypeExists), errors.Is(err, services.ErrContactTypeInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,81 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"apidesign/internal/database"
+	"apidesign/internal/pagination"
+	"apidesign/internal/services"
+)
+
+// ListEnvelope wraps a page of a collection with its pagination
+type ListEnvelope struct {
+	Data       interface{} `json:"data"`
+	Pagination Pagination  `json:"pagination"`
+}
+
+// Pagination describes where a page sits in its collection. The cursors
+// and links lead to the neighbouring pages and are left out when there is
+// none; the links are also sent in the Link header.
+type Pagination struct {
+	Total      int64  `json:"total"`
+	Limit      int64  `json:"limit"`
+	Offset     int64  `json:"offset"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Next       string `json:"next,omitempty"`
+	Prev       string `json:"prev,omitempty"`
+}
+
+// queryCursor reads the ?cursor= of a list in the given order
+func queryCursor(r *http.Request, cursors *pagination.Codec, sort []database.SortField) (*database.Cursor, error) {
+	return cursors.Decode(r.URL.Query().Get("cursor"), sort)
+}
+
+// writeList writes a page as a ListEnvelope with the cursors of the pages
+// around it. page.Sort, page.Cursor and page.Offset say how it was read.
+func writeList(w http.ResponseWriter, r *http.Request, cursors *pagination.Codec, data interface{}, page pagination.Page, info services.PageInfo, limit int64) {
+	next, prev, err := cursors.Links(page)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	// Read backwards, the page came from a cursor so a next page exists
+	backwards := page.Cursor != nil && page.Cursor.Before
+	p := Pagination{
+		Total:      info.Total,
+		Limit:      limit,
+		Offset:     page.Offset,
+		HasMore:    (info.More && !backwards) || (backwards && page.First != nil),
+		NextCursor: next,
+		PrevCursor: prev,
+	}
+	var links []string
+	if next != "" {
+		p.Next = cursorURL(r, next)
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, p.Next))
+	}
+	if prev != "" {
+		p.Prev = cursorURL(r, prev)
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, p.Prev))
+	}
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ListEnvelope{Data: data, Pagination: p})
+}
+
+// cursorURL is the request URL moved to the page of cursor
+func cursorURL(r *http.Request, cursor string) string {
+	u := *r.URL
+	query := u.Query()
+	query.Del("offset")
+	query.Set("cursor", cursor)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,560 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"apidesign/internal/database"
+	"apidesign/internal/event"
+	"apidesign/internal/pagination"
+	"apidesign/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+type EventController struct {
+	Service *services.EventService
+	// Cursors signs the cursors of event lists
+	Cursors *pagination.Codec
+}
+
+// CreateEvent handles POST /events?allowConflicts=
+func (ec *EventController) CreateEvent(w http.ResponseWriter, r *http.Request) {
+	loc, err := requestLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var newEvent event.Event
+	if err := json.NewDecoder(r.Body).Decode(&newEvent); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := ec.Service.CreateEvent(r.Context(), &newEvent, allowConflicts(r)); err != nil {
+		writeEventError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(newEvent.In(loc))
+}
+
+// GetEvent handles GET /events/{id}?fields=
+func (ec *EventController) GetEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	loc, err := requestLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fields, err := database.ParseFields(r.URL.Query().Get("fields"), event.EventColumns)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	e, err := ec.Service.GetEventFields(r.Context(), id, fields)
+	if err != nil {
+		writeEventError(w, err)
+		return
+	}
+	body, err := selectFields(e.In(loc), fields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(body)
+}
+
+// UpdateEvent handles PUT /events/{id}?allowConflicts=
+func (ec *EventController) UpdateEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	loc, err := requestLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var e event.Event
+	if err := json.NewDecoder(r.Body).Decode(&e); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	e.ID = id
+	if err := ec.Service.UpdateEvent(r.Context(), &e, allowConflicts(r)); err != nil {
+		writeEventError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e.In(loc))
+}
+
+// DeleteEvent handles DELETE /events/{id}
+func (ec *EventController) DeleteEvent(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := ec.Service.DeleteEvent(r.Context(), id); err != nil {
+		writeEventError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListEvents handles GET /events?type=&category=&include_subcategories=&status=&from=&to=&tz=&sort=&fields=&limit=&offset=&cursor=
+func (ec *EventController) ListEvents(w http.ResponseWriter, r *http.Request) {
+	loc, err := requestLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params, err := parseListEventsParams(r.URL.Query(), loc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if params.Cursor, err = queryCursor(r, ec.Cursors, params.Sort); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	events, info, err := ec.Service.ListEvents(r.Context(), params)
+	if err != nil {
+		writeEventError(w, err)
+		return
+	}
+	if events == nil {
+		events = []event.Event{}
+	}
+	if acceptsCalendar(r) {
+		ec.writeICS(w, r, events, "events.ics")
+		return
+	}
+
+	// Cursors are taken from the stored values, before moving to loc
+	page := pagination.Page{Sort: params.Sort, Cursor: params.Cursor, Offset: params.Offset, More: info.More}
+	if len(events) > 0 {
+		page.First, page.Last = events[0], events[len(events)-1]
+	}
+	for i := range events {
+		events[i] = events[i].In(loc)
+	}
+	if params.Limit == 0 {
+		params.Limit = services.DefaultEventLimit
+	}
+	data, err := selectFields(events, params.Fields)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeList(w, r, ec.Cursors, data, page, info, params.Limit)
+}
+
+// Calendar handles GET /events/calendar?view=day|week|month&date=&tz= and
+// accepts the same filters as ListEvents
+func (ec *EventController) Calendar(w http.ResponseWriter, r *http.Request) {
+	loc, err := requestLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	params, err := parseListEventsParams(query, loc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	date, err := queryTimeIn(query, "date", dateLocation(loc))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if date.IsZero() {
+		date = time.Now()
+	}
+	view := query.Get("view")
+	if view == "" {
+		view = event.CalendarMonth
+	}
+
+	cal, err := ec.Service.Calendar(r.Context(), params, view, date, dateLocation(loc))
+	if err != nil {
+		writeEventError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cal)
+}
+
+// FreeBusy handles POST /freebusy
+func (ec *EventController) FreeBusy(w http.ResponseWriter, r *http.Request) {
+	loc, err := requestLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var params event.FreeBusyParams
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := ec.Service.FreeBusy(r.Context(), params)
+	if err != nil {
+		writeEventError(w, err)
+		return
+	}
+	if loc != nil {
+		result.From, result.To = result.From.In(loc), result.To.In(loc)
+		busyIn(result.Busy, loc)
+		for _, intervals := range result.Contacts {
+			busyIn(intervals, loc)
+		}
+		for _, intervals := range result.Categories {
+			busyIn(intervals, loc)
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// busyIn expresses busy intervals in loc
+func busyIn(intervals []event.BusyInterval, loc *time.Location) {
+	for i := range intervals {
+		intervals[i].Start = intervals[i].Start.In(loc)
+		intervals[i].End = intervals[i].End.In(loc)
+	}
+}
+
+// GetEventICS handles GET /events/{id}.ics
+func (ec *EventController) GetEventICS(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	e, err := ec.Service.GetEvent(r.Context(), id)
+	if err != nil {
+		writeEventError(w, err)
+		return
+	}
+	ec.writeICS(w, r, []event.Event{e}, fmt.Sprintf("event-%d.ics", id))
+}
+
+// ImportEvents handles POST /events/import with an .ics body or a multipart
+// "file" upload. ?type= and ?category= fill in IDs the file does not carry.
+func (ec *EventController) ImportEvents(w http.ResponseWriter, r *http.Request) {
+	typeID, err := queryInt(r.URL.Query(), "type")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	categoryID, err := queryInt(r.URL.Query(), "category")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body, closeBody, err := uploadedFile(r, "file")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer closeBody()
+
+	report, err := ec.Service.ImportEvents(r.Context(), body, typeID, categoryID)
+	if err != nil && report.Imported == nil {
+		writeEventError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		// The import stopped early; report what was done before it did
+		report.Error = err.Error()
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// writeICS renders events, with their occurrence exceptions, as text/calendar
+func (ec *EventController) writeICS(w http.ResponseWriter, r *http.Request, events []event.Event, filename string) {
+	exceptions, err := ec.Service.EventExceptions(r.Context(), events)
+	if err != nil {
+		writeEventError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", event.ICSContentType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	event.MarshalICS(w, events, exceptions)
+}
+
+// PublishEvent handles POST /events/{id}/publish
+func (ec *EventController) PublishEvent(w http.ResponseWriter, r *http.Request) {
+	ec.transition(w, r, event.ActionPublish)
+}
+
+// CancelEvent handles POST /events/{id}/cancel
+func (ec *EventController) CancelEvent(w http.ResponseWriter, r *http.Request) {
+	ec.transition(w, r, event.ActionCancel)
+}
+
+// CompleteEvent handles POST /events/{id}/complete
+func (ec *EventController) CompleteEvent(w http.ResponseWriter, r *http.Request) {
+	ec.transition(w, r, event.ActionComplete)
+}
+
+// ListTransitions handles GET /events/{id}/transitions
+func (ec *EventController) ListTransitions(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	transitions, err := ec.Service.GetTransitions(r.Context(), id, limit, offset)
+	if err != nil {
+		writeEventError(w, err)
+		return
+	}
+	if transitions == nil {
+		transitions = []event.EventTransition{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transitions)
+}
+
+// transition applies a lifecycle action; the optional body may carry a reason
+func (ec *EventController) transition(w http.ResponseWriter, r *http.Request, action string) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	loc, err := requestLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var body struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	e, err := ec.Service.TransitionEvent(r.Context(), id, action, body.Reason)
+	if err != nil {
+		writeEventError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(e.In(loc))
+}
+
+// ListAllOccurrences handles GET /events/occurrences?from=&to= and expands
+// every matching event (accepts the same filters as ListEvents)
+func (ec *EventController) ListAllOccurrences(w http.ResponseWriter, r *http.Request) {
+	loc, err := requestLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	params, err := parseListEventsParams(r.URL.Query(), loc)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	occurrences, err := ec.Service.ExpandEvents(r.Context(), params)
+	if err != nil {
+		writeEventError(w, err)
+		return
+	}
+	writeOccurrences(w, occurrences, loc)
+}
+
+// ListOccurrences handles GET /events/{id}/occurrences?from=&to=&tz=
+func (ec *EventController) ListOccurrences(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	loc, err := requestLocation(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	from, err := queryTimeIn(r.URL.Query(), "from", dateLocation(loc))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := queryTimeIn(r.URL.Query(), "to", dateLocation(loc))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	occurrences, err := ec.Service.ListOccurrences(r.Context(), id, from, to)
+	if err != nil {
+		writeEventError(w, err)
+		return
+	}
+	writeOccurrences(w, occurrences, loc)
+}
+
+// writeOccurrences renders occurrences in loc, or each in its event's zone
+func writeOccurrences(w http.ResponseWriter, occurrences []event.Occurrence, loc *time.Location) {
+	for i := range occurrences {
+		occurrences[i] = occurrences[i].In(loc)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(occurrences)
+}
+
+// UpdateOccurrence handles PUT /events/{id}/occurrences/{recurrenceId}
+func (ec *EventController) UpdateOccurrence(w http.ResponseWriter, r *http.Request) {
+	id, recurrenceID, err := occurrencePath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var override event.EventException
+	if err := json.NewDecoder(r.Body).Decode(&override); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	exception, err := ec.Service.UpdateOccurrence(r.Context(), id, recurrenceID, override)
+	if err != nil {
+		writeEventError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(exception)
+}
+
+// CancelOccurrence handles DELETE /events/{id}/occurrences/{recurrenceId}
+func (ec *EventController) CancelOccurrence(w http.ResponseWriter, r *http.Request) {
+	id, recurrenceID, err := occurrencePath(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := ec.Service.CancelOccurrence(r.Context(), id, recurrenceID); err != nil {
+		writeEventError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// occurrencePath reads {id} and {recurrenceId} (RFC 5545 or RFC 3339 time)
+func occurrencePath(r *http.Request) (int, time.Time, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	recurrenceID, err := event.ParseICalTime(mux.Vars(r)["recurrenceId"], time.UTC)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("invalid recurrenceId: %w", err)
+	}
+	return id, recurrenceID, nil
+}
+
+// dateLocation is the zone YYYY-MM-DD query dates are read in
+func dateLocation(loc *time.Location) *time.Location {
+	if loc == nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// parseListEventsParams reads list filters from the query string; plain
+// dates are taken in loc
+func parseListEventsParams(query url.Values, loc *time.Location) (event.ListEventsParams, error) {
+	var params event.ListEventsParams
+	var err error
+
+	if params.EventTypeID, err = queryInt(query, "type"); err != nil {
+		return params, err
+	}
+	if params.CategoryID, err = queryInt(query, "category"); err != nil {
+		return params, err
+	}
+	params.IncludeSubcategories = query.Get("include_subcategories") == "true"
+	params.Status = query.Get("status")
+	if params.From, err = queryTimeIn(query, "from", dateLocation(loc)); err != nil {
+		return params, err
+	}
+	if params.To, err = queryTimeIn(query, "to", dateLocation(loc)); err != nil {
+		return params, err
+	}
+
+	limit, err := queryInt(query, "limit")
+	if err != nil {
+		return params, err
+	}
+	offset, err := queryInt(query, "offset")
+	if err != nil {
+		return params, err
+	}
+	params.Limit = int64(limit)
+	params.Offset = int64(offset)
+
+	if params.Sort, err = database.ParseSort(query.Get("sort"), event.EventColumns); err != nil {
+		return params, err
+	}
+	if params.Fields, err = database.ParseFields(query.Get("fields"), event.EventColumns); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// allowConflicts reports whether the client accepts overlapping events
+func allowConflicts(r *http.Request) bool {
+	return r.URL.Query().Get("allowConflicts") == "true"
+}
+
+// writeEventError maps service errors to HTTP status codes
+func writeEventError(w http.ResponseWriter, err error) {
+	var conflictErr *event.ConflictError
+	if errors.As(err, &conflictErr) {
+		// Conflicts are returned as JSON so clients can show what overlaps
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(struct {
+			Error     string           `json:"error"`
+			Conflicts []event.Conflict `json:"conflicts"`
+		}{conflictErr.Error(), conflictErr.Conflicts})
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrEventNotFound), errors.Is(err, services.ErrOccurrenceNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidEvent):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, event.ErrInvalidTransition):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
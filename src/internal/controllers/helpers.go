@@ -0,0 +1,269 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"apidesign/internal/contact"
+	"apidesign/internal/database"
+	"apidesign/internal/event"
+	"apidesign/internal/phone"
+	"apidesign/internal/services"
+
+	"github.com/gorilla/mux"
+)
+
+// timeZoneHeader lets clients pick the zone of rendered times instead of ?tz=
+const timeZoneHeader = "Time-Zone"
+
+// phoneRegionHeader lets clients pick the region of national phone numbers
+// instead of ?region=
+const phoneRegionHeader = "Phone-Region"
+
+// maxUploadSize limits multipart uploads kept in memory
+const maxUploadSize = 32 << 20
+
+// pathID reads the {id} route variable
+func pathID(r *http.Request) (int, error) {
+	return routeInt(r, "id")
+}
+
+// routeInt reads a positive integer route variable
+func routeInt(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(mux.Vars(r)[name])
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return id, nil
+}
+
+// queryInt reads an optional integer query parameter
+func queryInt(query url.Values, name string) (int, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %q", name, raw)
+	}
+	return value, nil
+}
+
+// queryList reads a comma separated query parameter, also accepting the
+// parameter repeated
+func queryList(query url.Values, name string) []string {
+	var values []string
+	for _, raw := range query[name] {
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
+
+// queryPage reads the optional limit and offset query parameters
+func queryPage(r *http.Request) (int64, int64, error) {
+	limit, err := queryInt(r.URL.Query(), "limit")
+	if err != nil {
+		return 0, 0, err
+	}
+	offset, err := queryInt(r.URL.Query(), "offset")
+	if err != nil {
+		return 0, 0, err
+	}
+	if limit < 0 || offset < 0 {
+		return 0, 0, fmt.Errorf("limit and offset must be non-negative")
+	}
+	return int64(limit), int64(offset), nil
+}
+
+// parseSearchContactsParams reads the filters, page and sort of a contact
+// list; the service validates them
+func parseSearchContactsParams(r *http.Request) (contact.SearchContactsParams, error) {
+	query := r.URL.Query()
+	params := contact.SearchContactsParams{
+		FirstName:            query.Get("first_name"),
+		LastName:             query.Get("last_name"),
+		Email:                query.Get("email"),
+		Phone:                query.Get("phone"),
+		IncludeSubcategories: query.Get("include_subcategories") == "true",
+		Tags:                 queryList(query, "tags"),
+		TagMatch:             query.Get("tag_match"),
+	}
+	var err error
+	if params.Limit, params.Offset, err = queryPage(r); err != nil {
+		return params, err
+	}
+	typeID, err := queryInt(query, "contact_type")
+	if err != nil {
+		return params, err
+	}
+	categoryID, err := queryInt(query, "category")
+	if err != nil {
+		return params, err
+	}
+	params.ContactType, params.Category = int64(typeID), int64(categoryID)
+	if params.Sort, err = database.ParseSort(query.Get("sort"), contact.ContactSortFields); err != nil {
+		return params, err
+	}
+	if params.Fields, err = database.ParseFields(query.Get("fields"), contact.ContactFields); err != nil {
+		return params, err
+	}
+	return params, nil
+}
+
+// selectFields renders v, a value or a slice of values, as JSON objects
+// holding only the given fields. The fields were read from the database
+// alone; this drops the zero values of the rest. No fields keep v as is.
+func selectFields(v interface{}, fields []string) (interface{}, error) {
+	if len(fields) == 0 {
+		return v, nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, err
+	}
+
+	pick := func(object interface{}) interface{} {
+		m, ok := object.(map[string]interface{})
+		if !ok {
+			return object
+		}
+		selected := make(map[string]interface{}, len(fields))
+		for _, f := range fields {
+			if value, ok := m[f]; ok {
+				selected[f] = value
+			}
+		}
+		return selected
+	}
+	if list, ok := decoded.([]interface{}); ok {
+		for i := range list {
+			list[i] = pick(list[i])
+		}
+		return list, nil
+	}
+	return pick(decoded), nil
+}
+
+// queryTime reads an optional RFC 3339 or YYYY-MM-DD query parameter
+func queryTime(query url.Values, name string) (time.Time, error) {
+	return queryTimeIn(query, name, time.UTC)
+}
+
+// queryTimeIn is queryTime with YYYY-MM-DD dates taken as midnight in loc
+func queryTimeIn(query url.Values, name string, loc *time.Location) (time.Time, error) {
+	raw := query.Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", raw, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s: %q", name, raw)
+	}
+	return t, nil
+}
+
+// requestLocation reads the zone to render times in from ?tz= or the
+// Time-Zone header; nil means each event's own zone
+func requestLocation(r *http.Request) (*time.Location, error) {
+	name := r.URL.Query().Get("tz")
+	if name == "" {
+		name = r.Header.Get(timeZoneHeader)
+	}
+	if name == "" {
+		return nil, nil
+	}
+	return event.LoadTimeZone(name)
+}
+
+// phoneContext returns the request context carrying the region national
+// phone numbers are read in, from ?region= or the Phone-Region header.
+// Without one the configured default region applies.
+func phoneContext(r *http.Request) (context.Context, error) {
+	region := r.URL.Query().Get("region")
+	if region == "" {
+		region = r.Header.Get(phoneRegionHeader)
+	}
+	if region == "" {
+		return r.Context(), nil
+	}
+	if !phone.IsSupportedRegion(region) {
+		return nil, fmt.Errorf("%w %q", phone.ErrUnknownRegion, region)
+	}
+	return services.WithPhoneRegion(r.Context(), strings.ToUpper(region)), nil
+}
+
+// accepts reports whether the Accept header lists the media type
+func accepts(r *http.Request, mediaType string) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		accepted, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err == nil && strings.EqualFold(accepted, mediaType) {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsCalendar reports whether the client asked for text/calendar
+func acceptsCalendar(r *http.Request) bool {
+	return accepts(r, "text/calendar")
+}
+
+// acceptsVCard reports whether the client asked for text/vcard and which
+// version, from the media type's version parameter or ?version=
+func acceptsVCard(r *http.Request) (bool, string) {
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		accepted, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(accepted, contact.VCardContentType) || strings.EqualFold(accepted, "text/x-vcard") {
+			version := params["version"]
+			if version == "" {
+				version = r.URL.Query().Get("version")
+			}
+			return true, version
+		}
+	}
+	return false, ""
+}
+
+// uploadedFile returns the multipart field or, for other content types, the
+// raw request body
+func uploadedFile(r *http.Request, field string) (io.Reader, func(), error) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return r.Body, func() {}, nil
+	}
+
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		return nil, nil, err
+	}
+	file, _, err := r.FormFile(field)
+	if err != nil {
+		return nil, nil, fmt.Errorf("missing %q upload: %w", field, err)
+	}
+	return file, func() { file.Close() }, nil
+}
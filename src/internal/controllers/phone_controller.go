@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"apidesign/internal/phone"
+)
+
+type PhoneController struct {
+	// DefaultRegion reads numbers without a country code when the request
+	// names no region
+	DefaultRegion string
+}
+
+// PhoneNumberResponse is a parsed number with its formatted forms
+type PhoneNumberResponse struct {
+	phone.Number
+	International string `json:"international"`
+	National      string `json:"national"`
+}
+
+// ParseNumber handles GET /phone-numbers/parse?number=&region=
+func (pc *PhoneController) ParseNumber(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	region := query.Get("region")
+	if region == "" {
+		region = r.Header.Get(phoneRegionHeader)
+	}
+	if region == "" {
+		region = pc.DefaultRegion
+	}
+
+	n, err := phone.Parse(query.Get("number"), region)
+	if err != nil {
+		status := http.StatusUnprocessableEntity
+		if errors.Is(err, phone.ErrUnknownRegion) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PhoneNumberResponse{
+		Number:        n,
+		International: n.International(),
+		National:      n.National(),
+	})
+}
+
+// ListRegions handles GET /phone-numbers/regions
+func (pc *PhoneController) ListRegions(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(phone.Regions())
+}
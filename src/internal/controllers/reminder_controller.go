@@ -0,0 +1,111 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"apidesign/internal/reminder"
+	"apidesign/internal/services"
+)
+
+type ReminderController struct {
+	Service *services.ReminderService
+}
+
+// ListReminders handles GET /events/{id}/reminders
+func (rc *ReminderController) ListReminders(w http.ResponseWriter, r *http.Request) {
+	eventID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rules, err := rc.Service.ListReminders(r.Context(), eventID)
+	if err != nil {
+		writeReminderError(w, err)
+		return
+	}
+	if rules == nil {
+		rules = []reminder.Rule{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+// AddReminder handles POST /events/{id}/reminders with {"minutes_before": 60}
+func (rc *ReminderController) AddReminder(w http.ResponseWriter, r *http.Request) {
+	eventID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var rule reminder.Rule
+	if err := json.NewDecoder(r.Body).Decode(&rule); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rule.ID = 0
+	rule.EventID = eventID
+	if err := rc.Service.AddReminder(r.Context(), &rule); err != nil {
+		writeReminderError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+// RemoveReminder handles DELETE /events/{id}/reminders/{reminderId}
+func (rc *ReminderController) RemoveReminder(w http.ResponseWriter, r *http.Request) {
+	eventID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id, err := routeInt(r, "reminderId")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := rc.Service.RemoveReminder(r.Context(), eventID, id); err != nil {
+		writeReminderError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListJobs handles GET /events/{id}/reminders/jobs?status=
+func (rc *ReminderController) ListJobs(w http.ResponseWriter, r *http.Request) {
+	eventID, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	jobs, err := rc.Service.ListJobs(r.Context(), eventID, r.URL.Query().Get("status"), limit, offset)
+	if err != nil {
+		writeReminderError(w, err)
+		return
+	}
+	if jobs == nil {
+		jobs = []reminder.Job{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(jobs)
+}
+
+// writeReminderError maps service errors to HTTP status codes
+func writeReminderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrEventNotFound), errors.Is(err, services.ErrReminderNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidReminder):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
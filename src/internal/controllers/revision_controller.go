@@ -0,0 +1,116 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"apidesign/internal/services"
+)
+
+type RevisionController struct {
+	Service *services.RevisionService
+}
+
+// ListRevisions handles GET /contacts/{id}/revisions
+func (rc *RevisionController) ListRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	revisions, err := rc.Service.Revisions(r.Context(), id)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revisions)
+}
+
+// GetRevision handles GET /contacts/{id}/revisions/{rev}
+func (rc *RevisionController) GetRevision(w http.ResponseWriter, r *http.Request) {
+	id, rev, ok := revisionIDs(w, r)
+	if !ok {
+		return
+	}
+	revision, err := rc.Service.GetRevision(r.Context(), id, rev)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(revision)
+}
+
+// DiffRevisions handles GET /contacts/{id}/revisions/diff?from=&to=
+func (rc *RevisionController) DiffRevisions(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	from, err := queryInt(query, "from")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	to, err := queryInt(query, "to")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if from <= 0 || to <= 0 {
+		http.Error(w, "from and to revisions are required", http.StatusBadRequest)
+		return
+	}
+
+	diff, err := rc.Service.Diff(r.Context(), id, from, to)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(diff)
+}
+
+// RestoreRevision handles POST /contacts/{id}/revisions/{rev}/restore
+func (rc *RevisionController) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	id, rev, ok := revisionIDs(w, r)
+	if !ok {
+		return
+	}
+	restored, err := rc.Service.Restore(r.Context(), id, rev)
+	if err != nil {
+		writeRevisionError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(restored)
+}
+
+// revisionIDs reads the contact ID and revision number, answering 400 when invalid
+func revisionIDs(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, 0, false
+	}
+	rev, err := routeInt(r, "rev")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return id, rev, true
+}
+
+// writeRevisionError maps service errors to HTTP status codes
+func writeRevisionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrRevisionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		writeContactError(w, err)
+	}
+}
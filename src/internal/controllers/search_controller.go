@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"apidesign/internal/services"
+)
+
+type SearchController struct {
+	Service *services.SearchService
+}
+
+// SearchContacts handles GET /contacts/search?q=&limit=&offset=
+func (sc *SearchController) SearchContacts(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ctx, err := phoneContext(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	result, err := sc.Service.Search(ctx, r.URL.Query().Get("q"), int(limit), int(offset))
+	if err != nil {
+		writeSearchError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// writeSearchError maps service errors to HTTP status codes
+func writeSearchError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidSearch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
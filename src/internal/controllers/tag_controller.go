@@ -0,0 +1,158 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"apidesign/internal/contact"
+	"apidesign/internal/services"
+)
+
+type TagController struct {
+	Service *services.TagService
+}
+
+// SetContactTagsRequest is the body of PUT /contacts/{id}/tags
+type SetContactTagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// CreateTag handles POST /tags, which creates a curated tag
+func (tc *TagController) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var t contact.Tag
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := tc.Service.CreateTag(r.Context(), &t); err != nil {
+		writeTagError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(t)
+}
+
+// ListTags handles GET /tags?curated=, with the usage count of every tag
+func (tc *TagController) ListTags(w http.ResponseWriter, r *http.Request) {
+	var curated *bool
+	if raw := r.URL.Query().Get("curated"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			http.Error(w, "invalid curated: "+raw, http.StatusBadRequest)
+			return
+		}
+		curated = &value
+	}
+	tags, err := tc.Service.ListTags(r.Context(), curated)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// GetTag handles GET /tags/{id}
+func (tc *TagController) GetTag(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	t, err := tc.Service.GetTag(r.Context(), id)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// UpdateTag handles PUT /tags/{id}
+func (tc *TagController) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var t contact.Tag
+	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	t.ID = id
+	if err := tc.Service.UpdateTag(r.Context(), &t); err != nil {
+		writeTagError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(t)
+}
+
+// DeleteTag handles DELETE /tags/{id}
+func (tc *TagController) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := tc.Service.DeleteTag(r.Context(), id); err != nil {
+		writeTagError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetContactTags handles GET /contacts/{id}/tags
+func (tc *TagController) GetContactTags(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tags, err := tc.Service.ContactTags(r.Context(), id)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// SetContactTags handles PUT /contacts/{id}/tags with {"tags": [...]}
+func (tc *TagController) SetContactTags(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var req SetContactTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tags, err := tc.Service.SetContactTags(r.Context(), id, req.Tags)
+	if err != nil {
+		writeTagError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+// writeTagError maps service errors to HTTP status codes
+func writeTagError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrTagNotFound), errors.Is(err, services.ErrContactNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidTag):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrTagExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"apidesign/internal/contact"
+	"apidesign/internal/services"
+)
+
+type TimelineController struct {
+	Service *services.TimelineService
+	// Subject returns the authenticated user of a request context
+	Subject func(ctx context.Context) string
+}
+
+// GetTimeline handles GET /contacts/{id}/timeline?kind=&from=&to=&order=&limit=&offset=
+func (tc *TimelineController) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := queryPage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	query := r.URL.Query()
+	params := contact.TimelineParams{
+		Kind:   query.Get("kind"),
+		Order:  query.Get("order"),
+		Limit:  limit,
+		Offset: offset,
+	}
+	if params.From, err = queryTime(query, "from"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if params.To, err = queryTime(query, "to"); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	entries, err := tc.Service.Timeline(r.Context(), id, params)
+	if err != nil {
+		writeTimelineError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
+
+// AddEntry handles POST /contacts/{id}/timeline
+func (tc *TimelineController) AddEntry(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var entry contact.TimelineEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := tc.Service.AddEntry(r.Context(), id, tc.Subject(r.Context()), &entry); err != nil {
+		writeTimelineError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(entry)
+}
+
+// GetEntry handles GET /contacts/{id}/timeline/{entryId}
+func (tc *TimelineController) GetEntry(w http.ResponseWriter, r *http.Request) {
+	id, entryID, ok := timelineIDs(w, r)
+	if !ok {
+		return
+	}
+	entry, err := tc.Service.GetEntry(r.Context(), id, entryID)
+	if err != nil {
+		writeTimelineError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// UpdateEntry handles PUT /contacts/{id}/timeline/{entryId}
+func (tc *TimelineController) UpdateEntry(w http.ResponseWriter, r *http.Request) {
+	id, entryID, ok := timelineIDs(w, r)
+	if !ok {
+		return
+	}
+	var entry contact.TimelineEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	entry.ID = entryID
+	if err := tc.Service.UpdateEntry(r.Context(), id, tc.Subject(r.Context()), &entry); err != nil {
+		writeTimelineError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entry)
+}
+
+// DeleteEntry handles DELETE /contacts/{id}/timeline/{entryId}
+func (tc *TimelineController) DeleteEntry(w http.ResponseWriter, r *http.Request) {
+	id, entryID, ok := timelineIDs(w, r)
+	if !ok {
+		return
+	}
+	if err := tc.Service.DeleteEntry(r.Context(), id, tc.Subject(r.Context()), entryID); err != nil {
+		writeTimelineError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// timelineIDs reads the contact and entry IDs, answering 400 when invalid
+func timelineIDs(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	id, err := pathID(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, 0, false
+	}
+	entryID, err := routeInt(r, "entryId")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return 0, 0, false
+	}
+	return id, entryID, true
+}
+
+// writeTimelineError maps service errors to HTTP status codes
+func writeTimelineError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrTimelineEntryNotFound), errors.Is(err, services.ErrContactNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidTimelineEntry):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrNoAuthor):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, services.ErrTimelineForbidden):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -2,7 +2,11 @@ package database
 
 import (
 	"context"
+	"errors"
 	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"gorm.io/gorm"
 )
 
 // Common model struct that can be embedded in other structs
@@ -18,8 +22,27 @@ type Database interface {
 	Close(ctx context.Context) error
 	Create(ctx context.Context, collection string, document interface{}) error
 	FindOne(ctx context.Context, collection string, filter interface{}, result interface{}) error
+	// FindOneWith is FindOne reading only the given columns, all when nil
+	FindOneWith(ctx context.Context, collection string, filter interface{}, result interface{}, fields []string) error
 	Find(ctx context.Context, collection string, filter interface{}, results interface{}, limit int64, offset int64) error 
+	// FindWith is Find with sorting and keyset paging; results are always
+	// ordered by id last, and rows read before a cursor come back in order
+	FindWith(ctx context.Context, collection string, filter interface{}, results interface{}, opts FindOptions) error
+	// Count returns the number of matching documents
+	Count(ctx context.Context, collection string, filter interface{}) (int64, error)
 	Update(ctx context.Context, collection string, filter interface{}, update interface{}) error
 	Delete(ctx context.Context, collection string, filter interface{}) error
+
+	// Transaction runs fn atomically. Every operation inside fn must go
+	// through tx (and the ctx passed to fn) to take part in the transaction.
+	Transaction(ctx context.Context, fn func(ctx context.Context, tx Database) error) error
+	// Lock takes an exclusive lock on the matching documents until the
+	// surrounding transaction ends; outside a transaction it has no lasting effect.
+	Lock(ctx context.Context, collection string, filter interface{}) error
 }
 
+
+// IsNotFound reports whether err is the "no rows/documents" error of either backend
+func IsNotFound(err error) bool {
+	return errors.Is(err, gorm.ErrRecordNotFound) || errors.Is(err, mongo.ErrNoDocuments)
+}
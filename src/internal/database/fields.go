@@ -0,0 +1,95 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Columns returns the columns of a model: the db tags of its fields and of
+// its embedded structs. Fields tagged db:"-" and fields holding other
+// records (structs, pointers to structs, slices) are left out.
+func Columns(model interface{}) []string {
+	return columnsOf(reflect.TypeOf(model))
+}
+
+func columnsOf(t reflect.Type) []string {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	var columns []string
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			columns = append(columns, columnsOf(sf.Type)...)
+			continue
+		}
+		name := strings.Split(sf.Tag.Get("db"), ",")[0]
+		if name == "" || name == "-" || !isScalar(sf.Type) {
+			continue
+		}
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+// isScalar reports whether a field type holds a single column value:
+// basic kinds, times and the sql.Null types
+func isScalar(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.Interface, reflect.Func, reflect.Chan:
+		return false
+	case reflect.Struct:
+		return t == reflect.TypeOf(time.Time{}) || strings.HasPrefix(t.Name(), "Null")
+	}
+	return true
+}
+
+// ParseFields reads a comma separated field list such as "id,first_name".
+// Only the allowed fields are accepted; an empty list means every field.
+func ParseFields(raw string, allowed []string) ([]string, error) {
+	var fields []string
+	seen := make(map[string]bool)
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" || seen[field] {
+			continue
+		}
+		if !containsField(allowed, field) {
+			return nil, fmt.Errorf("unknown field %q", field)
+		}
+		seen[field] = true
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// Projection returns the columns to read for the requested fields: those
+// that are columns, plus id, the sort fields and the required columns the
+// caller needs itself. No fields means every column, which is nil.
+func Projection(fields, columns []string, sort []SortField, required ...string) []string {
+	if len(fields) == 0 {
+		return nil
+	}
+	var projection []string
+	add := func(column string) {
+		if containsField(columns, column) && !containsField(projection, column) {
+			projection = append(projection, column)
+		}
+	}
+	add("id")
+	for _, field := range fields {
+		add(field)
+	}
+	for _, f := range sort {
+		add(f.Field)
+	}
+	for _, column := range required {
+		add(column)
+	}
+	return projection
+}
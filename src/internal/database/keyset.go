@@ -0,0 +1,179 @@
+package database
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ErrNullSortValue means a row cannot be paged past because one of its
+// sort values is null; the backends order nulls differently
+var ErrNullSortValue = errors.New("null sort value")
+
+// Cursor continues a sorted query after the row whose sort values are
+// Values, or before it when Before is set. Values follow StableSort.
+type Cursor struct {
+	Values []interface{}
+	Before bool
+}
+
+// StableSort appends id to the sort so that rows with equal sort values
+// keep the same order between pages. FindWith always sorts this way.
+func StableSort(sort []SortField) []SortField {
+	for _, f := range sort {
+		if f.Field == "id" {
+			return sort
+		}
+	}
+	return append(sort[:len(sort):len(sort)], SortField{Field: "id"})
+}
+
+// SortKey returns the values of row, a struct or pointer to one, for the
+// fields of the stable sort; a cursor made of them continues after the row.
+// Fields are found by their db tag, also in embedded structs.
+func SortKey(row interface{}, sort []SortField) ([]interface{}, error) {
+	v := reflect.Indirect(reflect.ValueOf(row))
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot read sort values from %T", row)
+	}
+	sort = StableSort(sort)
+	values := make([]interface{}, len(sort))
+	for i, f := range sort {
+		field, ok := fieldByTag(v, f.Field)
+		if !ok {
+			return nil, fmt.Errorf("%T has no field %q", row, f.Field)
+		}
+		value, err := plainValue(field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Field, err)
+		}
+		if value == nil {
+			return nil, fmt.Errorf("%w: %s", ErrNullSortValue, f.Field)
+		}
+		values[i] = value
+	}
+	return values, nil
+}
+
+// keysetFilter matches the rows past the cursor in sort order: for a sort
+// on a, b it is a > x OR (a = x AND b > y), with < for descending fields
+// and the comparisons flipped when reading backwards
+func keysetFilter(sort []SortField, cursor *Cursor) (bson.M, error) {
+	if len(cursor.Values) != len(sort) {
+		return nil, fmt.Errorf("cursor has %d values for %d sort fields", len(cursor.Values), len(sort))
+	}
+	or := make([]bson.M, 0, len(sort))
+	for i, f := range sort {
+		cond := bson.M{}
+		for j := 0; j < i; j++ {
+			cond[sort[j].Field] = cursor.Values[j]
+		}
+		op := "$gt"
+		if f.Desc != cursor.Before {
+			op = "$lt"
+		}
+		cond[f.Field] = bson.M{op: cursor.Values[i]}
+		or = append(or, cond)
+	}
+	return bson.M{"$or": or}, nil
+}
+
+// withCursor narrows filter to the rows past the cursor and returns the
+// order to read them in: reversed when reading backwards
+func withCursor(filter interface{}, sort []SortField, cursor *Cursor) (interface{}, []SortField, error) {
+	if cursor == nil {
+		return filter, sort, nil
+	}
+	keyset, err := keysetFilter(sort, cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	if cursor.Before {
+		reversed := make([]SortField, len(sort))
+		for i, f := range sort {
+			reversed[i] = SortField{Field: f.Field, Desc: !f.Desc}
+		}
+		sort = reversed
+	}
+	if filter == nil {
+		return keyset, sort, nil
+	}
+	m, ok := toFilterMap(filter)
+	if !ok {
+		return nil, nil, fmt.Errorf("cursors need a map filter, not %T", filter)
+	}
+	if len(m) == 0 {
+		return keyset, sort, nil
+	}
+	return bson.M{"$and": []bson.M{m, keyset}}, sort, nil
+}
+
+// reverseResults puts rows read backwards back into sort order
+func reverseResults(results interface{}) {
+	v := reflect.Indirect(reflect.ValueOf(results))
+	if v.Kind() != reflect.Slice {
+		return
+	}
+	swap := reflect.Swapper(v.Interface())
+	for i, j := 0, v.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
+}
+
+func fieldByTag(v reflect.Value, tag string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if name := strings.Split(sf.Tag.Get("db"), ",")[0]; name == tag {
+			return v.Field(i), true
+		}
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			if field, ok := fieldByTag(v.Field(i), tag); ok {
+				return field, true
+			}
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// plainValue unwraps nullable and pointer fields; integers become int64
+func plainValue(field reflect.Value) (interface{}, error) {
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			return nil, nil
+		}
+		field = field.Elem()
+	}
+	value := field.Interface()
+	if valuer, ok := value.(driver.Valuer); ok {
+		return valuer.Value()
+	}
+	switch field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(field.Uint()), nil
+	}
+	return value, nil
+}
+
+// TrimPage cuts results, read with a limit one above the page size, back to
+// limit rows and reports whether there were more. Reading backwards the
+// extra row is the first one.
+func TrimPage(results interface{}, limit int64, cursor *Cursor) bool {
+	v := reflect.ValueOf(results).Elem()
+	n := int64(v.Len())
+	if limit <= 0 || n <= limit {
+		return false
+	}
+	if cursor != nil && cursor.Before {
+		v.Set(v.Slice(int(n-limit), int(n)))
+	} else {
+		v.Set(v.Slice(0, int(limit)))
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestKeysetFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		sort   []SortField
+		cursor Cursor
+		want   bson.M
+	}{
+		{
+			name:   "single ascending",
+			sort:   []SortField{{Field: "id"}},
+			cursor: Cursor{Values: []interface{}{int64(7)}},
+			want: bson.M{"$or": []bson.M{
+				{"id": bson.M{"$gt": int64(7)}},
+			}},
+		},
+		{
+			name:   "ascending with tie breaker",
+			sort:   []SortField{{Field: "last_name"}, {Field: "id"}},
+			cursor: Cursor{Values: []interface{}{"Doe", int64(7)}},
+			want: bson.M{"$or": []bson.M{
+				{"last_name": bson.M{"$gt": "Doe"}},
+				{"last_name": "Doe", "id": bson.M{"$gt": int64(7)}},
+			}},
+		},
+		{
+			name:   "descending first field",
+			sort:   []SortField{{Field: "created_at", Desc: true}, {Field: "last_name"}, {Field: "id"}},
+			cursor: Cursor{Values: []interface{}{"2024-05-01", "Doe", int64(7)}},
+			want: bson.M{"$or": []bson.M{
+				{"created_at": bson.M{"$lt": "2024-05-01"}},
+				{"created_at": "2024-05-01", "last_name": bson.M{"$gt": "Doe"}},
+				{"created_at": "2024-05-01", "last_name": "Doe", "id": bson.M{"$gt": int64(7)}},
+			}},
+		},
+		{
+			name:   "backwards flips comparisons",
+			sort:   []SortField{{Field: "created_at", Desc: true}, {Field: "id"}},
+			cursor: Cursor{Values: []interface{}{"2024-05-01", int64(7)}, Before: true},
+			want: bson.M{"$or": []bson.M{
+				{"created_at": bson.M{"$gt": "2024-05-01"}},
+				{"created_at": "2024-05-01", "id": bson.M{"$lt": int64(7)}},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := keysetFilter(tt.sort, &tt.cursor)
+			if err != nil {
+				t.Fatalf("keysetFilter() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keysetFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestKeysetFilterValueCount(t *testing.T) {
+	sort := []SortField{{Field: "last_name"}, {Field: "id"}}
+	if _, err := keysetFilter(sort, &Cursor{Values: []interface{}{"Doe"}}); err == nil {
+		t.Error("keysetFilter() accepted a cursor with too few values")
+	}
+}
+
+func TestWithCursorBackwards(t *testing.T) {
+	sort := []SortField{{Field: "last_name"}, {Field: "id"}}
+	filter, order, err := withCursor(bson.M{"category_id": 3}, sort, &Cursor{Values: []interface{}{"Doe", int64(7)}, Before: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantOrder := []SortField{{Field: "last_name", Desc: true}, {Field: "id", Desc: true}}
+	if !reflect.DeepEqual(order, wantOrder) {
+		t.Errorf("order = %v, want %v", order, wantOrder)
+	}
+	and, ok := filter.(bson.M)["$and"].([]bson.M)
+	if !ok || len(and) != 2 || !reflect.DeepEqual(and[0], bson.M{"category_id": 3}) {
+		t.Errorf("filter = %v, want the list filter and the keyset", filter)
+	}
+}
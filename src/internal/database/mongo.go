@@ -2,6 +2,7 @@ package database
 
 import (
     "context" // Add this import
+    "go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/mongo"
     "go.mongodb.org/mongo-driver/mongo/options"
 )
@@ -40,6 +41,14 @@ func (m *MongoDatabase) FindOne(ctx context.Context, collection string, filter i
 	return m.db.Collection(collection).FindOne(ctx, filter).Decode(result)
 }
 
+func (m *MongoDatabase) FindOneWith(ctx context.Context, collection string, filter interface{}, result interface{}, fields []string) error {
+	find := options.FindOne()
+	if len(fields) > 0 {
+		find.SetProjection(projection(fields))
+	}
+	return m.db.Collection(collection).FindOne(ctx, filter, find).Decode(result)
+}
+
 func (m *MongoDatabase) Find(ctx context.Context, collection string, filter interface{}, result interface{}, limit int64, offset int64) error {
     cursor, err := m.db.Collection(collection).Find(ctx, filter, options.Find().SetLimit(limit).SetSkip(offset))
     if err != nil {
@@ -50,12 +59,94 @@ func (m *MongoDatabase) Find(ctx context.Context, collection string, filter inte
     return cursor.All(ctx, result) // Decode all results into the provided result slice
 }
 
+func (m *MongoDatabase) FindWith(ctx context.Context, collection string, filter interface{}, result interface{}, opts FindOptions) error {
+	filter, fields, err := withCursor(filter, StableSort(opts.Sort), opts.Cursor)
+	if err != nil {
+		return err
+	}
+	sort := bson.D{}
+	for _, f := range fields {
+		order := 1
+		if f.Desc {
+			order = -1
+		}
+		sort = append(sort, bson.E{Key: f.Field, Value: order})
+	}
+	find := options.Find().SetSort(sort).SetLimit(opts.Limit)
+	if len(opts.Fields) > 0 {
+		find.SetProjection(projection(opts.Fields))
+	}
+	if opts.Cursor == nil {
+		find.SetSkip(opts.Offset)
+	}
+	cursor, err := m.db.Collection(collection).Find(ctx, filter, find)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	if err := cursor.All(ctx, result); err != nil {
+		return err
+	}
+	if opts.Cursor != nil && opts.Cursor.Before {
+		reverseResults(result)
+	}
+	return nil
+}
+
+func (m *MongoDatabase) Count(ctx context.Context, collection string, filter interface{}) (int64, error) {
+	return m.db.Collection(collection).CountDocuments(ctx, filter)
+}
+
 func (m *MongoDatabase) Update(ctx context.Context, collection string, filter interface{}, update interface{}) error {
+	// Plain documents replace the given fields, as Updates does on Postgres
+	if doc, ok := toFilterMap(update); !ok || !hasOperators(doc) {
+		update = bson.M{"$set": update}
+	}
 	_, err := m.db.Collection(collection).UpdateOne(ctx, filter, update)
 	return err
 }
 
+// Delete removes every matching document, as it does on Postgres
 func (m *MongoDatabase) Delete(ctx context.Context, collection string, filter interface{}) error {
-	_, err := m.db.Collection(collection).DeleteOne(ctx, filter)
+	_, err := m.db.Collection(collection).DeleteMany(ctx, filter)
+	return err
+}
+
+// Transaction requires a replica set or sharded cluster
+func (m *MongoDatabase) Transaction(ctx context.Context, fn func(ctx context.Context, tx Database) error) error {
+	session, err := m.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	// WithTransaction retries fn on transient errors such as write conflicts
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc, m)
+	})
 	return err
-}
\ No newline at end of file
+}
+
+// Lock writes to the matching documents inside the transaction: a field is
+// set and unset again, so the documents are left as they were. The first
+// write claims the documents until the transaction ends; a concurrent
+// transaction writing to them fails with a WriteConflict, which
+// Transaction retries, so the two run one after another. Outside a
+// transaction Lock has no effect.
+func (m *MongoDatabase) Lock(ctx context.Context, collection string, filter interface{}) error {
+	coll := m.db.Collection(collection)
+	if _, err := coll.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"_lock": true}}); err != nil {
+		return err
+	}
+	_, err := coll.UpdateMany(ctx, filter, bson.M{"$unset": bson.M{"_lock": ""}})
+	return err
+}
+
+// projection includes the given fields only
+func projection(fields []string) bson.M {
+	p := bson.M{}
+	for _, field := range fields {
+		p[field] = 1
+	}
+	return p
+}
@@ -2,10 +2,11 @@ package database
 
 import (
 	"context"
-	// "fmt"
+	"fmt"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm" // Add this import
+	"gorm.io/gorm/clause"
 )
 
 // PostgreSQL implementation
@@ -40,15 +41,31 @@ func (p *PostgresDatabase) Create(ctx context.Context, collection string, docume
 }
 
 func (p *PostgresDatabase) FindOne(ctx context.Context, collection string, filter interface{}, result interface{}) error {
-	return p.db.WithContext(ctx).Table(collection).Where(filter).First(result).Error
+	query, err := applyFilter(p.db.WithContext(ctx).Table(collection), filter)
+	if err != nil {
+		return err
+	}
+	return query.First(result).Error
+}
+
+func (p *PostgresDatabase) FindOneWith(ctx context.Context, collection string, filter interface{}, result interface{}, fields []string) error {
+	query, err := applyFilter(p.db.WithContext(ctx).Table(collection), filter)
+	if err != nil {
+		return err
+	}
+	if query, err = selectColumns(query, fields); err != nil {
+		return err
+	}
+	return query.First(result).Error
 }
 
 func (p *PostgresDatabase) Find(ctx context.Context, collection string, filter interface{}, results interface{}, limit int64, offset int64) error {
 	query := p.db.WithContext(ctx).Table(collection)
 
 	// Apply filter conditions
-	if filter != nil {
-		query = query.Where(filter)
+	query, err := applyFilter(query, filter)
+	if err != nil {
+		return err
 	}
 
 	// Apply limit and offset
@@ -63,10 +80,90 @@ func (p *PostgresDatabase) Find(ctx context.Context, collection string, filter i
 	return query.Find(results).Error
 }
 
+func (p *PostgresDatabase) FindWith(ctx context.Context, collection string, filter interface{}, results interface{}, opts FindOptions) error {
+	filter, sort, err := withCursor(filter, StableSort(opts.Sort), opts.Cursor)
+	if err != nil {
+		return err
+	}
+	query, err := applyFilter(p.db.WithContext(ctx).Table(collection), filter)
+	if err != nil {
+		return err
+	}
+	if query, err = selectColumns(query, opts.Fields); err != nil {
+		return err
+	}
+	for _, f := range sort {
+		if !columnPattern.MatchString(f.Field) {
+			return fmt.Errorf("invalid sort field %q", f.Field)
+		}
+		query = query.Order(clause.OrderByColumn{Column: clause.Column{Name: f.Field}, Desc: f.Desc})
+	}
+	if opts.Limit > 0 {
+		query = query.Limit(int(opts.Limit))
+	}
+	if opts.Offset > 0 && opts.Cursor == nil {
+		query = query.Offset(int(opts.Offset))
+	}
+	if err := query.Find(results).Error; err != nil {
+		return err
+	}
+	if opts.Cursor != nil && opts.Cursor.Before {
+		reverseResults(results)
+	}
+	return nil
+}
+
+func (p *PostgresDatabase) Count(ctx context.Context, collection string, filter interface{}) (int64, error) {
+	query, err := applyFilter(p.db.WithContext(ctx).Table(collection), filter)
+	if err != nil {
+		return 0, err
+	}
+	var count int64
+	err = query.Count(&count).Error
+	return count, err
+}
+
 func (p *PostgresDatabase) Update(ctx context.Context, collection string, filter interface{}, update interface{}) error {
-	return p.db.WithContext(ctx).Table(collection).Where(filter).Updates(update).Error
+	query, err := applyFilter(p.db.WithContext(ctx).Table(collection), filter)
+	if err != nil {
+		return err
+	}
+	return query.Updates(update).Error
 }
 
 func (p *PostgresDatabase) Delete(ctx context.Context, collection string, filter interface{}) error {
-	return p.db.WithContext(ctx).Table(collection).Where(filter).Delete(nil).Error
+	query, err := applyFilter(p.db.WithContext(ctx).Table(collection), filter)
+	if err != nil {
+		return err
+	}
+	return query.Delete(nil).Error
+}
+
+func (p *PostgresDatabase) Transaction(ctx context.Context, fn func(ctx context.Context, tx Database) error) error {
+	return p.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(ctx, &PostgresDatabase{db: tx})
+	})
+}
+
+func (p *PostgresDatabase) Lock(ctx context.Context, collection string, filter interface{}) error {
+	query, err := applyFilter(p.db.WithContext(ctx).Table(collection), filter)
+	if err != nil {
+		return err
+	}
+	// SELECT ... FOR UPDATE holds row locks until the transaction ends
+	var ids []int64
+	return query.Clauses(clause.Locking{Strength: "UPDATE"}).Pluck("id", &ids).Error
+}
+
+// selectColumns limits the query to the given columns, all when none
+func selectColumns(query *gorm.DB, fields []string) (*gorm.DB, error) {
+	if len(fields) == 0 {
+		return query, nil
+	}
+	for _, field := range fields {
+		if !columnPattern.MatchString(field) {
+			return nil, fmt.Errorf("invalid field %q", field)
+		}
+	}
+	return query.Select(fields), nil
 }
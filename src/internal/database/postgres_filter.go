@@ -0,0 +1,216 @@
+package database
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"gorm.io/gorm"
+)
+
+// Column names are interpolated into SQL, so only plain identifiers are allowed
+var columnPattern = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_.]*$`)
+
+// applyFilter adds the filter to the query. Repositories build Mongo-style
+// filters (bson.M with $-operators) so the same filter works on both backends;
+// gorm does not understand those, so they are translated to SQL here.
+func applyFilter(query *gorm.DB, filter interface{}) (*gorm.DB, error) {
+	if filter == nil {
+		return query, nil
+	}
+
+	m, ok := toFilterMap(filter)
+	if !ok {
+		// Structs and other gorm-native conditions are passed through
+		return query.Where(filter), nil
+	}
+	if len(m) == 0 {
+		return query, nil
+	}
+
+	sql, args, err := buildConditions(m)
+	if err != nil {
+		return nil, err
+	}
+	return query.Where(sql, args...), nil
+}
+
+// toFilterMap converts bson.M and plain maps into a map[string]interface{}
+func toFilterMap(filter interface{}) (map[string]interface{}, bool) {
+	switch f := filter.(type) {
+	case bson.M:
+		return f, true
+	case map[string]interface{}:
+		return f, true
+	}
+	return nil, false
+}
+
+// buildConditions translates a filter into a SQL condition joined with AND
+func buildConditions(filter map[string]interface{}) (string, []interface{}, error) {
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	args := make([]interface{}, 0, len(keys))
+
+	for _, key := range keys {
+		value := filter[key]
+
+		switch key {
+		case "$or", "$and":
+			sql, subArgs, err := buildLogical(key, value)
+			if err != nil {
+				return "", nil, err
+			}
+			parts = append(parts, sql)
+			args = append(args, subArgs...)
+			continue
+		}
+
+		if !columnPattern.MatchString(key) {
+			return "", nil, fmt.Errorf("invalid filter field %q", key)
+		}
+
+		if ops, ok := toFilterMap(value); ok && hasOperators(ops) {
+			sql, opArgs, err := buildOperators(key, ops)
+			if err != nil {
+				return "", nil, err
+			}
+			parts = append(parts, sql)
+			args = append(args, opArgs...)
+			continue
+		}
+
+		if value == nil {
+			parts = append(parts, fmt.Sprintf("%s IS NULL", key))
+			continue
+		}
+		parts = append(parts, fmt.Sprintf("%s = ?", key))
+		args = append(args, value)
+	}
+
+	if len(parts) == 0 {
+		return "1 = 1", nil, nil
+	}
+	return strings.Join(parts, " AND "), args, nil
+}
+
+// buildLogical translates $or/$and with a list of sub filters
+func buildLogical(op string, value interface{}) (string, []interface{}, error) {
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return "", nil, fmt.Errorf("%s expects a list of filters", op)
+	}
+
+	joiner := " OR "
+	if op == "$and" {
+		joiner = " AND "
+	}
+
+	parts := make([]string, 0, rv.Len())
+	args := make([]interface{}, 0)
+	for i := 0; i < rv.Len(); i++ {
+		sub, ok := toFilterMap(rv.Index(i).Interface())
+		if !ok {
+			return "", nil, fmt.Errorf("%s expects a list of filters", op)
+		}
+		sql, subArgs, err := buildConditions(sub)
+		if err != nil {
+			return "", nil, err
+		}
+		parts = append(parts, "("+sql+")")
+		args = append(args, subArgs...)
+	}
+
+	if len(parts) == 0 {
+		return "1 = 1", nil, nil
+	}
+	return "(" + strings.Join(parts, joiner) + ")", args, nil
+}
+
+// hasOperators reports whether the map holds $-operators rather than a value
+func hasOperators(m map[string]interface{}) bool {
+	for key := range m {
+		if strings.HasPrefix(key, "$") {
+			return true
+		}
+	}
+	return false
+}
+
+// buildOperators translates comparison operators for a single column
+func buildOperators(column string, ops map[string]interface{}) (string, []interface{}, error) {
+	keys := make([]string, 0, len(ops))
+	for key := range ops {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	args := make([]interface{}, 0, len(keys))
+
+	for _, op := range keys {
+		value := ops[op]
+		switch op {
+		case "$eq":
+			if value == nil {
+				parts = append(parts, fmt.Sprintf("%s IS NULL", column))
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("%s = ?", column))
+			args = append(args, value)
+		case "$ne":
+			if value == nil {
+				parts = append(parts, fmt.Sprintf("%s IS NOT NULL", column))
+				continue
+			}
+			parts = append(parts, fmt.Sprintf("(%s <> ? OR %s IS NULL)", column, column))
+			args = append(args, value)
+		case "$gt":
+			parts = append(parts, fmt.Sprintf("%s > ?", column))
+			args = append(args, value)
+		case "$gte":
+			parts = append(parts, fmt.Sprintf("%s >= ?", column))
+			args = append(args, value)
+		case "$lt":
+			parts = append(parts, fmt.Sprintf("%s < ?", column))
+			args = append(args, value)
+		case "$lte":
+			parts = append(parts, fmt.Sprintf("%s <= ?", column))
+			args = append(args, value)
+		case "$in":
+			parts = append(parts, fmt.Sprintf("%s IN ?", column))
+			args = append(args, value)
+		case "$nin":
+			parts = append(parts, fmt.Sprintf("%s NOT IN ?", column))
+			args = append(args, value)
+		case "$exists":
+			exists, _ := value.(bool)
+			if exists {
+				parts = append(parts, fmt.Sprintf("%s IS NOT NULL", column))
+			} else {
+				parts = append(parts, fmt.Sprintf("%s IS NULL", column))
+			}
+		case "$regex":
+			operator := "~"
+			if options, _ := ops["$options"].(string); strings.Contains(options, "i") {
+				operator = "~*"
+			}
+			parts = append(parts, fmt.Sprintf("%s %s ?", column, operator))
+			args = append(args, value)
+		case "$options":
+			// Consumed by $regex
+		default:
+			return "", nil, fmt.Errorf("unsupported filter operator %q on %q", op, column)
+		}
+	}
+
+	return strings.Join(parts, " AND "), args, nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SortField orders results by a field, ascending unless Desc is set
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// FindOptions controls a FindWith query. Zero Limit and Offset mean no
+// limit and no offset, as they do for Find. With a Cursor the rows after
+// (or before) it are read instead of skipping Offset rows.
+type FindOptions struct {
+	Limit  int64
+	Offset int64
+	Sort   []SortField
+	Cursor *Cursor
+	// Fields limits the columns read; nil reads them all
+	Fields []string
+}
+
+// ParseSort reads a comma separated sort list such as "-created_at,last_name",
+// where a leading minus sorts descending. Only the allowed fields are accepted.
+func ParseSort(raw string, allowed []string) ([]SortField, error) {
+	var fields []SortField
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		f := SortField{Field: strings.TrimPrefix(part, "-"), Desc: strings.HasPrefix(part, "-")}
+		if !containsField(allowed, f.Field) {
+			return nil, fmt.Errorf("cannot sort by %q", f.Field)
+		}
+		if seen[f.Field] {
+			return nil, fmt.Errorf("%q is sorted twice", f.Field)
+		}
+		seen[f.Field] = true
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
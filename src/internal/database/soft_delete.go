@@ -0,0 +1,35 @@
+package database
+
+import "go.mongodb.org/mongo-driver/bson"
+
+// DeletedAtField is the column that marks soft-deleted rows
+const DeletedAtField = "deleted_at"
+
+// NotDeleted returns a copy of filter that also excludes soft-deleted rows.
+// Missing and null values both match on Mongo, NULL on Postgres. A filter
+// that already constrains deleted_at is returned as is.
+func NotDeleted(filter bson.M) bson.M {
+	if _, ok := filter[DeletedAtField]; ok {
+		return filter
+	}
+	result := make(bson.M, len(filter)+1)
+	for key, value := range filter {
+		result[key] = value
+	}
+	result[DeletedAtField] = nil
+	return result
+}
+
+// OnlyDeleted returns a copy of filter that matches soft-deleted rows only.
+// A filter that already constrains deleted_at is returned as is.
+func OnlyDeleted(filter bson.M) bson.M {
+	if _, ok := filter[DeletedAtField]; ok {
+		return filter
+	}
+	result := make(bson.M, len(filter)+1)
+	for key, value := range filter {
+		result[key] = value
+	}
+	result[DeletedAtField] = bson.M{"$ne": nil}
+	return result
+}
@@ -3,6 +3,8 @@ package event
 import (
 	"fmt"
 	"time"
+
+	"apidesign/internal/database"
 )
 
 // EventType represents the event_types table
@@ -34,9 +36,20 @@ type Event struct {
 	StartDate   time.Time `json:"start_date" db:"start_date"`
 	EndDate     time.Time `json:"end_date" db:"end_date"`
 	Status      string    `json:"status" db:"status"`
+	Capacity    int       `json:"capacity" db:"capacity"` // 0 = ไม่จำกัดจำนวน
+	Venue       string    `json:"venue,omitempty" db:"venue"`
+	TimeZone    string    `json:"time_zone,omitempty" db:"time_zone"` // IANA เช่น Asia/Bangkok, ว่าง = UTC
 	CreatedAt   time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
 
+	// UID จากไฟล์ iCalendar ที่ import เข้ามา
+	UID string `json:"uid,omitempty" db:"uid"`
+
+	// Recurrence ตาม RFC 5545 (EXDATE/RDATE คั่นด้วย comma)
+	RRule  string `json:"rrule,omitempty" db:"rrule"`
+	ExDate string `json:"exdate,omitempty" db:"exdate"`
+	RDate  string `json:"rdate,omitempty" db:"rdate"`
+
 	// Relations (ไม่มีในฐานข้อมูล แต่ใช้สำหรับ join)
 	EventType   *EventType     `json:"event_type,omitempty" db:"-"`
 	Category    *EventCategory `json:"category,omitempty" db:"-"`
@@ -50,6 +63,50 @@ const (
 	EventStatusCompleted = "completed"
 )
 
+// EventColumns are the columns of the events table; events can be sorted
+// by and ?fields= can select any of them
+var EventColumns = database.Columns(Event{})
+
+// ListEventsParams holds the filters for listing events
+type ListEventsParams struct {
+	EventTypeID int
+	CategoryID  int
+	Status      string
+	From        time.Time
+	To          time.Time
+	Limit       int64
+	Offset      int64
+
+	// Sort orders the events; they are ordered by ID when empty
+	Sort []database.SortField
+	// Cursor continues from a previous page instead of skipping Offset
+	Cursor *database.Cursor
+	// Fields selects the fields read, all when empty
+	Fields []string
+
+	// IncludeSubcategories matches events in any subcategory of CategoryID
+	IncludeSubcategories bool
+}
+
+// IsValidStatus reports whether status is one of the EventStatus constants
+func IsValidStatus(status string) bool {
+	switch status {
+	case EventStatusDraft, EventStatusPublished, EventStatusCanceled, EventStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// IsColumn reports whether field is one of the EventColumns
+func IsColumn(field string) bool {
+	for _, c := range EventColumns {
+		if c == field {
+			return true
+		}
+	}
+	return false
+}
+
 // Validate ตรวจสอบความถูกต้องของข้อมูล Event
 func (e *Event) Validate() error {
 	if e.Title == "" {
@@ -70,6 +127,57 @@ func (e *Event) Validate() error {
 	if e.EndDate.Before(e.StartDate) {
 		return fmt.Errorf("end date must be after start date")
 	}
+	if e.Status != "" && !IsValidStatus(e.Status) {
+		return fmt.Errorf("invalid status %q", e.Status)
+	}
+	if e.Capacity < 0 {
+		return fmt.Errorf("capacity must be non-negative")
+	}
+	if len(e.Venue) > 255 {
+		return fmt.Errorf("venue must be at most 255 characters")
+	}
+	if _, err := LoadTimeZone(e.TimeZone); err != nil {
+		return err
+	}
+	if err := e.validateRecurrence(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Validate ตรวจสอบพารามิเตอร์สำหรับการค้นหา Event
+func (p ListEventsParams) Validate() error {
+	if p.Limit < 0 {
+		return fmt.Errorf("limit must be non-negative")
+	}
+	if p.Offset < 0 {
+		return fmt.Errorf("offset must be non-negative")
+	}
+	if p.Cursor != nil && p.Offset > 0 {
+		return fmt.Errorf("use either a cursor or an offset")
+	}
+	for _, f := range p.Sort {
+		if !IsColumn(f.Field) {
+			return fmt.Errorf("cannot sort by %q", f.Field)
+		}
+	}
+	for _, f := range p.Fields {
+		if !IsColumn(f) {
+			return fmt.Errorf("unknown field %q", f)
+		}
+	}
+	if p.EventTypeID < 0 {
+		return fmt.Errorf("event type ID must be non-negative")
+	}
+	if p.CategoryID < 0 {
+		return fmt.Errorf("category ID must be non-negative")
+	}
+	if p.Status != "" && !IsValidStatus(p.Status) {
+		return fmt.Errorf("invalid status %q", p.Status)
+	}
+	if !p.From.IsZero() && !p.To.IsZero() && p.To.Before(p.From) {
+		return fmt.Errorf("to must be after from")
+	}
 	return nil
 }
 
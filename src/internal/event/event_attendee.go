@@ -0,0 +1,114 @@
+package event
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RSVP status constants
+const (
+	RSVPInvited   = "invited"
+	RSVPAccepted  = "accepted"
+	RSVPDeclined  = "declined"
+	RSVPTentative = "tentative"
+
+	// RSVPWaitlisted is set by the system when an event is full
+	RSVPWaitlisted = "waitlisted"
+)
+
+// Attendee role constants
+const (
+	RoleAttendee  = "attendee"
+	RoleOrganizer = "organizer"
+	RoleSpeaker   = "speaker"
+	RoleOptional  = "optional"
+)
+
+// Attendee represents the event_attendees table (contact ที่เข้าร่วม event)
+type Attendee struct {
+	ID          int        `json:"id" db:"id"`
+	EventID     int        `json:"event_id" db:"event_id"`
+	ContactID   int        `json:"contact_id" db:"contact_id"`
+	RSVPStatus  string     `json:"rsvp_status" db:"rsvp_status"`
+	Role        string     `json:"role" db:"role"`
+	CheckedInAt *time.Time `json:"checked_in_at,omitempty" db:"checked_in_at"`
+
+	// ลำดับใน waitlist เรียงตาม WaitlistedAt
+	WaitlistedAt     *time.Time `json:"waitlisted_at,omitempty" db:"waitlisted_at"`
+	WaitlistPosition int        `json:"waitlist_position,omitempty" db:"-" gorm:"-" bson:"-"`
+
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// IsValidRSVP reports whether status is one of the RSVP constants
+func IsValidRSVP(status string) bool {
+	switch status {
+	case RSVPInvited, RSVPAccepted, RSVPDeclined, RSVPTentative, RSVPWaitlisted:
+		return true
+	}
+	return false
+}
+
+// IsValidRole reports whether role is one of the attendee role constants
+func IsValidRole(role string) bool {
+	switch role {
+	case RoleAttendee, RoleOrganizer, RoleSpeaker, RoleOptional:
+		return true
+	}
+	return false
+}
+
+// Validate ตรวจสอบความถูกต้องของข้อมูล Attendee
+func (a *Attendee) Validate() error {
+	if a.EventID <= 0 {
+		return fmt.Errorf("event ID is required")
+	}
+	if a.ContactID <= 0 {
+		return fmt.Errorf("contact ID is required")
+	}
+	if !IsValidRSVP(a.RSVPStatus) {
+		return fmt.Errorf("invalid rsvp status %q", a.RSVPStatus)
+	}
+	if !IsValidRole(a.Role) {
+		return fmt.Errorf("invalid role %q", a.Role)
+	}
+	if a.CheckedInAt != nil && a.RSVPStatus != RSVPAccepted {
+		return fmt.Errorf("only accepted attendees can be checked in")
+	}
+	return nil
+}
+
+// BeforeCreate กำหนดค่าเริ่มต้นก่อนบันทึกข้อมูลใหม่
+func (a *Attendee) BeforeCreate() {
+	now := time.Now()
+	if a.RSVPStatus == "" {
+		a.RSVPStatus = RSVPInvited
+	}
+	if a.Role == "" {
+		a.Role = RoleAttendee
+	}
+	a.CreatedAt = now
+	a.UpdatedAt = now
+}
+
+// BeforeUpdate อัพเดทเวลาก่อนบันทึกการแก้ไข
+func (a *Attendee) BeforeUpdate() {
+	a.UpdatedAt = time.Now()
+}
+
+// SortWaitlist orders waitlisted attendees by when they joined the waitlist
+// and fills in their 1-based WaitlistPosition
+func SortWaitlist(attendees []Attendee) {
+	sort.SliceStable(attendees, func(i, j int) bool {
+		a, b := attendees[i], attendees[j]
+		if a.WaitlistedAt == nil || b.WaitlistedAt == nil || a.WaitlistedAt.Equal(*b.WaitlistedAt) {
+			return a.ID < b.ID
+		}
+		return a.WaitlistedAt.Before(*b.WaitlistedAt)
+	})
+	for i := range attendees {
+		attendees[i].WaitlistPosition = i + 1
+	}
+}
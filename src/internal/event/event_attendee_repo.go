@@ -0,0 +1,61 @@
+package event
+
+import (
+	"context"
+
+	"apidesign/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const attendeesCollection = "event_attendees"
+
+type AttendeeRepo struct {
+	db database.Database // Reference to the Database interface
+}
+
+// NewAttendeeRepo creates a new AttendeeRepo backed by the given database
+func NewAttendeeRepo(db database.Database) *AttendeeRepo {
+	return &AttendeeRepo{db: db}
+}
+
+// WithTx returns a copy of the repo that runs inside the given transaction
+func (repo *AttendeeRepo) WithTx(tx database.Database) *AttendeeRepo {
+	return &AttendeeRepo{db: tx}
+}
+
+// CreateAttendee links a contact to an event
+func (repo *AttendeeRepo) CreateAttendee(ctx context.Context, attendee *Attendee) error {
+	return repo.db.Create(ctx, attendeesCollection, attendee)
+}
+
+// GetAttendee retrieves the attendance of a contact at an event
+func (repo *AttendeeRepo) GetAttendee(ctx context.Context, eventID int, contactID int) (Attendee, error) {
+	var attendee Attendee
+	err := repo.db.FindOne(ctx, attendeesCollection, bson.M{"event_id": eventID, "contact_id": contactID}, &attendee)
+	return attendee, err
+}
+
+// UpdateAttendee updates an existing attendance. Columns are listed
+// explicitly so that cleared check-in and waitlist times are written too.
+func (repo *AttendeeRepo) UpdateAttendee(ctx context.Context, attendee *Attendee) error {
+	return repo.db.Update(ctx, attendeesCollection, bson.M{"id": attendee.ID}, map[string]interface{}{
+		"rsvp_status":   attendee.RSVPStatus,
+		"role":          attendee.Role,
+		"checked_in_at": attendee.CheckedInAt,
+		"waitlisted_at": attendee.WaitlistedAt,
+		"updated_at":    attendee.UpdatedAt,
+	})
+}
+
+// DeleteAttendee removes a contact from an event
+func (repo *AttendeeRepo) DeleteAttendee(ctx context.Context, eventID int, contactID int) error {
+	return repo.db.Delete(ctx, attendeesCollection, bson.M{"event_id": eventID, "contact_id": contactID})
+}
+
+// FindAttendees retrieves attendances based on conditions, limit, and offset
+func (repo *AttendeeRepo) FindAttendees(ctx context.Context, filter bson.M, limit int64, offset int64) ([]Attendee, error) {
+	var attendees []Attendee
+	err := repo.db.Find(ctx, attendeesCollection, filter, &attendees, limit, offset)
+	return attendees, err
+}
@@ -0,0 +1,160 @@
+package event
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Calendar views
+const (
+	CalendarDay   = "day"
+	CalendarWeek  = "week"
+	CalendarMonth = "month"
+)
+
+// CalendarBucket holds the occurrences overlapping one calendar day
+type CalendarBucket struct {
+	Date        string       `json:"date"` // YYYY-MM-DD ในโซนเวลาที่แสดงผล
+	Start       time.Time    `json:"start"`
+	End         time.Time    `json:"end"`
+	Occurrences []Occurrence `json:"occurrences"`
+}
+
+// CalendarView is a day, week or month of occurrences grouped by day
+type CalendarView struct {
+	View     string           `json:"view"`
+	TimeZone string           `json:"time_zone"`
+	Start    time.Time        `json:"start"`
+	End      time.Time        `json:"end"`
+	Buckets  []CalendarBucket `json:"buckets"`
+}
+
+// BusyInterval is a period in which at least one event takes place
+type BusyInterval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// CalendarRange returns [from, to) of the view containing date, as
+// midnights in loc. Weeks start on Monday.
+func CalendarRange(view string, date time.Time, loc *time.Location) (time.Time, time.Time, error) {
+	date = date.In(loc)
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, loc)
+
+	switch view {
+	case CalendarDay:
+		return day, day.AddDate(0, 0, 1), nil
+	case CalendarWeek:
+		offset := (int(day.Weekday()) + 6) % 7
+		start := day.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 7), nil
+	case CalendarMonth:
+		start := time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, loc)
+		return start, start.AddDate(0, 1, 0), nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("invalid view %q: must be day, week or month", view)
+}
+
+// BuildCalendar groups occurrences into one bucket per day of [from, to).
+// Occurrences spanning several days appear in each of them.
+func BuildCalendar(view string, from, to time.Time, occurrences []Occurrence) CalendarView {
+	loc := from.Location()
+	cal := CalendarView{
+		View:     view,
+		TimeZone: loc.String(),
+		Start:    from,
+		End:      to,
+		Buckets:  []CalendarBucket{},
+	}
+
+	// Days are stepped with AddDate so DST days keep their midnights
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		bucket := CalendarBucket{
+			Date:        day.Format("2006-01-02"),
+			Start:       day,
+			End:         next,
+			Occurrences: []Occurrence{},
+		}
+		for _, occ := range occurrences {
+			if Overlaps(occ.StartDate, occ.EndDate, day, next) ||
+				(occ.StartDate.Equal(occ.EndDate) && !occ.StartDate.Before(day) && occ.StartDate.Before(next)) {
+				bucket.Occurrences = append(bucket.Occurrences, occ.In(loc))
+			}
+		}
+		cal.Buckets = append(cal.Buckets, bucket)
+	}
+	return cal
+}
+
+// MergeBusy clips occurrences to [from, to) and merges overlapping or
+// touching ones into sorted busy intervals
+func MergeBusy(occurrences []Occurrence, from, to time.Time) []BusyInterval {
+	intervals := make([]BusyInterval, 0, len(occurrences))
+	for _, occ := range occurrences {
+		start, end := occ.StartDate, occ.EndDate
+		if start.Before(from) {
+			start = from
+		}
+		if end.After(to) {
+			end = to
+		}
+		if end.After(start) {
+			intervals = append(intervals, BusyInterval{Start: start, End: end})
+		}
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i].Start.Before(intervals[j].Start) })
+
+	merged := []BusyInterval{}
+	for _, iv := range intervals {
+		last := len(merged) - 1
+		if last >= 0 && !iv.Start.After(merged[last].End) {
+			if iv.End.After(merged[last].End) {
+				merged[last].End = iv.End
+			}
+			continue
+		}
+		merged = append(merged, iv)
+	}
+	return merged
+}
+
+// MaxFreeBusyWindow bounds the period of a free/busy query
+const MaxFreeBusyWindow = 366 * 24 * time.Hour
+
+// FreeBusyParams selects the contacts and categories to compute busy time for
+type FreeBusyParams struct {
+	From                 time.Time `json:"from"`
+	To                   time.Time `json:"to"`
+	ContactIDs           []int     `json:"contact_ids"`
+	CategoryIDs          []int     `json:"category_ids"`
+	IncludeSubcategories bool      `json:"include_subcategories"`
+}
+
+// FreeBusy holds merged busy intervals overall and per contact/category
+type FreeBusy struct {
+	From       time.Time              `json:"from"`
+	To         time.Time              `json:"to"`
+	Busy       []BusyInterval         `json:"busy"`
+	Contacts   map[int][]BusyInterval `json:"contacts,omitempty"`
+	Categories map[int][]BusyInterval `json:"categories,omitempty"`
+}
+
+// Validate ตรวจสอบพารามิเตอร์สำหรับการค้นหาช่วงเวลาว่าง/ไม่ว่าง
+func (p FreeBusyParams) Validate() error {
+	if p.From.IsZero() || p.To.IsZero() {
+		return fmt.Errorf("from and to are required")
+	}
+	if !p.To.After(p.From) {
+		return fmt.Errorf("to must be after from")
+	}
+	if p.To.Sub(p.From) > MaxFreeBusyWindow {
+		return fmt.Errorf("window must not exceed %d days", int(MaxFreeBusyWindow.Hours()/24))
+	}
+	if len(p.ContactIDs) == 0 && len(p.CategoryIDs) == 0 {
+		return fmt.Errorf("at least one contact or category is required")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package event
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"apidesign/internal/models"
+)
+
+// EventCategoryTree is a category with its nested subcategories
+type EventCategoryTree struct {
+	EventCategory
+	Children []EventCategoryTree `json:"children"`
+}
+
+// Validate ตรวจสอบความถูกต้องของข้อมูล EventCategory
+func (c *EventCategory) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if c.ParentID != nil && *c.ParentID < 1 {
+		return fmt.Errorf("parent ID must be positive")
+	}
+	return nil
+}
+
+// BeforeCreate กำหนดค่าเริ่มต้นก่อนบันทึกข้อมูลใหม่
+func (c *EventCategory) BeforeCreate() {
+	now := time.Now()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+}
+
+// BeforeUpdate อัพเดทเวลาก่อนบันทึกการแก้ไข
+func (c *EventCategory) BeforeUpdate() {
+	c.UpdatedAt = time.Now()
+}
+
+// TreeNode returns the parent link of the category
+func (c *EventCategory) TreeNode() models.TreeNode {
+	node := models.TreeNode{ID: c.ID}
+	if c.ParentID != nil {
+		node.ParentID = *c.ParentID
+	}
+	return node
+}
+
+// CategoryNodes returns the parent links of the categories
+func CategoryNodes(categories []EventCategory) []models.TreeNode {
+	nodes := make([]models.TreeNode, len(categories))
+	for i := range categories {
+		nodes[i] = categories[i].TreeNode()
+	}
+	return nodes
+}
+
+// BuildCategoryTree nests the subtree rooted at rootID
+func BuildCategoryTree(categories []EventCategory, rootID int) (EventCategoryTree, bool) {
+	byID := make(map[int]EventCategory, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+	root, ok := byID[rootID]
+	if !ok {
+		return EventCategoryTree{}, false
+	}
+
+	children := models.Children(CategoryNodes(categories))
+	visited := make(map[int]bool)
+
+	var build func(c EventCategory) EventCategoryTree
+	build = func(c EventCategory) EventCategoryTree {
+		visited[c.ID] = true
+		tree := EventCategoryTree{EventCategory: c, Children: []EventCategoryTree{}}
+		for _, childID := range children[c.ID] {
+			if !visited[childID] {
+				tree.Children = append(tree.Children, build(byID[childID]))
+			}
+		}
+		return tree
+	}
+	return build(root), true
+}
@@ -0,0 +1,55 @@
+package event
+
+import (
+	"context"
+
+	"apidesign/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const categoriesCollection = "event_categories"
+
+type EventCategoryRepo struct {
+	db database.Database // Reference to the Database interface
+}
+
+// NewEventCategoryRepo creates a new EventCategoryRepo backed by the given database
+func NewEventCategoryRepo(db database.Database) *EventCategoryRepo {
+	return &EventCategoryRepo{db: db}
+}
+
+// CreateCategory adds a new event category
+func (repo *EventCategoryRepo) CreateCategory(ctx context.Context, category *EventCategory) error {
+	return repo.db.Create(ctx, categoriesCollection, category)
+}
+
+// GetCategory retrieves an event category by ID
+func (repo *EventCategoryRepo) GetCategory(ctx context.Context, id int) (EventCategory, error) {
+	var category EventCategory
+	err := repo.db.FindOne(ctx, categoriesCollection, bson.M{"id": id}, &category)
+	return category, err
+}
+
+// UpdateCategory updates an existing event category. Fields are written
+// explicitly so that clearing the parent moves the category to the root.
+func (repo *EventCategoryRepo) UpdateCategory(ctx context.Context, category *EventCategory) error {
+	return repo.db.Update(ctx, categoriesCollection, bson.M{"id": category.ID}, map[string]interface{}{
+		"name":        category.Name,
+		"description": category.Description,
+		"parent_id":   category.ParentID,
+		"updated_at":  category.UpdatedAt,
+	})
+}
+
+// DeleteCategory removes an event category
+func (repo *EventCategoryRepo) DeleteCategory(ctx context.Context, id int) error {
+	return repo.db.Delete(ctx, categoriesCollection, bson.M{"id": id})
+}
+
+// FindCategories retrieves event categories based on conditions, limit, and offset
+func (repo *EventCategoryRepo) FindCategories(ctx context.Context, filter bson.M, limit int64, offset int64) ([]EventCategory, error) {
+	var categories []EventCategory
+	err := repo.db.Find(ctx, categoriesCollection, filter, &categories, limit, offset)
+	return categories, err
+}
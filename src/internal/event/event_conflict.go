@@ -0,0 +1,85 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Conflict reasons
+const (
+	ConflictCategory = "category"
+	ConflictVenue    = "venue"
+	ConflictAttendee = "attendee"
+)
+
+// ConflictHorizon bounds how far ahead a recurring series is checked
+const ConflictHorizon = 365 * 24 * time.Hour
+
+var ErrConflict = errors.New("event conflicts with existing events")
+
+// Conflict describes an existing event that overlaps the checked one
+type Conflict struct {
+	EventID    int       `json:"event_id"`
+	Title      string    `json:"title"`
+	StartDate  time.Time `json:"start_date"` // ช่วงเวลาแรกที่ทับซ้อน
+	EndDate    time.Time `json:"end_date"`
+	Reasons    []string  `json:"reasons"`
+	ContactIDs []int     `json:"contact_ids,omitempty"`
+}
+
+// ConflictError carries the conflicts that refused a create or update
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("event overlaps %d existing event(s)", len(e.Conflicts))
+}
+
+func (e *ConflictError) Unwrap() error {
+	return ErrConflict
+}
+
+// ConflictWindow returns the period in which the event is checked for
+// conflicts: its own span, or ConflictHorizon from its start for a series
+func (e *Event) ConflictWindow() (time.Time, time.Time) {
+	from, to := e.StartDate, e.EndDate
+	if e.IsRecurring() {
+		to = from.Add(ConflictHorizon)
+	}
+	if !to.After(from) {
+		to = from.Add(time.Second)
+	}
+	return from, to
+}
+
+// SharesVenue reports whether both events name the same venue
+func (e *Event) SharesVenue(other *Event) bool {
+	venue := strings.TrimSpace(e.Venue)
+	return venue != "" && strings.EqualFold(venue, strings.TrimSpace(other.Venue))
+}
+
+// Overlaps reports whether [aStart, aEnd) and [bStart, bEnd) intersect
+func Overlaps(aStart, aEnd, bStart, bEnd time.Time) bool {
+	return aStart.Before(bEnd) && bStart.Before(aEnd)
+}
+
+// FirstOverlap returns the earliest occurrence in b that overlaps one in a.
+// Both lists must be sorted by start.
+func FirstOverlap(a, b []Occurrence) (Occurrence, bool) {
+	j := 0
+	for _, occ := range a {
+		// Occurrences of b that end before occ starts cannot overlap later ones either
+		for j < len(b) && !b[j].EndDate.After(occ.StartDate) {
+			j++
+		}
+		for k := j; k < len(b) && b[k].StartDate.Before(occ.EndDate); k++ {
+			if Overlaps(occ.StartDate, occ.EndDate, b[k].StartDate, b[k].EndDate) {
+				return b[k], true
+			}
+		}
+	}
+	return Occurrence{}, false
+}
@@ -0,0 +1,666 @@
+package event
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ICSContentType is the MIME type of iCalendar documents
+const ICSContentType = "text/calendar"
+
+const icsProductID = "-//apidesign//Events API//EN"
+
+// Custom properties that carry fields iCalendar has no slot for
+const (
+	icsPropEventType = "X-APIDESIGN-EVENT-TYPE-ID"
+	icsPropCategory  = "X-APIDESIGN-CATEGORY-ID"
+)
+
+var ErrInvalidICS = errors.New("invalid iCalendar data")
+
+// Windows zone names used by Outlook, mapped to IANA zones
+var windowsZones = map[string]string{
+	"SE Asia Standard Time":          "Asia/Bangkok",
+	"Singapore Standard Time":        "Asia/Singapore",
+	"Tokyo Standard Time":            "Asia/Tokyo",
+	"China Standard Time":            "Asia/Shanghai",
+	"India Standard Time":            "Asia/Kolkata",
+	"GMT Standard Time":              "Europe/London",
+	"W. Europe Standard Time":        "Europe/Berlin",
+	"Romance Standard Time":          "Europe/Paris",
+	"Eastern Standard Time":          "America/New_York",
+	"Central Standard Time":          "America/Chicago",
+	"Mountain Standard Time":         "America/Denver",
+	"Pacific Standard Time":          "America/Los_Angeles",
+	"AUS Eastern Standard Time":      "Australia/Sydney",
+	"UTC":                            "UTC",
+	"Coordinated Universal Time":     "UTC",
+	"Greenwich Standard Time":        "Atlantic/Reykjavik",
+	"Korea Standard Time":            "Asia/Seoul",
+	"Arabian Standard Time":          "Asia/Dubai",
+	"E. South America Standard Time": "America/Sao_Paulo",
+}
+
+var icsDurationPattern = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+// ICSEvent is a VEVENT read from an iCalendar document, together with the
+// RECURRENCE-ID components that override its occurrences
+type ICSEvent struct {
+	Index      int
+	UID        string
+	Event      Event
+	Exceptions []EventException
+}
+
+// ICSError reports a component that could not be imported
+type ICSError struct {
+	Index int    `json:"index"`
+	UID   string `json:"uid,omitempty"`
+	Line  int    `json:"line,omitempty"`
+	Error string `json:"error"`
+}
+
+// icsProperty is a single content line: NAME;PARAM=VALUE:value
+type icsProperty struct {
+	Name   string
+	Params map[string]string
+	Value  string
+	Line   int
+}
+
+type icsComponent struct {
+	Index int
+	Line  int
+	Props []icsProperty
+}
+
+// MarshalICS writes events as an iCalendar document. Canceled occurrences are
+// written as EXDATE and edited ones as RECURRENCE-ID components.
+func MarshalICS(w io.Writer, events []Event, exceptions []EventException) error {
+	byEvent := make(map[int][]EventException)
+	for _, ex := range exceptions {
+		byEvent[ex.EventID] = append(byEvent[ex.EventID], ex)
+	}
+
+	bw := bufio.NewWriter(w)
+	writeICSLine(bw, "BEGIN:VCALENDAR")
+	writeICSLine(bw, "VERSION:2.0")
+	writeICSLine(bw, "PRODID:"+icsProductID)
+	writeICSLine(bw, "CALSCALE:GREGORIAN")
+
+	stamp := FormatICalTime(time.Now())
+	for i := range events {
+		e := &events[i]
+		uid := e.ICSUID()
+
+		exdates := e.ExDate
+		var overrides []EventException
+		for _, ex := range byEvent[e.ID] {
+			if ex.Canceled {
+				exdates = joinDateValues(exdates, FormatICalTime(ex.RecurrenceID))
+				continue
+			}
+			overrides = append(overrides, ex)
+		}
+
+		writeICSLine(bw, "BEGIN:VEVENT")
+		writeICSLine(bw, "UID:"+uid)
+		writeICSLine(bw, "DTSTAMP:"+stamp)
+		writeICSLine(bw, icsTimeProp("DTSTART", e.StartDate, e))
+		writeICSLine(bw, icsTimeProp("DTEND", e.EndDate, e))
+		writeICSLine(bw, "SUMMARY:"+escapeICSText(e.Title))
+		if e.Description != "" {
+			writeICSLine(bw, "DESCRIPTION:"+escapeICSText(e.Description))
+		}
+		if e.Venue != "" {
+			writeICSLine(bw, "LOCATION:"+escapeICSText(e.Venue))
+		}
+		writeICSLine(bw, "STATUS:"+icsStatus(e.Status))
+		if strings.TrimSpace(e.RRule) != "" {
+			writeICSLine(bw, "RRULE:"+strings.TrimPrefix(strings.TrimSpace(e.RRule), "RRULE:"))
+		}
+		if exdates != "" {
+			writeICSLine(bw, icsDateListProp("EXDATE", exdates, e))
+		}
+		if e.RDate != "" {
+			writeICSLine(bw, icsDateListProp("RDATE", e.RDate, e))
+		}
+		writeICSLine(bw, icsPropEventType+":"+strconv.Itoa(e.EventTypeID))
+		writeICSLine(bw, icsPropCategory+":"+strconv.Itoa(e.CategoryID))
+		if !e.CreatedAt.IsZero() {
+			writeICSLine(bw, "CREATED:"+FormatICalTime(e.CreatedAt))
+		}
+		if !e.UpdatedAt.IsZero() {
+			writeICSLine(bw, "LAST-MODIFIED:"+FormatICalTime(e.UpdatedAt))
+		}
+		writeICSLine(bw, "END:VEVENT")
+
+		duration := e.EndDate.Sub(e.StartDate)
+		for _, ex := range overrides {
+			occ := ex.apply(e.occurrenceAt(ex.RecurrenceID, duration))
+			writeICSLine(bw, "BEGIN:VEVENT")
+			writeICSLine(bw, "UID:"+uid)
+			writeICSLine(bw, "DTSTAMP:"+stamp)
+			writeICSLine(bw, icsTimeProp("RECURRENCE-ID", ex.RecurrenceID, e))
+			writeICSLine(bw, icsTimeProp("DTSTART", occ.StartDate, e))
+			writeICSLine(bw, icsTimeProp("DTEND", occ.EndDate, e))
+			writeICSLine(bw, "SUMMARY:"+escapeICSText(occ.Title))
+			if occ.Description != "" {
+				writeICSLine(bw, "DESCRIPTION:"+escapeICSText(occ.Description))
+			}
+			writeICSLine(bw, "END:VEVENT")
+		}
+	}
+
+	writeICSLine(bw, "END:VCALENDAR")
+	return bw.Flush()
+}
+
+// ICSUID returns the iCalendar UID of the event
+func (e *Event) ICSUID() string {
+	if e.UID != "" {
+		return e.UID
+	}
+	return fmt.Sprintf("event-%d@apidesign", e.ID)
+}
+
+// ParseICS reads the VEVENTs of an iCalendar document. Components that cannot
+// be mapped onto an Event are reported in the returned errors; the error
+// result is only set when the document itself is unreadable.
+func ParseICS(r io.Reader) ([]ICSEvent, []ICSError, error) {
+	components, err := readICSComponents(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var events []ICSEvent
+	var errs []ICSError
+	masters := make(map[string]int)
+	var overrides []icsComponent
+
+	for _, comp := range components {
+		if comp.prop("RECURRENCE-ID") != nil {
+			overrides = append(overrides, comp)
+			continue
+		}
+		e, err := comp.toEvent()
+		if err != nil {
+			errs = append(errs, comp.error(err))
+			continue
+		}
+		uid := comp.value("UID")
+		if uid != "" {
+			if _, dup := masters[uid]; dup {
+				errs = append(errs, comp.error(fmt.Errorf("duplicate UID %q", uid)))
+				continue
+			}
+			masters[uid] = len(events)
+		}
+		events = append(events, ICSEvent{Index: comp.Index, UID: uid, Event: e})
+	}
+
+	for _, comp := range overrides {
+		uid := comp.value("UID")
+		master, ok := masters[uid]
+		if !ok {
+			errs = append(errs, comp.error(errors.New("RECURRENCE-ID refers to an unknown or invalid UID")))
+			continue
+		}
+		ex, err := comp.toException(events[master].Event)
+		if err != nil {
+			errs = append(errs, comp.error(err))
+			continue
+		}
+		events[master].Exceptions = append(events[master].Exceptions, ex)
+	}
+
+	return events, errs, nil
+}
+
+// readICSComponents unfolds content lines and collects the VEVENT components
+func readICSComponents(r io.Reader) ([]icsComponent, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var lines []icsProperty
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if text == "" {
+			continue
+		}
+		// Lines starting with a space or tab continue the previous line
+		if (text[0] == ' ' || text[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1].Value += text[1:]
+			continue
+		}
+		lines = append(lines, icsProperty{Value: text, Line: lineNo})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidICS, err)
+	}
+
+	var components []icsComponent
+	var current *icsComponent
+	var stack []string
+	index := 0
+	sawCalendar := false
+
+	for _, raw := range lines {
+		prop, err := parseICSProperty(raw.Value)
+		if err != nil {
+			if current != nil {
+				// Keep the line so the component reports the error
+				current.Props = append(current.Props, icsProperty{Name: "X-INVALID", Value: raw.Value, Line: raw.Line})
+				continue
+			}
+			return nil, fmt.Errorf("%w: line %d: %v", ErrInvalidICS, raw.Line, err)
+		}
+		prop.Line = raw.Line
+
+		switch prop.Name {
+		case "BEGIN":
+			name := strings.ToUpper(prop.Value)
+			stack = append(stack, name)
+			if name == "VCALENDAR" {
+				sawCalendar = true
+			}
+			if name == "VEVENT" && len(stack) == 2 {
+				current = &icsComponent{Index: index, Line: raw.Line}
+				index++
+			}
+		case "END":
+			name := strings.ToUpper(prop.Value)
+			if len(stack) == 0 || stack[len(stack)-1] != name {
+				return nil, fmt.Errorf("%w: line %d: unexpected END:%s", ErrInvalidICS, raw.Line, prop.Value)
+			}
+			stack = stack[:len(stack)-1]
+			if name == "VEVENT" && current != nil && len(stack) == 1 {
+				components = append(components, *current)
+				current = nil
+			}
+		default:
+			// Properties of nested components (VALARM) are ignored
+			if current != nil && len(stack) == 2 {
+				current.Props = append(current.Props, prop)
+			}
+		}
+	}
+
+	if !sawCalendar {
+		return nil, fmt.Errorf("%w: missing BEGIN:VCALENDAR", ErrInvalidICS)
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("%w: unterminated %s", ErrInvalidICS, stack[len(stack)-1])
+	}
+	return components, nil
+}
+
+// parseICSProperty splits NAME;PARAM=VALUE;PARAM="QUOTED":value
+func parseICSProperty(line string) (icsProperty, error) {
+	prop := icsProperty{Params: map[string]string{}}
+
+	inQuotes := false
+	colon := -1
+	for i, c := range line {
+		if c == '"' {
+			inQuotes = !inQuotes
+		}
+		if c == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return prop, fmt.Errorf("malformed content line %q", line)
+	}
+
+	head := line[:colon]
+	prop.Value = line[colon+1:]
+
+	parts := splitOutsideQuotes(head, ';')
+	prop.Name = strings.ToUpper(parts[0])
+	for _, param := range parts[1:] {
+		name, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return prop, fmt.Errorf("malformed parameter %q", param)
+		}
+		prop.Params[strings.ToUpper(name)] = strings.Trim(value, `"`)
+	}
+	return prop, nil
+}
+
+func (c icsComponent) prop(name string) *icsProperty {
+	for i := range c.Props {
+		if c.Props[i].Name == name {
+			return &c.Props[i]
+		}
+	}
+	return nil
+}
+
+func (c icsComponent) value(name string) string {
+	if p := c.prop(name); p != nil {
+		return p.Value
+	}
+	return ""
+}
+
+func (c icsComponent) error(err error) ICSError {
+	line := c.Line
+	var perr *icsPropertyError
+	if errors.As(err, &perr) {
+		line = perr.Line
+	}
+	return ICSError{Index: c.Index, UID: c.value("UID"), Line: line, Error: err.Error()}
+}
+
+type icsPropertyError struct {
+	Line int
+	Err  error
+}
+
+func (e *icsPropertyError) Error() string { return e.Err.Error() }
+func (e *icsPropertyError) Unwrap() error { return e.Err }
+
+// toEvent maps a VEVENT onto an Event
+func (c icsComponent) toEvent() (Event, error) {
+	var e Event
+	if p := c.prop("X-INVALID"); p != nil {
+		return e, &icsPropertyError{Line: p.Line, Err: fmt.Errorf("malformed content line %q", p.Value)}
+	}
+
+	e.UID = c.value("UID")
+	e.Title = unescapeICSText(c.value("SUMMARY"))
+	e.Description = unescapeICSText(c.value("DESCRIPTION"))
+	e.Venue = unescapeICSText(c.value("LOCATION"))
+
+	dtstart := c.prop("DTSTART")
+	if dtstart == nil {
+		return e, errors.New("DTSTART is required")
+	}
+	start, err := dtstart.time()
+	if err != nil {
+		return e, err
+	}
+	e.StartDate = start
+	if dtstart.Params["TZID"] != "" {
+		// Keep the zone so the series expands on its local wall clock
+		e.TimeZone = start.Location().String()
+	}
+
+	switch {
+	case c.prop("DTEND") != nil:
+		end, err := c.prop("DTEND").time()
+		if err != nil {
+			return e, err
+		}
+		e.EndDate = end
+	case c.prop("DURATION") != nil:
+		p := c.prop("DURATION")
+		d, err := parseICSDuration(p.Value)
+		if err != nil {
+			return e, &icsPropertyError{Line: p.Line, Err: err}
+		}
+		e.EndDate = start.Add(d)
+	case dtstart.Params["VALUE"] == "DATE":
+		// All-day events without DTEND last one day
+		e.EndDate = start.AddDate(0, 0, 1)
+	default:
+		e.EndDate = start
+	}
+
+	if status := c.value("STATUS"); status != "" {
+		e.Status = statusFromICS(status)
+	}
+
+	var rrules []string
+	var exdates, rdates []string
+	for _, p := range c.Props {
+		switch p.Name {
+		case "RRULE":
+			rrules = append(rrules, p.Value)
+		case "EXDATE", "RDATE":
+			values, err := p.dates()
+			if err != nil {
+				return e, err
+			}
+			if p.Name == "EXDATE" {
+				exdates = append(exdates, values...)
+			} else {
+				rdates = append(rdates, values...)
+			}
+		}
+	}
+	if len(rrules) > 1 {
+		return e, errors.New("multiple RRULE properties are not supported")
+	}
+	if len(rrules) == 1 {
+		if _, err := ParseRRule(rrules[0]); err != nil {
+			return e, err
+		}
+		e.RRule = rrules[0]
+	}
+	e.ExDate = strings.Join(exdates, ",")
+	e.RDate = strings.Join(rdates, ",")
+
+	if p := c.prop(icsPropEventType); p != nil {
+		if e.EventTypeID, err = strconv.Atoi(strings.TrimSpace(p.Value)); err != nil {
+			return e, &icsPropertyError{Line: p.Line, Err: fmt.Errorf("invalid %s", icsPropEventType)}
+		}
+	}
+	if p := c.prop(icsPropCategory); p != nil {
+		if e.CategoryID, err = strconv.Atoi(strings.TrimSpace(p.Value)); err != nil {
+			return e, &icsPropertyError{Line: p.Line, Err: fmt.Errorf("invalid %s", icsPropCategory)}
+		}
+	}
+
+	return e, nil
+}
+
+// toException maps a RECURRENCE-ID component onto an exception of master
+func (c icsComponent) toException(master Event) (EventException, error) {
+	var ex EventException
+	recurrenceID, err := c.prop("RECURRENCE-ID").time()
+	if err != nil {
+		return ex, err
+	}
+	ex.RecurrenceID = recurrenceID
+
+	if strings.EqualFold(c.value("STATUS"), "CANCELLED") {
+		ex.Canceled = true
+		return ex, nil
+	}
+
+	if title := unescapeICSText(c.value("SUMMARY")); title != master.Title {
+		ex.Title = title
+	}
+	if description := unescapeICSText(c.value("DESCRIPTION")); description != master.Description {
+		ex.Description = description
+	}
+	if p := c.prop("DTSTART"); p != nil {
+		start, err := p.time()
+		if err != nil {
+			return ex, err
+		}
+		ex.StartDate = &start
+	}
+	if p := c.prop("DTEND"); p != nil {
+		end, err := p.time()
+		if err != nil {
+			return ex, err
+		}
+		ex.EndDate = &end
+	}
+	return ex, nil
+}
+
+// time parses a DATE or DATE-TIME property, honouring its TZID parameter
+func (p *icsProperty) time() (time.Time, error) {
+	loc, err := p.location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := ParseICalTime(p.Value, loc)
+	if err != nil {
+		return time.Time{}, &icsPropertyError{Line: p.Line, Err: fmt.Errorf("%s: %v", p.Name, err)}
+	}
+	return t, nil
+}
+
+// dates parses an EXDATE/RDATE list into UTC DATE-TIME values
+func (p *icsProperty) dates() ([]string, error) {
+	loc, err := p.location()
+	if err != nil {
+		return nil, err
+	}
+	times, err := ParseDateList(p.Value, loc)
+	if err != nil {
+		return nil, &icsPropertyError{Line: p.Line, Err: fmt.Errorf("%s: %v", p.Name, err)}
+	}
+	values := make([]string, len(times))
+	for i, t := range times {
+		values[i] = FormatICalTime(t)
+	}
+	return values, nil
+}
+
+func (p *icsProperty) location() (*time.Location, error) {
+	tzid := p.Params["TZID"]
+	if tzid == "" {
+		return time.UTC, nil
+	}
+	if mapped, ok := windowsZones[tzid]; ok {
+		tzid = mapped
+	}
+	loc, err := time.LoadLocation(tzid)
+	if err != nil {
+		return nil, &icsPropertyError{Line: p.Line, Err: fmt.Errorf("%s: unknown TZID %q", p.Name, p.Params["TZID"])}
+	}
+	return loc, nil
+}
+
+// parseICSDuration parses RFC 5545 durations such as PT1H30M or P1D
+func parseICSDuration(value string) (time.Duration, error) {
+	m := icsDurationPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if m == nil || value == "P" || value == "PT" {
+		return 0, fmt.Errorf("invalid DURATION %q", value)
+	}
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, _ := strconv.Atoi(m[i+2])
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+	return d, nil
+}
+
+func icsStatus(status string) string {
+	switch status {
+	case EventStatusPublished, EventStatusCompleted:
+		return "CONFIRMED"
+	case EventStatusCanceled:
+		return "CANCELLED"
+	}
+	return "TENTATIVE"
+}
+
+func statusFromICS(status string) string {
+	switch strings.ToUpper(status) {
+	case "CONFIRMED":
+		return EventStatusPublished
+	case "CANCELLED":
+		return EventStatusCanceled
+	}
+	return EventStatusDraft
+}
+
+// writeICSLine writes a content line folded at 75 octets
+func writeICSLine(w *bufio.Writer, line string) {
+	limit := 75
+	for len(line) > limit {
+		cut := limit
+		// Do not split a multi-byte UTF-8 sequence
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		// Continuation lines start with a space, which counts towards the limit
+		limit = 74
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func escapeICSText(value string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(value)
+}
+
+func unescapeICSText(value string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(value)
+}
+
+// icsTimeProp formats a DATE-TIME property, as UTC or with the event's TZID.
+// IANA names are used as TZIDs without a VTIMEZONE, as common clients accept.
+func icsTimeProp(name string, t time.Time, e *Event) string {
+	loc := e.Location()
+	if loc == time.UTC {
+		return name + ":" + FormatICalTime(t)
+	}
+	return name + ";TZID=" + loc.String() + ":" + t.In(loc).Format("20060102T150405")
+}
+
+// icsDateListProp rewrites a stored EXDATE/RDATE list like icsTimeProp
+func icsDateListProp(name string, value string, e *Event) string {
+	loc := e.Location()
+	dates, err := ParseDateList(value, loc)
+	if err != nil {
+		return name + ":" + value
+	}
+	if loc == time.UTC {
+		return name + ":" + FormatDateList(dates)
+	}
+	values := make([]string, len(dates))
+	for i, t := range dates {
+		values[i] = t.In(loc).Format("20060102T150405")
+	}
+	return name + ";TZID=" + loc.String() + ":" + strings.Join(values, ",")
+}
+
+func joinDateValues(list, value string) string {
+	if strings.TrimSpace(list) == "" {
+		return value
+	}
+	return list + "," + value
+}
+
+func splitOutsideQuotes(value string, sep rune) []string {
+	var parts []string
+	inQuotes := false
+	start := 0
+	for i, c := range value {
+		switch {
+		case c == '"':
+			inQuotes = !inQuotes
+		case c == sep && !inQuotes:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
@@ -0,0 +1,724 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Recurrence frequencies (RFC 5545 FREQ)
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+const (
+	// maxOccurrences limits a single expansion so open-ended rules stay bounded
+	maxOccurrences = 1000
+	// maxPeriods stops rules that can never match (e.g. BYMONTH=2;BYMONTHDAY=30)
+	maxPeriods = 100000
+)
+
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+var weekdayCodes = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// WeekdayNum is a BYDAY entry such as MO, 2TU or -1FR
+type WeekdayNum struct {
+	Ordinal int
+	Weekday time.Weekday
+}
+
+// RecurrenceRule is a parsed RFC 5545 RRULE
+type RecurrenceRule struct {
+	Freq       string
+	Interval   int
+	Count      int
+	Until      time.Time
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []int
+	WeekStart  time.Weekday
+}
+
+// EventException represents the event_exceptions table: a single occurrence
+// of a recurring event that was edited or canceled without touching the series
+type EventException struct {
+	ID           int        `json:"id" db:"id"`
+	EventID      int        `json:"event_id" db:"event_id"`
+	RecurrenceID time.Time  `json:"recurrence_id" db:"recurrence_id"`
+	Canceled     bool       `json:"canceled" db:"canceled"`
+	Title        string     `json:"title,omitempty" db:"title"`
+	Description  string     `json:"description,omitempty" db:"description"`
+	StartDate    *time.Time `json:"start_date,omitempty" db:"start_date"`
+	EndDate      *time.Time `json:"end_date,omitempty" db:"end_date"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Occurrence is one expanded instance of an event
+type Occurrence struct {
+	EventID      int       `json:"event_id"`
+	RecurrenceID time.Time `json:"recurrence_id"`
+	Title        string    `json:"title"`
+	Description  string    `json:"description,omitempty"`
+	StartDate    time.Time `json:"start_date"`
+	EndDate      time.Time `json:"end_date"`
+	Status       string    `json:"status"`
+	TimeZone     string    `json:"time_zone,omitempty"`
+	Modified     bool      `json:"modified,omitempty"`
+}
+
+// ParseRRule parses an RRULE value, with or without the "RRULE:" prefix
+func ParseRRule(value string) (*RecurrenceRule, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "RRULE:")
+	rule := &RecurrenceRule{Interval: 1, WeekStart: time.Monday}
+
+	for _, part := range strings.Split(value, ";") {
+		if part == "" {
+			continue
+		}
+		name, val, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRecurrence, part)
+		}
+
+		var err error
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			rule.Freq = strings.ToUpper(val)
+			switch rule.Freq {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+			default:
+				return nil, fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRecurrence, val)
+			}
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(val)
+			if err != nil || rule.Interval < 1 {
+				return nil, fmt.Errorf("%w: INTERVAL must be a positive integer", ErrInvalidRecurrence)
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(val)
+			if err != nil || rule.Count < 1 {
+				return nil, fmt.Errorf("%w: COUNT must be a positive integer", ErrInvalidRecurrence)
+			}
+		case "UNTIL":
+			rule.Until, err = ParseICalTime(val, time.UTC)
+			if err != nil {
+				return nil, fmt.Errorf("%w: UNTIL: %v", ErrInvalidRecurrence, err)
+			}
+		case "BYDAY":
+			for _, code := range strings.Split(val, ",") {
+				wd, err := parseWeekdayNum(code)
+				if err != nil {
+					return nil, err
+				}
+				rule.ByDay = append(rule.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseIntList(val, -31, 31)
+			if err != nil {
+				return nil, fmt.Errorf("%w: BYMONTHDAY: %v", ErrInvalidRecurrence, err)
+			}
+		case "BYMONTH":
+			rule.ByMonth, err = parseIntList(val, 1, 12)
+			if err != nil {
+				return nil, fmt.Errorf("%w: BYMONTH: %v", ErrInvalidRecurrence, err)
+			}
+		case "WKST":
+			wd, ok := weekdayCodes[strings.ToUpper(val)]
+			if !ok {
+				return nil, fmt.Errorf("%w: invalid WKST %q", ErrInvalidRecurrence, val)
+			}
+			rule.WeekStart = wd
+		default:
+			return nil, fmt.Errorf("%w: unsupported part %q", ErrInvalidRecurrence, name)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrence)
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL cannot be combined", ErrInvalidRecurrence)
+	}
+	if rule.Freq == FreqDaily || rule.Freq == FreqWeekly {
+		for _, wd := range rule.ByDay {
+			if wd.Ordinal != 0 {
+				return nil, fmt.Errorf("%w: BYDAY ordinals require MONTHLY or YEARLY", ErrInvalidRecurrence)
+			}
+		}
+	}
+	if rule.Freq == FreqWeekly && len(rule.ByMonthDay) > 0 {
+		return nil, fmt.Errorf("%w: BYMONTHDAY cannot be used with WEEKLY", ErrInvalidRecurrence)
+	}
+	return rule, nil
+}
+
+// String formats the rule as an RRULE value (without the "RRULE:" prefix)
+func (r *RecurrenceRule) String() string {
+	parts := []string{"FREQ=" + r.Freq}
+	if r.Interval > 1 {
+		parts = append(parts, fmt.Sprintf("INTERVAL=%d", r.Interval))
+	}
+	if r.Count > 0 {
+		parts = append(parts, fmt.Sprintf("COUNT=%d", r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+FormatICalTime(r.Until))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			codes[i] = wd.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "BYMONTHDAY="+joinInts(r.ByMonthDay))
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "BYMONTH="+joinInts(r.ByMonth))
+	}
+	if r.WeekStart != time.Monday {
+		parts = append(parts, "WKST="+weekdayCode(r.WeekStart))
+	}
+	return strings.Join(parts, ";")
+}
+
+func (w WeekdayNum) String() string {
+	if w.Ordinal != 0 {
+		return strconv.Itoa(w.Ordinal) + weekdayCode(w.Weekday)
+	}
+	return weekdayCode(w.Weekday)
+}
+
+// ParseICalTime parses RFC 5545 DATE / DATE-TIME values (UTC or floating in
+// loc) and, for convenience, RFC 3339 timestamps
+func ParseICalTime(value string, loc *time.Location) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if t, err := time.Parse("20060102T150405Z", value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102T150405", value, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("20060102", value, loc); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date-time %q", value)
+}
+
+// FormatICalTime formats t as an RFC 5545 UTC DATE-TIME
+func FormatICalTime(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// ParseDateList parses a comma separated EXDATE/RDATE value
+func ParseDateList(value string, loc *time.Location) ([]time.Time, error) {
+	var dates []time.Time
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		t, err := ParseICalTime(item, loc)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+		}
+		dates = append(dates, t)
+	}
+	return dates, nil
+}
+
+// FormatDateList formats dates as a comma separated EXDATE/RDATE value
+func FormatDateList(dates []time.Time) string {
+	values := make([]string, len(dates))
+	for i, t := range dates {
+		values[i] = FormatICalTime(t)
+	}
+	return strings.Join(values, ",")
+}
+
+// IsRecurring reports whether the event has a recurrence rule or extra dates
+func (e *Event) IsRecurring() bool {
+	return strings.TrimSpace(e.RRule) != "" || strings.TrimSpace(e.RDate) != ""
+}
+
+// validateRecurrence ตรวจสอบ RRULE, EXDATE และ RDATE
+func (e *Event) validateRecurrence() error {
+	if strings.TrimSpace(e.RRule) != "" {
+		if _, err := ParseRRule(e.RRule); err != nil {
+			return err
+		}
+	}
+	if _, err := ParseDateList(e.ExDate, e.Location()); err != nil {
+		return err
+	}
+	if _, err := ParseDateList(e.RDate, e.Location()); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Occurrences expands the event into the instances overlapping [from, to),
+// applying EXDATE, RDATE and per-occurrence exceptions. Non-recurring events
+// yield at most one occurrence.
+func (e *Event) Occurrences(from, to time.Time, exceptions []EventException) ([]Occurrence, error) {
+	starts, err := e.instanceStarts(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]EventException, len(exceptions))
+	for _, ex := range exceptions {
+		byID[ex.RecurrenceID.Unix()] = ex
+	}
+
+	duration := e.EndDate.Sub(e.StartDate)
+	occurrences := make([]Occurrence, 0, len(starts))
+	seen := make(map[int64]bool, len(starts))
+
+	for _, start := range starts {
+		seen[start.Unix()] = true
+		occ := e.occurrenceAt(start, duration)
+		if ex, ok := byID[start.Unix()]; ok {
+			if ex.Canceled {
+				continue
+			}
+			occ = ex.apply(occ)
+		}
+		if occ.StartDate.Before(to) && occ.EndDate.After(from) {
+			occurrences = append(occurrences, occ)
+		}
+	}
+
+	// Edited occurrences may have been moved into the window from outside it
+	for _, ex := range exceptions {
+		if ex.Canceled || seen[ex.RecurrenceID.Unix()] {
+			continue
+		}
+		occ := ex.apply(e.occurrenceAt(ex.RecurrenceID, duration))
+		if !occ.StartDate.Before(to) || !occ.EndDate.After(from) {
+			continue
+		}
+		ok, err := e.IsOccurrence(ex.RecurrenceID)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			occurrences = append(occurrences, occ)
+		}
+	}
+
+	sort.Slice(occurrences, func(i, j int) bool {
+		return occurrences[i].StartDate.Before(occurrences[j].StartDate)
+	})
+	return occurrences, nil
+}
+
+// IsOccurrence reports whether t is the original start of an instance
+func (e *Event) IsOccurrence(t time.Time) (bool, error) {
+	starts, err := e.instanceStarts(t, t.Add(time.Second))
+	if err != nil {
+		return false, err
+	}
+	for _, start := range starts {
+		if start.Equal(t) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// instanceStarts returns the original start times of the instances that
+// overlap [from, to), before exceptions are applied
+func (e *Event) instanceStarts(from, to time.Time) ([]time.Time, error) {
+	duration := e.EndDate.Sub(e.StartDate)
+	if duration <= 0 {
+		// Zero-length events still occupy their start instant
+		duration = time.Second
+	}
+
+	// Expand on the wall clock of the event's zone so instances keep their
+	// local time across DST changes
+	loc := e.Location()
+	dtstart := e.StartDate.In(loc)
+
+	var starts []time.Time
+	if strings.TrimSpace(e.RRule) != "" {
+		rule, err := ParseRRule(e.RRule)
+		if err != nil {
+			return nil, err
+		}
+		starts = rule.expand(dtstart, from, to, duration, maxOccurrences)
+	} else if dtstart.Before(to) && dtstart.Add(duration).After(from) {
+		starts = []time.Time{dtstart}
+	}
+
+	rdates, err := ParseDateList(e.RDate, loc)
+	if err != nil {
+		return nil, err
+	}
+	for _, rd := range rdates {
+		if rd.Before(to) && rd.Add(duration).After(from) {
+			starts = append(starts, rd)
+		}
+	}
+
+	exdates, err := ParseDateList(e.ExDate, loc)
+	if err != nil {
+		return nil, err
+	}
+	excluded := make(map[int64]bool, len(exdates))
+	for _, ex := range exdates {
+		excluded[ex.Unix()] = true
+	}
+
+	sort.Slice(starts, func(i, j int) bool { return starts[i].Before(starts[j]) })
+	result := make([]time.Time, 0, len(starts))
+	seen := make(map[int64]bool, len(starts))
+	for _, start := range starts {
+		key := start.Unix()
+		if excluded[key] || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, start)
+	}
+	return result, nil
+}
+
+func (e *Event) occurrenceAt(start time.Time, duration time.Duration) Occurrence {
+	return Occurrence{
+		EventID:      e.ID,
+		RecurrenceID: start,
+		Title:        e.Title,
+		Description:  e.Description,
+		StartDate:    start,
+		EndDate:      start.Add(duration),
+		Status:       e.Status,
+		TimeZone:     e.TimeZone,
+	}
+}
+
+// apply overlays the exception's overrides on an occurrence
+func (ex EventException) apply(occ Occurrence) Occurrence {
+	occ.Modified = true
+	if ex.Title != "" {
+		occ.Title = ex.Title
+	}
+	if ex.Description != "" {
+		occ.Description = ex.Description
+	}
+	if ex.StartDate != nil {
+		duration := occ.EndDate.Sub(occ.StartDate)
+		occ.StartDate = *ex.StartDate
+		occ.EndDate = occ.StartDate.Add(duration)
+	}
+	if ex.EndDate != nil {
+		occ.EndDate = *ex.EndDate
+	}
+	return occ
+}
+
+// expand returns the starts of the rule's instances, beginning at dtstart,
+// whose [start, start+duration) overlaps [from, to). DTSTART is always the
+// first instance and counts towards COUNT.
+func (r *RecurrenceRule) expand(dtstart, from, to time.Time, duration time.Duration, limit int) []time.Time {
+	var starts []time.Time
+	if !dtstart.Before(to) {
+		return starts
+	}
+
+	emit := func(t time.Time) bool {
+		if t.Add(duration).After(from) {
+			starts = append(starts, t)
+		}
+		return len(starts) < limit
+	}
+
+	count := 1
+	if !emit(dtstart) || (r.Count > 0 && count >= r.Count) {
+		return starts
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		for _, candidate := range r.periodCandidates(dtstart, period) {
+			if !candidate.After(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && candidate.After(r.Until) {
+				return starts
+			}
+			if !candidate.Before(to) {
+				return starts
+			}
+			count++
+			if !emit(candidate) || (r.Count > 0 && count >= r.Count) {
+				return starts
+			}
+		}
+	}
+	return starts
+}
+
+// periodCandidates returns the sorted candidate starts in the n-th period
+// (day, week, month or year, depending on FREQ) after dtstart
+func (r *RecurrenceRule) periodCandidates(dtstart time.Time, n int) []time.Time {
+	y, m, d := dtstart.Date()
+	hh, mm, ss := dtstart.Clock()
+	loc := dtstart.Location()
+	step := n * r.Interval
+
+	var candidates []time.Time
+	switch r.Freq {
+	case FreqDaily:
+		t := time.Date(y, m, d+step, hh, mm, ss, 0, loc)
+		if r.matchesMonth(t) && r.matchesMonthDay(t) && r.matchesWeekday(t) {
+			candidates = append(candidates, t)
+		}
+
+	case FreqWeekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		weekdays := []time.Weekday{dtstart.Weekday()}
+		if len(r.ByDay) > 0 {
+			weekdays = weekdays[:0]
+			for _, wd := range r.ByDay {
+				weekdays = append(weekdays, wd.Weekday)
+			}
+		}
+		for _, wd := range weekdays {
+			delta := (int(wd) - int(r.WeekStart) + 7) % 7
+			t := time.Date(y, m, d-offset+step*7+delta, hh, mm, ss, 0, loc)
+			if r.matchesMonth(t) {
+				candidates = append(candidates, t)
+			}
+		}
+
+	case FreqMonthly:
+		first := time.Date(y, m+time.Month(step), 1, hh, mm, ss, 0, loc)
+		if r.matchesMonth(first) {
+			candidates = r.monthCandidates(first, d)
+		}
+
+	case FreqYearly:
+		year := y + step
+		if len(r.ByDay) > 0 && len(r.ByMonth) == 0 && len(r.ByMonthDay) == 0 {
+			candidates = r.yearWeekdayCandidates(year, hh, mm, ss, loc)
+			break
+		}
+		// BYMONTHDAY without BYMONTH applies to every month
+		months := r.ByMonth
+		switch {
+		case len(months) > 0:
+		case len(r.ByMonthDay) > 0:
+			months = []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+		default:
+			months = []int{int(m)}
+		}
+		for _, month := range months {
+			first := time.Date(year, time.Month(month), 1, hh, mm, ss, 0, loc)
+			candidates = append(candidates, r.monthCandidates(first, d)...)
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+	return candidates
+}
+
+// monthCandidates expands BYMONTHDAY/BYDAY within the month starting at first
+func (r *RecurrenceRule) monthCandidates(first time.Time, defaultDay int) []time.Time {
+	daysInMonth := first.AddDate(0, 1, -1).Day()
+
+	var days []int
+	switch {
+	case len(r.ByMonthDay) == 0 && len(r.ByDay) == 0:
+		if defaultDay <= daysInMonth {
+			days = []int{defaultDay}
+		}
+	case len(r.ByDay) == 0:
+		days = r.monthDays(daysInMonth)
+	case len(r.ByMonthDay) == 0:
+		days = r.weekdaysInRange(first, daysInMonth)
+	default:
+		allowed := make(map[int]bool)
+		for _, day := range r.monthDays(daysInMonth) {
+			allowed[day] = true
+		}
+		for _, day := range r.weekdaysInRange(first, daysInMonth) {
+			if allowed[day] {
+				days = append(days, day)
+			}
+		}
+	}
+
+	candidates := make([]time.Time, 0, len(days))
+	for _, day := range uniqueSorted(days) {
+		candidates = append(candidates, first.AddDate(0, 0, day-1))
+	}
+	return candidates
+}
+
+// yearWeekdayCandidates expands BYDAY over a whole year (ordinals count in the year)
+func (r *RecurrenceRule) yearWeekdayCandidates(year, hh, mm, ss int, loc *time.Location) []time.Time {
+	first := time.Date(year, time.January, 1, hh, mm, ss, 0, loc)
+	daysInYear := time.Date(year, time.December, 31, 0, 0, 0, 0, loc).YearDay()
+
+	days := uniqueSorted(r.weekdaysInRange(first, daysInYear))
+	candidates := make([]time.Time, 0, len(days))
+	for _, day := range days {
+		candidates = append(candidates, first.AddDate(0, 0, day-1))
+	}
+	return candidates
+}
+
+// weekdaysInRange returns the 1-based day numbers in [first, first+length)
+// selected by BYDAY, resolving ordinals such as 2TU or -1FR
+func (r *RecurrenceRule) weekdaysInRange(first time.Time, length int) []int {
+	var days []int
+	for _, wd := range r.ByDay {
+		offset := (int(wd.Weekday) - int(first.Weekday()) + 7) % 7
+		var matches []int
+		for day := offset + 1; day <= length; day += 7 {
+			matches = append(matches, day)
+		}
+		switch {
+		case wd.Ordinal > 0 && wd.Ordinal <= len(matches):
+			days = append(days, matches[wd.Ordinal-1])
+		case wd.Ordinal < 0 && -wd.Ordinal <= len(matches):
+			days = append(days, matches[len(matches)+wd.Ordinal])
+		case wd.Ordinal == 0:
+			days = append(days, matches...)
+		}
+	}
+	return days
+}
+
+// monthDays resolves BYMONTHDAY (negative values count from the month end)
+func (r *RecurrenceRule) monthDays(daysInMonth int) []int {
+	var days []int
+	for _, md := range r.ByMonthDay {
+		day := md
+		if md < 0 {
+			day = daysInMonth + md + 1
+		}
+		if day >= 1 && day <= daysInMonth {
+			days = append(days, day)
+		}
+	}
+	return days
+}
+
+func (r *RecurrenceRule) matchesMonth(t time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, month := range r.ByMonth {
+		if time.Month(month) == t.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RecurrenceRule) matchesMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	daysInMonth := time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, t.Location()).Day()
+	for _, day := range r.monthDays(daysInMonth) {
+		if day == t.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *RecurrenceRule) matchesWeekday(t time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func parseWeekdayNum(value string) (WeekdayNum, error) {
+	value = strings.ToUpper(strings.TrimSpace(value))
+	if len(value) < 2 {
+		return WeekdayNum{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRecurrence, value)
+	}
+	code := value[len(value)-2:]
+	wd, ok := weekdayCodes[code]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRecurrence, value)
+	}
+	result := WeekdayNum{Weekday: wd}
+	if prefix := value[:len(value)-2]; prefix != "" {
+		ordinal, err := strconv.Atoi(prefix)
+		if err != nil || ordinal == 0 || ordinal < -53 || ordinal > 53 {
+			return WeekdayNum{}, fmt.Errorf("%w: invalid BYDAY %q", ErrInvalidRecurrence, value)
+		}
+		result.Ordinal = ordinal
+	}
+	return result, nil
+}
+
+func parseIntList(value string, min, max int) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil || n == 0 || n < min || n > max {
+			return nil, fmt.Errorf("invalid value %q", item)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = strconv.Itoa(v)
+	}
+	return strings.Join(parts, ",")
+}
+
+func weekdayCode(wd time.Weekday) string {
+	for code, day := range weekdayCodes {
+		if day == wd {
+			return code
+		}
+	}
+	return ""
+}
+
+func uniqueSorted(values []int) []int {
+	sort.Ints(values)
+	result := values[:0]
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
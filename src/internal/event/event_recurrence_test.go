@@ -0,0 +1,186 @@
+package event
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOccurrences(t *testing.T) {
+	jan1 := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC) // a Monday
+	tests := []struct {
+		name     string
+		event    Event
+		from, to time.Time
+		want     []string
+	}{
+		{
+			name:  "single event",
+			event: Event{StartDate: jan1, EndDate: jan1.Add(time.Hour)},
+			from:  jan1.AddDate(0, 0, -1), to: jan1.AddDate(0, 1, 0),
+			want: []string{"2024-01-01T09:00:00Z"},
+		},
+		{
+			name:  "daily count",
+			event: Event{StartDate: jan1, EndDate: jan1.Add(time.Hour), RRule: "FREQ=DAILY;COUNT=3"},
+			from:  jan1, to: jan1.AddDate(1, 0, 0),
+			want: []string{"2024-01-01T09:00:00Z", "2024-01-02T09:00:00Z", "2024-01-03T09:00:00Z"},
+		},
+		{
+			name:  "daily until",
+			event: Event{StartDate: jan1, EndDate: jan1.Add(time.Hour), RRule: "RRULE:FREQ=DAILY;INTERVAL=2;UNTIL=20240105T090000Z"},
+			from:  jan1, to: jan1.AddDate(1, 0, 0),
+			want: []string{"2024-01-01T09:00:00Z", "2024-01-03T09:00:00Z", "2024-01-05T09:00:00Z"},
+		},
+		{
+			name:  "weekly by day",
+			event: Event{StartDate: jan1, EndDate: jan1.Add(time.Hour), RRule: "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=4"},
+			from:  jan1, to: jan1.AddDate(1, 0, 0),
+			want: []string{"2024-01-01T09:00:00Z", "2024-01-03T09:00:00Z", "2024-01-08T09:00:00Z", "2024-01-10T09:00:00Z"},
+		},
+		{
+			name: "monthly last friday",
+			event: Event{
+				StartDate: time.Date(2024, 1, 26, 9, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2024, 1, 26, 10, 0, 0, 0, time.UTC),
+				RRule:     "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			},
+			from: jan1, to: jan1.AddDate(1, 0, 0),
+			want: []string{"2024-01-26T09:00:00Z", "2024-02-23T09:00:00Z", "2024-03-29T09:00:00Z"},
+		},
+		{
+			name: "monthly day 31 skips short months",
+			event: Event{
+				StartDate: time.Date(2024, 1, 31, 9, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2024, 1, 31, 10, 0, 0, 0, time.UTC),
+				RRule:     "FREQ=MONTHLY;BYMONTHDAY=31;COUNT=3",
+			},
+			from: jan1, to: jan1.AddDate(1, 0, 0),
+			want: []string{"2024-01-31T09:00:00Z", "2024-03-31T09:00:00Z", "2024-05-31T09:00:00Z"},
+		},
+		{
+			name: "yearly leap day",
+			event: Event{
+				StartDate: time.Date(2024, 2, 29, 9, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2024, 2, 29, 10, 0, 0, 0, time.UTC),
+				RRule:     "FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=29;COUNT=2",
+			},
+			from: jan1, to: jan1.AddDate(10, 0, 0),
+			want: []string{"2024-02-29T09:00:00Z", "2028-02-29T09:00:00Z"},
+		},
+		{
+			name:  "yearly month day without month",
+			event: Event{StartDate: jan1, EndDate: jan1.Add(time.Hour), RRule: "FREQ=YEARLY;BYMONTHDAY=1;COUNT=4"},
+			from:  jan1, to: jan1.AddDate(2, 0, 0),
+			want: []string{"2024-01-01T09:00:00Z", "2024-02-01T09:00:00Z", "2024-03-01T09:00:00Z", "2024-04-01T09:00:00Z"},
+		},
+		{
+			name: "exdate and rdate",
+			event: Event{
+				StartDate: jan1, EndDate: jan1.Add(time.Hour),
+				RRule:  "FREQ=DAILY;COUNT=4",
+				ExDate: "20240102T090000Z",
+				RDate:  "20240110T090000Z",
+			},
+			from: jan1, to: jan1.AddDate(1, 0, 0),
+			want: []string{"2024-01-01T09:00:00Z", "2024-01-03T09:00:00Z", "2024-01-04T09:00:00Z", "2024-01-10T09:00:00Z"},
+		},
+		{
+			name:  "unbounded rule within window",
+			event: Event{StartDate: jan1, EndDate: jan1.Add(time.Hour), RRule: "FREQ=DAILY"},
+			from:  time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC), to: time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC),
+			want: []string{"2024-01-05T09:00:00Z", "2024-01-06T09:00:00Z", "2024-01-07T09:00:00Z"},
+		},
+		{
+			name:  "overlapping the window start",
+			event: Event{StartDate: jan1, EndDate: jan1.Add(2 * time.Hour), RRule: "FREQ=DAILY;COUNT=3"},
+			from:  time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC), to: time.Date(2024, 1, 2, 12, 0, 0, 0, time.UTC),
+			want: []string{"2024-01-02T09:00:00Z"},
+		},
+		{
+			name: "local time kept across DST",
+			event: Event{
+				StartDate: time.Date(2024, 3, 9, 14, 0, 0, 0, time.UTC), // 09:00 in New York
+				EndDate:   time.Date(2024, 3, 9, 15, 0, 0, 0, time.UTC),
+				TimeZone:  "America/New_York",
+				RRule:     "FREQ=DAILY;COUNT=2",
+			},
+			from: jan1, to: jan1.AddDate(1, 0, 0),
+			want: []string{"2024-03-09T14:00:00Z", "2024-03-10T13:00:00Z"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurrences, err := tt.event.Occurrences(tt.from, tt.to, nil)
+			if err != nil {
+				t.Fatalf("Occurrences() error = %v", err)
+			}
+			got := make([]string, len(occurrences))
+			for i, occ := range occurrences {
+				got[i] = occ.StartDate.UTC().Format(time.RFC3339)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Occurrences() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOccurrencesExceptions(t *testing.T) {
+	jan1 := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	e := Event{ID: 1, Title: "Standup", StartDate: jan1, EndDate: jan1.Add(time.Hour), RRule: "FREQ=DAILY;COUNT=3"}
+	moved := time.Date(2024, 1, 10, 15, 0, 0, 0, time.UTC)
+	exceptions := []EventException{
+		{EventID: 1, RecurrenceID: jan1.AddDate(0, 0, 1), Canceled: true},
+		{EventID: 1, RecurrenceID: jan1.AddDate(0, 0, 2), Title: "Retro", StartDate: &moved},
+	}
+
+	occurrences, err := e.Occurrences(jan1, jan1.AddDate(0, 1, 0), exceptions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(occurrences) != 2 {
+		t.Fatalf("got %d occurrences, want 2", len(occurrences))
+	}
+	if occurrences[0].Modified || !occurrences[0].StartDate.Equal(jan1) {
+		t.Errorf("first occurrence = %+v, want the unchanged first instance", occurrences[0])
+	}
+	last := occurrences[1]
+	if !last.Modified || last.Title != "Retro" || !last.StartDate.Equal(moved) || !last.EndDate.Equal(moved.Add(time.Hour)) {
+		t.Errorf("moved occurrence = %+v", last)
+	}
+	if !last.RecurrenceID.Equal(jan1.AddDate(0, 0, 2)) {
+		t.Errorf("moved occurrence keeps recurrence id %v, want %v", last.RecurrenceID, jan1.AddDate(0, 0, 2))
+	}
+}
+
+func TestParseRRule(t *testing.T) {
+	valid := []string{
+		"FREQ=DAILY",
+		"RRULE:FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR",
+		"FREQ=MONTHLY;BYDAY=2TU;UNTIL=20241231T000000Z",
+		"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1;COUNT=5",
+	}
+	for _, value := range valid {
+		if _, err := ParseRRule(value); err != nil {
+			t.Errorf("ParseRRule(%q) error = %v", value, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;COUNT=0",
+		"FREQ=DAILY;INTERVAL=-1",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=32",
+		"FREQ=DAILY;COUNT=2;UNTIL=20241231T000000Z",
+	}
+	for _, value := range invalid {
+		if _, err := ParseRRule(value); err == nil {
+			t.Errorf("ParseRRule(%q) accepted an invalid rule", value)
+		}
+	}
+}
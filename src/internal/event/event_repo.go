@@ -0,0 +1,144 @@
+package event
+
+import (
+	"context"
+
+	"apidesign/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	eventsCollection      = "events"
+	transitionsCollection = "event_transitions"
+	exceptionsCollection  = "event_exceptions"
+)
+
+type EventRepo struct {
+	db database.Database // Reference to the Database interface
+}
+
+// NewEventRepo creates a new EventRepo backed by the given database
+func NewEventRepo(db database.Database) *EventRepo {
+	return &EventRepo{db: db}
+}
+
+// WithTx returns a copy of the repo that runs inside the given transaction
+func (repo *EventRepo) WithTx(tx database.Database) *EventRepo {
+	return &EventRepo{db: tx}
+}
+
+// Transaction runs fn in a database transaction
+func (repo *EventRepo) Transaction(ctx context.Context, fn func(ctx context.Context, tx database.Database) error) error {
+	return repo.db.Transaction(ctx, fn)
+}
+
+// LockEvent locks an event row until the surrounding transaction ends
+func (repo *EventRepo) LockEvent(ctx context.Context, id int) error {
+	return repo.db.Lock(ctx, eventsCollection, bson.M{"id": id})
+}
+
+// CreateEvent adds a new event to the repository
+func (repo *EventRepo) CreateEvent(ctx context.Context, event *Event) error {
+	return repo.db.Create(ctx, eventsCollection, event)
+}
+
+// GetEvent retrieves an event by ID
+func (repo *EventRepo) GetEvent(ctx context.Context, id int) (Event, error) {
+	var event Event
+	err := repo.db.FindOne(ctx, eventsCollection, bson.M{"id": id}, &event)
+	return event, err
+}
+
+// GetEventWith retrieves an event by ID reading only the given columns,
+// all when nil
+func (repo *EventRepo) GetEventWith(ctx context.Context, id int, fields []string) (Event, error) {
+	var event Event
+	err := repo.db.FindOneWith(ctx, eventsCollection, bson.M{"id": id}, &event, fields)
+	return event, err
+}
+
+// GetEventByUID retrieves an event by its iCalendar UID
+func (repo *EventRepo) GetEventByUID(ctx context.Context, uid string) (Event, error) {
+	var event Event
+	err := repo.db.FindOne(ctx, eventsCollection, bson.M{"uid": uid}, &event)
+	return event, err
+}
+
+// UpdateEvent updates an existing event. Columns are listed explicitly so
+// that cleared values, such as an empty rrule or zero capacity, are written
+// too. The import UID never changes.
+func (repo *EventRepo) UpdateEvent(ctx context.Context, event *Event) error {
+	return repo.db.Update(ctx, eventsCollection, bson.M{"id": event.ID}, map[string]interface{}{
+		"title":         event.Title,
+		"description":   event.Description,
+		"event_type_id": event.EventTypeID,
+		"category_id":   event.CategoryID,
+		"start_date":    event.StartDate,
+		"end_date":      event.EndDate,
+		"status":        event.Status,
+		"capacity":      event.Capacity,
+		"venue":         event.Venue,
+		"time_zone":     event.TimeZone,
+		"rrule":         event.RRule,
+		"exdate":        event.ExDate,
+		"rdate":         event.RDate,
+		"updated_at":    event.UpdatedAt,
+	})
+}
+
+// DeleteEvent removes an event from the repository
+func (repo *EventRepo) DeleteEvent(ctx context.Context, id int) error {
+	return repo.db.Delete(ctx, eventsCollection, bson.M{"id": id})
+}
+
+// FindEvents retrieves events based on conditions, limit, and offset
+func (repo *EventRepo) FindEvents(ctx context.Context, filter bson.M, limit int64, offset int64) ([]Event, error) {
+	var events []Event
+	err := repo.db.Find(ctx, eventsCollection, filter, &events, limit, offset)
+	return events, err
+}
+
+// FindEventsWith retrieves events in the given order
+func (repo *EventRepo) FindEventsWith(ctx context.Context, filter bson.M, opts database.FindOptions) ([]Event, error) {
+	var events []Event
+	err := repo.db.FindWith(ctx, eventsCollection, filter, &events, opts)
+	return events, err
+}
+
+// CountEvents returns the number of events matching filter
+func (repo *EventRepo) CountEvents(ctx context.Context, filter bson.M) (int64, error) {
+	return repo.db.Count(ctx, eventsCollection, filter)
+}
+
+// CreateTransition records a status transition of an event
+func (repo *EventRepo) CreateTransition(ctx context.Context, transition *EventTransition) error {
+	return repo.db.Create(ctx, transitionsCollection, transition)
+}
+
+// FindTransitions retrieves the transition history of an event
+func (repo *EventRepo) FindTransitions(ctx context.Context, eventID int, limit int64, offset int64) ([]EventTransition, error) {
+	var transitions []EventTransition
+	err := repo.db.Find(ctx, transitionsCollection, bson.M{"event_id": eventID}, &transitions, limit, offset)
+	return transitions, err
+}
+
+// SaveException stores the exception for one occurrence, replacing any
+// earlier exception for the same recurrence ID
+func (repo *EventRepo) SaveException(ctx context.Context, exception *EventException) error {
+	filter := bson.M{"event_id": exception.EventID, "recurrence_id": exception.RecurrenceID}
+	if err := repo.db.Delete(ctx, exceptionsCollection, filter); err != nil {
+		return err
+	}
+	return repo.db.Create(ctx, exceptionsCollection, exception)
+}
+
+// FindExceptions retrieves the occurrence exceptions of the given events
+func (repo *EventRepo) FindExceptions(ctx context.Context, eventIDs []int) ([]EventException, error) {
+	var exceptions []EventException
+	if len(eventIDs) == 0 {
+		return exceptions, nil
+	}
+	err := repo.db.Find(ctx, exceptionsCollection, bson.M{"event_id": bson.M{"$in": eventIDs}}, &exceptions, 0, 0)
+	return exceptions, err
+}
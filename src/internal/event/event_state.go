@@ -0,0 +1,193 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Transition actions
+const (
+	ActionPublish  = "publish"
+	ActionCancel   = "cancel"
+	ActionComplete = "complete"
+)
+
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// EventTransition represents the event_transitions table (ประวัติการเปลี่ยนสถานะ)
+type EventTransition struct {
+	ID         int       `json:"id" db:"id"`
+	EventID    int       `json:"event_id" db:"event_id"`
+	Action     string    `json:"action" db:"action"`
+	FromStatus string    `json:"from_status" db:"from_status"`
+	ToStatus   string    `json:"to_status" db:"to_status"`
+	Reason     string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// TransitionError explains why a transition was refused
+type TransitionError struct {
+	Action string
+	From   string
+	Reason string
+}
+
+func (e *TransitionError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("cannot %s event in status %q: %s", e.Action, e.From, e.Reason)
+	}
+	return fmt.Sprintf("cannot %s event in status %q", e.Action, e.From)
+}
+
+func (e *TransitionError) Unwrap() error {
+	return ErrInvalidTransition
+}
+
+// Guard ตรวจสอบเงื่อนไขก่อนเปลี่ยนสถานะ คืนค่า error เพื่อปฏิเสธ
+type Guard func(e *Event, now time.Time) error
+
+// Hook ทำงานหลังจากเปลี่ยนสถานะสำเร็จ
+type Hook func(ctx context.Context, e *Event, t EventTransition)
+
+type transitionRule struct {
+	from   []string
+	to     string
+	guards []Guard
+}
+
+// StateMachine enforces the legal status transitions of an Event
+type StateMachine struct {
+	rules map[string]*transitionRule
+	hooks []Hook
+	now   func() time.Time
+}
+
+// NewStateMachine creates the default event lifecycle:
+// draft -> published -> completed, and draft/published -> canceled
+func NewStateMachine() *StateMachine {
+	sm := &StateMachine{
+		rules: map[string]*transitionRule{
+			ActionPublish: {
+				from:   []string{EventStatusDraft},
+				to:     EventStatusPublished,
+				guards: []Guard{guardEndsInFuture},
+			},
+			ActionCancel: {
+				from: []string{EventStatusDraft, EventStatusPublished},
+				to:   EventStatusCanceled,
+			},
+			ActionComplete: {
+				from:   []string{EventStatusPublished},
+				to:     EventStatusCompleted,
+				guards: []Guard{guardHasStarted},
+			},
+		},
+		now: time.Now,
+	}
+	return sm
+}
+
+// AddGuard registers an additional guard for an action
+func (sm *StateMachine) AddGuard(action string, guard Guard) error {
+	rule, ok := sm.rules[action]
+	if !ok {
+		return fmt.Errorf("unknown action %q", action)
+	}
+	rule.guards = append(rule.guards, guard)
+	return nil
+}
+
+// OnTransition registers a hook that runs after every applied transition
+func (sm *StateMachine) OnTransition(hook Hook) {
+	sm.hooks = append(sm.hooks, hook)
+}
+
+// Apply checks and applies action to e, returning the transition record.
+// The event is left untouched when the transition is refused.
+func (sm *StateMachine) Apply(e *Event, action string) (EventTransition, error) {
+	return sm.apply(e, action, true)
+}
+
+// ApplyUnguarded applies action to e like Apply but skips the guards, for
+// events taken as they are from another calendar
+func (sm *StateMachine) ApplyUnguarded(e *Event, action string) (EventTransition, error) {
+	return sm.apply(e, action, false)
+}
+
+func (sm *StateMachine) apply(e *Event, action string, guarded bool) (EventTransition, error) {
+	rule, ok := sm.rules[action]
+	if !ok {
+		return EventTransition{}, fmt.Errorf("unknown action %q", action)
+	}
+
+	from := e.Status
+	if from == "" {
+		from = EventStatusDraft
+	}
+	if !contains(rule.from, from) {
+		return EventTransition{}, &TransitionError{Action: action, From: from}
+	}
+
+	now := sm.now()
+	for _, guard := range rule.guards {
+		if !guarded {
+			break
+		}
+		if err := guard(e, now); err != nil {
+			return EventTransition{}, &TransitionError{Action: action, From: from, Reason: err.Error()}
+		}
+	}
+
+	e.Status = rule.to
+	return EventTransition{
+		EventID:    e.ID,
+		Action:     action,
+		FromStatus: from,
+		ToStatus:   rule.to,
+		CreatedAt:  now,
+	}, nil
+}
+
+// ActionFor returns the action leading from one status to another
+func (sm *StateMachine) ActionFor(from, to string) (string, bool) {
+	for action, rule := range sm.rules {
+		if rule.to == to && contains(rule.from, from) {
+			return action, true
+		}
+	}
+	return "", false
+}
+
+// RunHooks calls every registered hook for an applied transition
+func (sm *StateMachine) RunHooks(ctx context.Context, e *Event, t EventTransition) {
+	for _, hook := range sm.hooks {
+		hook(ctx, e, t)
+	}
+}
+
+// guardEndsInFuture: publish ไม่ได้ถ้าวันสิ้นสุดผ่านไปแล้ว
+func guardEndsInFuture(e *Event, now time.Time) error {
+	if e.EndDate.IsZero() || !e.EndDate.After(now) {
+		return errors.New("end date must be in the future")
+	}
+	return nil
+}
+
+// guardHasStarted: complete ไม่ได้ถ้ายังไม่ถึงวันเริ่ม
+func guardHasStarted(e *Event, now time.Time) error {
+	if e.StartDate.After(now) {
+		return errors.New("event has not started yet")
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package event
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	// Embed the tz database so zones validate on hosts without zoneinfo
+	_ "time/tzdata"
+)
+
+var ErrInvalidTimeZone = errors.New("invalid time zone")
+
+// LoadTimeZone resolves an IANA zone name; an empty name means UTC.
+// "Local" is refused because it depends on the server's configuration.
+func LoadTimeZone(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+	if name == "Local" {
+		return nil, fmt.Errorf("%w %q", ErrInvalidTimeZone, name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q", ErrInvalidTimeZone, name)
+	}
+	return loc, nil
+}
+
+// Location returns the event's time zone, UTC when unset
+func (e *Event) Location() *time.Location {
+	loc, err := LoadTimeZone(e.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// In returns a copy of the event with its times expressed in loc, or in
+// the event's own zone when loc is nil
+func (e Event) In(loc *time.Location) Event {
+	if loc == nil {
+		loc = e.Location()
+	}
+	e.StartDate = e.StartDate.In(loc)
+	e.EndDate = e.EndDate.In(loc)
+	e.CreatedAt = e.CreatedAt.In(loc)
+	e.UpdatedAt = e.UpdatedAt.In(loc)
+	return e
+}
+
+// In returns a copy of the occurrence with its times expressed in loc, or
+// in the event's own zone when loc is nil
+func (o Occurrence) In(loc *time.Location) Occurrence {
+	if loc == nil {
+		var err error
+		if loc, err = LoadTimeZone(o.TimeZone); err != nil {
+			loc = time.UTC
+		}
+	}
+	o.RecurrenceID = o.RecurrenceID.In(loc)
+	o.StartDate = o.StartDate.In(loc)
+	o.EndDate = o.EndDate.In(loc)
+	return o
+}
@@ -2,10 +2,12 @@ package middleware
 
 import (
 	"context"
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
@@ -93,12 +95,21 @@ func WithValidation(validate *validator.Validate) Middleware {
 
 // Authentication middleware
 func WithAuthentication(secretKey string) Middleware {
+	// An empty key would accept tokens anyone can sign; a random one
+	// rejects them all
+	key := []byte(secretKey)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(err)
+		}
+	}
 	return func(next http.HandlerFunc) http.HandlerFunc {
 		return func(w http.ResponseWriter, r *http.Request) {
 			ctx, span := otel.Tracer("auth").Start(r.Context(), "authenticate")
 			defer span.End()
 
-			tokenString := r.Header.Get("Authorization")
+			tokenString := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
 			if tokenString == "" {
 				respondWithError(w, http.StatusUnauthorized, "Missing authorization token")
 				return
@@ -108,7 +119,7 @@ func WithAuthentication(secretKey string) Middleware {
 				if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 				}
-				return []byte(secretKey), nil
+				return key, nil
 			})
 
 			if err != nil {
@@ -127,6 +138,33 @@ func WithAuthentication(secretKey string) Middleware {
 	}
 }
 
+// WithOptionalAuthentication authenticates requests that carry a token, as
+// WithAuthentication does, and lets requests without one through
+func WithOptionalAuthentication(secretKey string) Middleware {
+	authenticate := WithAuthentication(secretKey)
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		withAuth := authenticate(next)
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				next(w, r)
+				return
+			}
+			withAuth(w, r)
+		}
+	}
+}
+
+// Subject returns the "sub" claim of the token WithAuthentication accepted,
+// or "" for unauthenticated requests
+func Subject(ctx context.Context) string {
+	claims, ok := ctx.Value(UserContextKey).(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	sub, _ := claims["sub"].(string)
+	return sub
+}
+
 // Authorization middleware
 func WithAuthorization(requiredRole string) Middleware {
 	return func(next http.HandlerFunc) http.HandlerFunc {
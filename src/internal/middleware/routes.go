@@ -1,24 +1,256 @@
 package middleware
 
 import (
-	// "apidesign/internal/contact"
+	"context"
+	"log"
+	"time"
+
+	"apidesign/config"
+
+	"apidesign/internal/contact"
 	"apidesign/internal/controllers"
 	"apidesign/internal/database"
+	"apidesign/internal/event"
+	"apidesign/internal/pagination"
+	"apidesign/internal/reminder"
+	"apidesign/internal/search"
 	"apidesign/internal/services"
 
 	"github.com/gorilla/mux"
 )
 
-func SetupRoutes(r *mux.Router, db database.Database) {
+// SetupRoutes registers every route and starts the background jobs that
+// need the services it builds; they stop when ctx is done
+func SetupRoutes(ctx context.Context, r *mux.Router, db database.Database, scheduler *reminder.Scheduler, cfg *config.Config) {
+	contactRepo := contact.NewContactRepo(db)
+	eventRepo := event.NewEventRepo(db)
+	attendeeRepo := event.NewAttendeeRepo(db)
+
+	contactCategoryService := services.NewContactCategoryService(contact.NewContactCategoryRepo(db), contactRepo)
+	contactTypeService := services.NewContactTypeService(contact.NewContactTypeRepo(db), contactRepo)
+	eventCategoryService := services.NewEventCategoryService(event.NewEventCategoryRepo(db), eventRepo)
+	tagService := services.NewTagService(contact.NewTagRepo(db), contactRepo)
+	contactService := services.NewContactService(contactRepo, contactCategoryService, contactTypeService, tagService)
+	contactService.OnMerge(tagService.ReassignContacts)
+	contactService.OnDelete(tagService.RemoveContact)
+	if err := contactService.SetDefaultRegion(cfg.Phone.DefaultRegion); err != nil {
+		log.Fatalf("Phone: %v", err)
+	}
+	revisionService := services.NewRevisionService(contact.NewRevisionRepo(db), contactService, Subject)
+	contactService.OnSave(revisionService.Record)
+	contactService.OnMerge(revisionService.RemoveMerged)
+	contactService.OnDelete(revisionService.RemoveContact)
+
+	if cfg.JWTSecret == "" {
+		log.Printf("Auth: jwt_secret is not set; every token will be rejected")
+	}
+	// Changes made with a token are attributed to its subject in the history
+	actor := WithOptionalAuthentication(cfg.JWTSecret)
+
+	// Cursors of every list are signed with the same key
+	cursors := pagination.NewCodec(cfg.Pagination.CursorSecret)
+
 	contactController := &controllers.ContactController{
-		Service: &services.ContactService{
-			// Repo: &contact.ContactRepo{DB: db},
-		},
+		Service: contactService,
+		Cursors: cursors,
 	}
 
 	// CRUD routes for contacts
-	r.HandleFunc("/contacts", contactController.CreateContact).Methods("POST")        // Create
-	r.HandleFunc("/contacts/{id}", contactController.GetContact).Methods("GET")       // Read
-	r.HandleFunc("/contacts/{id}", contactController.UpdateContact).Methods("PUT")    // Update
-	r.HandleFunc("/contacts/{id}", contactController.DeleteContact).Methods("DELETE") // Delete
+	r.HandleFunc("/contacts", actor(contactController.CreateContact)).Methods("POST")            // Create
+	r.HandleFunc("/contacts", contactController.ListContacts).Methods("GET")                     // List
+	r.HandleFunc("/contacts/{id:[0-9]+}", contactController.GetContact).Methods("GET")           // Read
+	r.HandleFunc("/contacts/{id:[0-9]+}", actor(contactController.UpdateContact)).Methods("PUT") // Update
+	r.HandleFunc("/contacts/{id:[0-9]+}", contactController.DeleteContact).Methods("DELETE")     // Delete
+
+	revisionController := &controllers.RevisionController{
+		Service: revisionService,
+	}
+
+	// Revision history with diffs and rollback
+	r.HandleFunc("/contacts/{id:[0-9]+}/revisions", revisionController.ListRevisions).Methods("GET")
+	r.HandleFunc("/contacts/{id:[0-9]+}/revisions/diff", revisionController.DiffRevisions).Methods("GET")
+	r.HandleFunc("/contacts/{id:[0-9]+}/revisions/{rev:[0-9]+}", revisionController.GetRevision).Methods("GET")
+	r.HandleFunc("/contacts/{id:[0-9]+}/revisions/{rev:[0-9]+}/restore", actor(revisionController.RestoreRevision)).Methods("POST")
+
+	// Trash: deleted contacts can be restored until the purger removes them
+	r.HandleFunc("/contacts/trash", contactController.ListTrash).Methods("GET")
+	r.HandleFunc("/contacts/{id:[0-9]+}/restore", contactController.RestoreContact).Methods("POST")
+
+	// Duplicate detection and merge
+	r.HandleFunc("/contacts/duplicates", contactController.FindDuplicates).Methods("GET")
+	r.HandleFunc("/contacts/merge", actor(contactController.MergeContacts)).Methods("POST")
+
+	// vCard and CSV import
+	r.HandleFunc("/contacts/import", actor(contactController.ImportContacts)).Methods("POST")
+	r.HandleFunc("/contacts/import/csv", actor(contactController.ImportContactsCSV)).Methods("POST")
+
+	phoneController := &controllers.PhoneController{
+		DefaultRegion: cfg.Phone.DefaultRegion,
+	}
+
+	// Phone number parsing with the embedded numbering plans
+	r.HandleFunc("/phone-numbers/parse", phoneController.ParseNumber).Methods("GET")
+	r.HandleFunc("/phone-numbers/regions", phoneController.ListRegions).Methods("GET")
+
+	tagController := &controllers.TagController{
+		Service: tagService,
+	}
+
+	// Curated tags and usage counts; free-form tags appear when assigned
+	r.HandleFunc("/tags", tagController.CreateTag).Methods("POST")
+	r.HandleFunc("/tags", tagController.ListTags).Methods("GET")
+	r.HandleFunc("/tags/{id:[0-9]+}", tagController.GetTag).Methods("GET")
+	r.HandleFunc("/tags/{id:[0-9]+}", tagController.UpdateTag).Methods("PUT")
+	r.HandleFunc("/tags/{id:[0-9]+}", tagController.DeleteTag).Methods("DELETE")
+	r.HandleFunc("/contacts/{id:[0-9]+}/tags", tagController.GetContactTags).Methods("GET")
+	r.HandleFunc("/contacts/{id:[0-9]+}/tags", tagController.SetContactTags).Methods("PUT")
+
+	eventService := services.NewEventService(eventRepo, eventCategoryService, attendeeRepo)
+	// Publishing starts reminders, canceling or completing stops them
+	eventService.States().OnTransition(func(ctx context.Context, e *event.Event, t event.EventTransition) {
+		if err := scheduler.Schedule(ctx, e.ID); err != nil {
+			log.Printf("Reminders: scheduling event %d: %v", e.ID, err)
+		}
+	})
+	eventController := &controllers.EventController{
+		Service: eventService,
+		Cursors: cursors,
+	}
+
+	// CRUD routes for events
+	r.HandleFunc("/events", eventController.CreateEvent).Methods("POST")               // Create
+	r.HandleFunc("/events", eventController.ListEvents).Methods("GET")                 // List
+	r.HandleFunc("/events/{id:[0-9]+}", eventController.GetEvent).Methods("GET")       // Read
+	r.HandleFunc("/events/{id:[0-9]+}", eventController.UpdateEvent).Methods("PUT")    // Update
+	r.HandleFunc("/events/{id:[0-9]+}", eventController.DeleteEvent).Methods("DELETE") // Delete
+
+	// iCalendar import and export
+	r.HandleFunc("/events/{id:[0-9]+}.ics", eventController.GetEventICS).Methods("GET")
+	r.HandleFunc("/events/import", eventController.ImportEvents).Methods("POST")
+
+	// Lifecycle transitions for events
+	r.HandleFunc("/events/{id:[0-9]+}/publish", eventController.PublishEvent).Methods("POST")
+	r.HandleFunc("/events/{id:[0-9]+}/cancel", eventController.CancelEvent).Methods("POST")
+	r.HandleFunc("/events/{id:[0-9]+}/complete", eventController.CompleteEvent).Methods("POST")
+	r.HandleFunc("/events/{id:[0-9]+}/transitions", eventController.ListTransitions).Methods("GET")
+
+	// Recurring event occurrences
+	r.HandleFunc("/events/occurrences", eventController.ListAllOccurrences).Methods("GET")
+	r.HandleFunc("/events/{id:[0-9]+}/occurrences", eventController.ListOccurrences).Methods("GET")
+	r.HandleFunc("/events/{id:[0-9]+}/occurrences/{recurrenceId}", eventController.UpdateOccurrence).Methods("PUT")
+	r.HandleFunc("/events/{id:[0-9]+}/occurrences/{recurrenceId}", eventController.CancelOccurrence).Methods("DELETE")
+
+	reminderController := &controllers.ReminderController{
+		Service: services.NewReminderService(reminder.NewRepo(db), eventRepo, scheduler),
+	}
+
+	// Reminders before event occurrences
+	r.HandleFunc("/events/{id:[0-9]+}/reminders", reminderController.ListReminders).Methods("GET")
+	r.HandleFunc("/events/{id:[0-9]+}/reminders", reminderController.AddReminder).Methods("POST")
+	r.HandleFunc("/events/{id:[0-9]+}/reminders/jobs", reminderController.ListJobs).Methods("GET")
+	r.HandleFunc("/events/{id:[0-9]+}/reminders/{reminderId:[0-9]+}", reminderController.RemoveReminder).Methods("DELETE")
+
+	// Calendar views and free/busy
+	r.HandleFunc("/events/calendar", eventController.Calendar).Methods("GET")
+	r.HandleFunc("/freebusy", eventController.FreeBusy).Methods("POST")
+
+	attendeeService := services.NewAttendeeService(attendeeRepo, eventRepo, contactRepo)
+	attendeeService.OnPromoted(func(ctx context.Context, e event.Event, a event.Attendee) {
+		log.Printf("Waitlist: contact %d promoted to a seat at event %d", a.ContactID, e.ID)
+	})
+	contactService.OnMerge(attendeeService.ReassignContacts)
+	attendeeController := &controllers.AttendeeController{
+		Service: attendeeService,
+	}
+
+	// Attendees link contacts to events
+	r.HandleFunc("/events/{id:[0-9]+}/attendees", attendeeController.ListAttendees).Methods("GET")
+	r.HandleFunc("/events/{id:[0-9]+}/attendees", attendeeController.AddAttendee).Methods("POST")
+	r.HandleFunc("/events/{id:[0-9]+}/attendees/{contactId:[0-9]+}", attendeeController.UpdateAttendee).Methods("PUT")
+	r.HandleFunc("/events/{id:[0-9]+}/attendees/{contactId:[0-9]+}", attendeeController.RemoveAttendee).Methods("DELETE")
+	r.HandleFunc("/events/{id:[0-9]+}/attendees/{contactId:[0-9]+}/checkin", attendeeController.CheckIn).Methods("POST")
+	r.HandleFunc("/events/{id:[0-9]+}/waitlist", attendeeController.ListWaitlist).Methods("GET")
+	r.HandleFunc("/contacts/{id:[0-9]+}/events", attendeeController.ListContactEvents).Methods("GET")
+
+	timelineService := services.NewTimelineService(contact.NewTimelineRepo(db), contactRepo, eventRepo)
+	contactService.OnMerge(timelineService.ReassignContacts)
+	contactService.OnDelete(timelineService.RemoveContact)
+
+	// The search index is built before the server takes requests, so no
+	// change can race the rebuild; afterwards it follows every change to
+	// contacts, their tags and notes
+	searchService := services.NewSearchService(search.NewMemoryIndex(nil), contactRepo,
+		contact.NewTagRepo(db), contact.NewTimelineRepo(db), contactService.PhoneRegion)
+	if err := searchService.Rebuild(ctx); err != nil {
+		log.Printf("Search: %v", err)
+	}
+	contactService.OnChange(searchService.Reindex)
+	tagService.OnChange(searchService.Reindex)
+	timelineService.OnChange(searchService.Reindex)
+	searchController := &controllers.SearchController{
+		Service: searchService,
+	}
+
+	// Full-text search across names, emails, phones, tags and notes
+	r.HandleFunc("/contacts/search", searchController.SearchContacts).Methods("GET")
+
+	// Every delete hook is registered, so purging removes all related records
+	purger := services.NewTrashPurger(contactService,
+		time.Duration(cfg.Trash.RetentionDays)*24*time.Hour,
+		time.Duration(cfg.Trash.PurgeIntervalSeconds)*time.Second)
+	go purger.Start(ctx)
+
+	timelineController := &controllers.TimelineController{
+		Service: timelineService,
+		Subject: Subject,
+	}
+
+	// Timeline entries are authored by the JWT subject
+	auth := WithAuthentication(cfg.JWTSecret)
+	r.HandleFunc("/contacts/{id:[0-9]+}/timeline", auth(timelineController.GetTimeline)).Methods("GET")
+	r.HandleFunc("/contacts/{id:[0-9]+}/timeline", auth(timelineController.AddEntry)).Methods("POST")
+	r.HandleFunc("/contacts/{id:[0-9]+}/timeline/{entryId:[0-9]+}", auth(timelineController.GetEntry)).Methods("GET")
+	r.HandleFunc("/contacts/{id:[0-9]+}/timeline/{entryId:[0-9]+}", auth(timelineController.UpdateEntry)).Methods("PUT")
+	r.HandleFunc("/contacts/{id:[0-9]+}/timeline/{entryId:[0-9]+}", auth(timelineController.DeleteEntry)).Methods("DELETE")
+
+	contactCategoryController := &controllers.ContactCategoryController{
+		Service:  contactCategoryService,
+		Contacts: contactService,
+	}
+
+	// Contact category tree
+	r.HandleFunc("/contact-categories", contactCategoryController.CreateCategory).Methods("POST")
+	r.HandleFunc("/contact-categories", contactCategoryController.ListCategories).Methods("GET")
+	r.HandleFunc("/contact-categories/{id:[0-9]+}", contactCategoryController.GetCategory).Methods("GET")
+	r.HandleFunc("/contact-categories/{id:[0-9]+}", contactCategoryController.UpdateCategory).Methods("PUT")
+	r.HandleFunc("/contact-categories/{id:[0-9]+}", contactCategoryController.DeleteCategory).Methods("DELETE")
+	r.HandleFunc("/contact-categories/{id:[0-9]+}/tree", contactCategoryController.GetTree).Methods("GET")
+	r.HandleFunc("/contact-categories/{id:[0-9]+}/descendants", contactCategoryController.GetDescendants).Methods("GET")
+	r.HandleFunc("/contact-categories/{id:[0-9]+}/ancestors", contactCategoryController.GetAncestors).Methods("GET")
+	r.HandleFunc("/contact-categories/{id:[0-9]+}/contacts", contactCategoryController.ListContacts).Methods("GET")
+
+	contactTypeController := &controllers.ContactTypeController{
+		Service: contactTypeService,
+	}
+
+	// Contact types
+	r.HandleFunc("/contact-types", contactTypeController.CreateContactType).Methods("POST")
+	r.HandleFunc("/contact-types", contactTypeController.ListContactTypes).Methods("GET")
+	r.HandleFunc("/contact-types/{id:[0-9]+}", contactTypeController.GetContactType).Methods("GET")
+	r.HandleFunc("/contact-types/{id:[0-9]+}", contactTypeController.UpdateContactType).Methods("PUT")
+	r.HandleFunc("/contact-types/{id:[0-9]+}", contactTypeController.DeleteContactType).Methods("DELETE")
+
+	eventCategoryController := &controllers.EventCategoryController{
+		Service: eventCategoryService,
+	}
+
+	// Event category tree
+	r.HandleFunc("/event-categories", eventCategoryController.CreateCategory).Methods("POST")
+	r.HandleFunc("/event-categories", eventCategoryController.ListCategories).Methods("GET")
+	r.HandleFunc("/event-categories/{id:[0-9]+}", eventCategoryController.GetCategory).Methods("GET")
+	r.HandleFunc("/event-categories/{id:[0-9]+}", eventCategoryController.UpdateCategory).Methods("PUT")
+	r.HandleFunc("/event-categories/{id:[0-9]+}", eventCategoryController.DeleteCategory).Methods("DELETE")
+	r.HandleFunc("/event-categories/{id:[0-9]+}/tree", eventCategoryController.GetTree).Methods("GET")
+	r.HandleFunc("/event-categories/{id:[0-9]+}/descendants", eventCategoryController.GetDescendants).Methods("GET")
+	r.HandleFunc("/event-categories/{id:[0-9]+}/ancestors", eventCategoryController.GetAncestors).Methods("GET")
 }
@@ -7,4 +7,6 @@ type BaseModel struct {
 	ID        int       `json:"id" db:"id"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	// DeletedAt is set while the row is soft deleted; normal reads skip it
+	DeletedAt *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
 }
@@ -0,0 +1,72 @@
+package models
+
+// TreeNode is the minimal view of a row that points at its parent (parent_id)
+type TreeNode struct {
+	ID       int
+	ParentID int // 0 for root nodes
+}
+
+// Children groups node IDs by their parent ID
+func Children(nodes []TreeNode) map[int][]int {
+	children := make(map[int][]int)
+	for _, n := range nodes {
+		children[n.ParentID] = append(children[n.ParentID], n.ID)
+	}
+	return children
+}
+
+// Descendants returns the IDs below id, breadth first
+func Descendants(nodes []TreeNode, id int) []int {
+	children := Children(nodes)
+	visited := map[int]bool{id: true}
+
+	var result []int
+	queue := []int{id}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range children[current] {
+			// Guard against cycles in existing data
+			if visited[child] {
+				continue
+			}
+			visited[child] = true
+			result = append(result, child)
+			queue = append(queue, child)
+		}
+	}
+	return result
+}
+
+// Ancestors returns the IDs above id, nearest parent first
+func Ancestors(nodes []TreeNode, id int) []int {
+	parents := make(map[int]int, len(nodes))
+	for _, n := range nodes {
+		parents[n.ID] = n.ParentID
+	}
+
+	visited := map[int]bool{id: true}
+	var result []int
+	for parent := parents[id]; parent != 0 && !visited[parent]; parent = parents[parent] {
+		visited[parent] = true
+		result = append(result, parent)
+	}
+	return result
+}
+
+// CreatesCycle reports whether making parentID the parent of id would
+// create a cycle, i.e. whether parentID is id itself or one of its descendants
+func CreatesCycle(nodes []TreeNode, id int, parentID int) bool {
+	if parentID == 0 || id == 0 {
+		return false
+	}
+	if parentID == id {
+		return true
+	}
+	for _, ancestor := range Ancestors(nodes, parentID) {
+		if ancestor == id {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,158 @@
+// Package pagination turns positions in sorted lists into opaque cursors.
+// Cursors are signed so clients cannot forge them into arbitrary queries,
+// and carry their sort so they are not reused with another order.
+package pagination
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"apidesign/internal/database"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Codec signs and verifies cursors
+type Codec struct {
+	key []byte
+}
+
+// NewCodec creates a codec signing with secret. Without a secret a random
+// key is used, so cursors do not survive a restart.
+func NewCodec(secret string) *Codec {
+	key := []byte(secret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(err)
+		}
+	}
+	return &Codec{key: key}
+}
+
+// token is the signed content of a cursor
+type token struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+	Before bool     `json:"b,omitempty"`
+}
+
+// Encode returns the cursor of a position in a list in the given order
+func (c *Codec) Encode(sort []database.SortField, cursor database.Cursor) (string, error) {
+	t := token{Sort: sortKey(sort), Before: cursor.Before}
+	for _, v := range cursor.Values {
+		encoded, err := encodeValue(v)
+		if err != nil {
+			return "", err
+		}
+		t.Values = append(t.Values, encoded)
+	}
+	payload, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(payload) + "." +
+		base64.RawURLEncoding.EncodeToString(c.sign(payload)), nil
+}
+
+// Decode verifies a cursor made by Encode for the same order. An empty
+// cursor decodes to nil.
+func (c *Codec) Decode(cursor string, sort []database.SortField) (*database.Cursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	encoded, signature, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, c.sign(payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var t token
+	if err := json.Unmarshal(payload, &t); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if t.Sort != sortKey(sort) {
+		return nil, fmt.Errorf("%w: it was made for another sort", ErrInvalidCursor)
+	}
+	result := &database.Cursor{Before: t.Before}
+	for _, v := range t.Values {
+		value, err := decodeValue(v)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		result.Values = append(result.Values, value)
+	}
+	return result, nil
+}
+
+func (c *Codec) sign(payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
+
+// sortKey writes the stable sort in ?sort= form
+func sortKey(sort []database.SortField) string {
+	parts := make([]string, 0, len(sort)+1)
+	for _, f := range database.StableSort(sort) {
+		if f.Desc {
+			parts = append(parts, "-"+f.Field)
+		} else {
+			parts = append(parts, f.Field)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// Values keep their type, prefixed with a letter, so they compare the same
+// way in the next query: times must stay times for Mongo
+func encodeValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return "s" + v, nil
+	case int64:
+		return "i" + strconv.FormatInt(v, 10), nil
+	case float64:
+		return "f" + strconv.FormatFloat(v, 'g', -1, 64), nil
+	case bool:
+		return "b" + strconv.FormatBool(v), nil
+	case time.Time:
+		return "t" + v.UTC().Format(time.RFC3339Nano), nil
+	}
+	return "", fmt.Errorf("cannot page by a %T", v)
+}
+
+func decodeValue(s string) (interface{}, error) {
+	if s == "" {
+		return nil, ErrInvalidCursor
+	}
+	kind, value := s[0], s[1:]
+	switch kind {
+	case 's':
+		return value, nil
+	case 'i':
+		return strconv.ParseInt(value, 10, 64)
+	case 'f':
+		return strconv.ParseFloat(value, 64)
+	case 'b':
+		return strconv.ParseBool(value)
+	case 't':
+		return time.Parse(time.RFC3339Nano, value)
+	}
+	return nil, ErrInvalidCursor
+}
@@ -0,0 +1,101 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"apidesign/internal/database"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	codec := NewCodec("secret")
+	sort := []database.SortField{{Field: "created_at", Desc: true}, {Field: "last_name"}}
+	at := time.Date(2024, 5, 1, 9, 30, 0, 123456789, time.UTC)
+
+	tests := []struct {
+		name   string
+		cursor database.Cursor
+	}{
+		{name: "after", cursor: database.Cursor{Values: []interface{}{at, "Doe", int64(42)}}},
+		{name: "before", cursor: database.Cursor{Values: []interface{}{at, "Doe", int64(42)}, Before: true}},
+		{name: "other types", cursor: database.Cursor{Values: []interface{}{1.5, true, int64(-1)}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := codec.Encode(sort, tt.cursor)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			decoded, err := codec.Decode(encoded, sort)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !reflect.DeepEqual(*decoded, tt.cursor) {
+				t.Errorf("Decode() = %#v, want %#v", *decoded, tt.cursor)
+			}
+		})
+	}
+}
+
+func TestCodecDecodeRejects(t *testing.T) {
+	codec := NewCodec("secret")
+	sort := []database.SortField{{Field: "last_name"}}
+	valid, err := codec.Encode(sort, database.Cursor{Values: []interface{}{"Doe", int64(7)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, signature, _ := strings.Cut(valid, ".")
+
+	// The same signature over another position
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"s":"last_name,id","v":["sZed","i1"]}`)) + "." + signature
+	// A flipped bit in the signature
+	mac, _ := base64.RawURLEncoding.DecodeString(signature)
+	mac[0] ^= 1
+	tampered := payload + "." + base64.RawURLEncoding.EncodeToString(mac)
+	otherKey, err := NewCodec("other").Encode(sort, database.Cursor{Values: []interface{}{"Doe", int64(7)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   []database.SortField
+	}{
+		{name: "tampered signature", cursor: tampered, sort: sort},
+		{name: "tampered payload", cursor: forged, sort: sort},
+		{name: "other key", cursor: otherKey, sort: sort},
+		{name: "wrong sort", cursor: valid, sort: []database.SortField{{Field: "last_name", Desc: true}}},
+		{name: "no signature", cursor: payload, sort: sort},
+		{name: "not base64", cursor: "!!!.!!!", sort: sort},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := codec.Decode(tt.cursor, tt.sort); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode() error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestCodecDecodeEmpty(t *testing.T) {
+	cursor, err := NewCodec("secret").Decode("", nil)
+	if cursor != nil || err != nil {
+		t.Errorf("Decode(\"\") = %v, %v, want nil, nil", cursor, err)
+	}
+}
+
+func TestCodecRandomKey(t *testing.T) {
+	sort := []database.SortField{{Field: "last_name"}}
+	encoded, err := NewCodec("").Encode(sort, database.Cursor{Values: []interface{}{"Doe", int64(7)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCodec("").Decode(encoded, sort); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("cursor of another random key decoded, error = %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package pagination
+
+import (
+	"errors"
+
+	"apidesign/internal/database"
+)
+
+// Page describes a page read from a sorted list
+type Page struct {
+	// Sort is the order the page was read in
+	Sort []database.SortField
+	// Cursor is the cursor the page was read from, nil for an offset page
+	Cursor *database.Cursor
+	Offset int64
+	// First and Last are the first and last rows of the page, nil when empty
+	First, Last interface{}
+	// More reports rows past the page in the direction it was read
+	More bool
+}
+
+// Links returns the cursors of the next and previous pages, empty when
+// there is no such page. A page ending on a row with a null sort value has
+// no cursor on that side; such lists are paged by offset.
+func (c *Codec) Links(p Page) (next, prev string, err error) {
+	if p.First == nil {
+		return "", "", nil
+	}
+	backwards := p.Cursor != nil && p.Cursor.Before
+	hasNext := p.More || backwards
+	hasPrev := (p.More && backwards) || (!backwards && (p.Cursor != nil || p.Offset > 0))
+
+	if hasNext {
+		if next, err = c.cursorAt(p.Sort, p.Last, false); err != nil {
+			return "", "", err
+		}
+	}
+	if hasPrev {
+		if prev, err = c.cursorAt(p.Sort, p.First, true); err != nil {
+			return "", "", err
+		}
+	}
+	return next, prev, nil
+}
+
+// cursorAt returns the cursor that continues after (or before) row
+func (c *Codec) cursorAt(sort []database.SortField, row interface{}, before bool) (string, error) {
+	values, err := database.SortKey(row, sort)
+	if errors.Is(err, database.ErrNullSortValue) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return c.Encode(sort, database.Cursor{Values: values, Before: before})
+}
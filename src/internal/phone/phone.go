@@ -0,0 +1,228 @@
+// Package phone parses and formats phone numbers using the numbering plans
+// embedded in metadata.json. It needs no network access.
+package phone
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Number types
+const (
+	TypeMobile            = "mobile"
+	TypeLandline          = "landline"
+	TypeFixedLineOrMobile = "fixed_line_or_mobile"
+	TypeTollFree          = "toll_free"
+)
+
+var (
+	ErrInvalidNumber = errors.New("invalid phone number")
+	ErrUnknownRegion = errors.New("unknown phone region")
+	// ErrUnknownCountryCode is returned for international numbers of
+	// countries without metadata; they may still be valid E.164 numbers
+	ErrUnknownCountryCode = errors.New("no metadata for country code")
+)
+
+// Number is a parsed phone number
+type Number struct {
+	E164           string `json:"e164"`
+	Region         string `json:"region"`
+	CountryCode    int    `json:"country_code"`
+	NationalNumber string `json:"national_number"`
+	Type           string `json:"type"`
+}
+
+// region is the numbering plan of a country
+type region struct {
+	CountryCode         int    `json:"country_code"`
+	NationalPrefix      string `json:"national_prefix"`
+	InternationalPrefix string `json:"international_prefix"`
+	// Main marks the region that owns a country code shared by several
+	Main  bool   `json:"main"`
+	Rules []rule `json:"rules"`
+}
+
+// rule matches national numbers of one type by leading digits and length
+type rule struct {
+	Type     string   `json:"type"`
+	Prefixes []string `json:"prefixes"`
+	Lengths  []int    `json:"lengths"`
+}
+
+//go:embed metadata.json
+var metadataJSON []byte
+
+var (
+	regions      map[string]region
+	countryCodes map[int][]string // country code → regions, main region first
+)
+
+func init() {
+	if err := json.Unmarshal(metadataJSON, &regions); err != nil {
+		panic(fmt.Sprintf("phone: bad metadata: %v", err))
+	}
+	countryCodes = make(map[int][]string)
+	for code, r := range regions {
+		countryCodes[r.CountryCode] = append(countryCodes[r.CountryCode], code)
+	}
+	for _, codes := range countryCodes {
+		sort.Slice(codes, func(i, j int) bool {
+			if regions[codes[i]].Main != regions[codes[j]].Main {
+				return regions[codes[i]].Main
+			}
+			return codes[i] < codes[j]
+		})
+	}
+}
+
+// IsSupportedRegion reports whether code (ISO 3166-1 alpha-2) has metadata
+func IsSupportedRegion(code string) bool {
+	_, ok := regions[strings.ToUpper(code)]
+	return ok
+}
+
+// Regions returns the supported region codes, sorted
+func Regions() []string {
+	codes := make([]string, 0, len(regions))
+	for code := range regions {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Parse reads a number written in international form (+66 81 234 5678,
+// 00 66 81 234 5678) or in the national form of defaultRegion (081-234-5678).
+// defaultRegion may be empty when only international numbers are expected.
+func Parse(raw, defaultRegion string) (Number, error) {
+	defaultRegion = strings.ToUpper(strings.TrimSpace(defaultRegion))
+	var home *region
+	if defaultRegion != "" {
+		r, ok := regions[defaultRegion]
+		if !ok {
+			return Number{}, fmt.Errorf("%w %q", ErrUnknownRegion, defaultRegion)
+		}
+		home = &r
+	}
+
+	raw = strings.TrimSpace(raw)
+	plus := strings.HasPrefix(raw, "+")
+	digits := digitsOf(raw)
+	if digits == "" {
+		return Number{}, fmt.Errorf("%w: %q has no digits", ErrInvalidNumber, raw)
+	}
+
+	switch {
+	case plus:
+		return parseInternational(raw, digits)
+	case home != nil && home.InternationalPrefix != "" && strings.HasPrefix(digits, home.InternationalPrefix):
+		return parseInternational(raw, digits[len(home.InternationalPrefix):])
+	case strings.HasPrefix(digits, "00"):
+		return parseInternational(raw, digits[2:])
+	case home == nil:
+		return Number{}, fmt.Errorf("%w: %q needs a country code or a region", ErrInvalidNumber, raw)
+	}
+	if n, ok := match(defaultRegion, digits); ok {
+		return n, nil
+	}
+	return Number{}, fmt.Errorf("%w: %q is not a %s number", ErrInvalidNumber, raw, defaultRegion)
+}
+
+// Normalize returns the E.164 form of raw, see Parse
+func Normalize(raw, defaultRegion string) (string, error) {
+	n, err := Parse(raw, defaultRegion)
+	return n.E164, err
+}
+
+// International formats the number as +CC NATIONAL
+func (n Number) International() string {
+	return "+" + strconv.Itoa(n.CountryCode) + " " + n.NationalNumber
+}
+
+// National formats the number as dialled inside its region
+func (n Number) National() string {
+	return regions[n.Region].NationalPrefix + n.NationalNumber
+}
+
+// parseInternational splits digits into a country code and national number
+func parseInternational(raw, digits string) (Number, error) {
+	for size := 1; size <= 3 && size < len(digits); size++ {
+		cc, _ := strconv.Atoi(digits[:size])
+		codes, ok := countryCodes[cc]
+		if !ok {
+			continue
+		}
+		national := digits[size:]
+		// Regions that share a code are told apart by their rules; the
+		// main region is the fallback
+		for i := len(codes) - 1; i >= 0; i-- {
+			if n, ok := match(codes[i], national); ok {
+				return n, nil
+			}
+		}
+		return Number{}, fmt.Errorf("%w: %q is not a valid +%d number", ErrInvalidNumber, raw, cc)
+	}
+	return Number{}, fmt.Errorf("%w: %q", ErrUnknownCountryCode, raw)
+}
+
+// match classifies a national number of the region, with or without the
+// national prefix, e.g. 0812345678 and 812345678 in TH
+func match(code, national string) (Number, bool) {
+	r := regions[code]
+	if r.NationalPrefix != "" && strings.HasPrefix(national, r.NationalPrefix) {
+		if n, ok := r.classify(code, national[len(r.NationalPrefix):]); ok {
+			return n, true
+		}
+	}
+	return r.classify(code, national)
+}
+
+// classify finds the rule with the longest matching prefix that allows the
+// number's length
+func (r region) classify(code, national string) (Number, bool) {
+	best, bestLen := "", 0
+	for _, rule := range r.Rules {
+		if !containsInt(rule.Lengths, len(national)) {
+			continue
+		}
+		for _, prefix := range rule.Prefixes {
+			if len(prefix) > bestLen && strings.HasPrefix(national, prefix) {
+				best, bestLen = rule.Type, len(prefix)
+			}
+		}
+	}
+	if bestLen == 0 {
+		return Number{}, false
+	}
+	return Number{
+		E164:           "+" + strconv.Itoa(r.CountryCode) + national,
+		Region:         code,
+		CountryCode:    r.CountryCode,
+		NationalNumber: national,
+		Type:           best,
+	}, true
+}
+
+func digitsOf(s string) string {
+	var b strings.Builder
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
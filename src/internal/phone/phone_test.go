@@ -0,0 +1,66 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name          string
+		raw           string
+		defaultRegion string
+		e164          string
+		region        string
+		numberType    string
+		err           error
+	}{
+		{name: "national mobile", raw: "081-234-5678", defaultRegion: "TH", e164: "+66812345678", region: "TH", numberType: TypeMobile},
+		{name: "national landline", raw: "02 123 4567", defaultRegion: "th", e164: "+6621234567", region: "TH", numberType: TypeLandline},
+		{name: "national without prefix", raw: "812345678", defaultRegion: "TH", e164: "+66812345678", region: "TH", numberType: TypeMobile},
+		{name: "plus country code", raw: "+66 81 234 5678", e164: "+66812345678", region: "TH", numberType: TypeMobile},
+		{name: "plus ignores region", raw: "+44 7700 900123", defaultRegion: "TH", e164: "+447700900123", region: "GB", numberType: TypeMobile},
+		{name: "00 prefix", raw: "00 66 81 234 5678", e164: "+66812345678", region: "TH", numberType: TypeMobile},
+		{name: "IDD of region", raw: "001 66 81 234 5678", defaultRegion: "TH", e164: "+66812345678", region: "TH", numberType: TypeMobile},
+		{name: "IDD of other region", raw: "011 44 7700 900123", defaultRegion: "US", e164: "+447700900123", region: "GB", numberType: TypeMobile},
+		{name: "shared code US", raw: "+1 212 555 0123", e164: "+12125550123", region: "US", numberType: TypeFixedLineOrMobile},
+		{name: "shared code CA", raw: "+1 416 555 0123", e164: "+14165550123", region: "CA", numberType: TypeFixedLineOrMobile},
+		{name: "shared code toll free", raw: "+1 800 555 0199", e164: "+18005550199", region: "US", numberType: TypeTollFree},
+		{name: "national US with trunk prefix", raw: "1 (212) 555-0123", defaultRegion: "US", e164: "+12125550123", region: "US", numberType: TypeFixedLineOrMobile},
+		{name: "unknown region", raw: "081-234-5678", defaultRegion: "XX", err: ErrUnknownRegion},
+		{name: "national without region", raw: "081-234-5678", err: ErrInvalidNumber},
+		{name: "no digits", raw: "n/a", defaultRegion: "TH", err: ErrInvalidNumber},
+		{name: "wrong length", raw: "081-234-567", defaultRegion: "TH", err: ErrInvalidNumber},
+		{name: "invalid for country code", raw: "+66 12", err: ErrInvalidNumber},
+		{name: "country code without metadata", raw: "+998 90 123 4567", err: ErrUnknownCountryCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, err := Parse(tt.raw, tt.defaultRegion)
+			if tt.err != nil {
+				if !errors.Is(err, tt.err) {
+					t.Fatalf("Parse(%q, %q) error = %v, want %v", tt.raw, tt.defaultRegion, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Parse(%q, %q) error = %v", tt.raw, tt.defaultRegion, err)
+			}
+			if n.E164 != tt.e164 || n.Region != tt.region || n.Type != tt.numberType {
+				t.Errorf("Parse(%q, %q) = %s %s %s, want %s %s %s", tt.raw, tt.defaultRegion,
+					n.E164, n.Region, n.Type, tt.e164, tt.region, tt.numberType)
+			}
+		})
+	}
+}
+
+func TestNational(t *testing.T) {
+	n, err := Parse("+66812345678", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := n.National(); got != "0812345678" {
+		t.Errorf("National() = %q, want %q", got, "0812345678")
+	}
+}
@@ -0,0 +1,61 @@
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Notifier delivers fired reminders. Returning an error makes the scheduler
+// retry the job later.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier writes reminders to a logger
+type LogNotifier struct {
+	Logger *log.Logger // nil uses the standard logger
+}
+
+// Notify implements Notifier
+func (n *LogNotifier) Notify(ctx context.Context, notification Notification) error {
+	logf := log.Printf
+	if n.Logger != nil {
+		logf = n.Logger.Printf
+	}
+	logf("Reminder: event %d %q starts at %s (%d minutes)",
+		notification.Event.ID, notification.Event.Title,
+		notification.OccurrenceStart.In(notification.Event.Location()).Format(time.RFC3339),
+		notification.MinutesBefore)
+	return nil
+}
+
+// FileNotifier appends reminders to a file as JSON lines
+type FileNotifier struct {
+	Path string
+	mu   sync.Mutex
+}
+
+// NewFileNotifier creates a FileNotifier writing to path
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{Path: path}
+}
+
+// Notify implements Notifier
+func (n *FileNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	file, err := os.OpenFile(n.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(file).Encode(notification); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
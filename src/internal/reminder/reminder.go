@@ -0,0 +1,76 @@
+package reminder
+
+import (
+	"fmt"
+	"time"
+
+	"apidesign/internal/event"
+)
+
+// Job status constants
+const (
+	JobPending  = "pending"
+	JobSent     = "sent"
+	JobFailed   = "failed"
+	JobCanceled = "canceled"
+
+	// JobExpired is set when the occurrence started before the job could fire
+	JobExpired = "expired"
+)
+
+// MaxMinutesBefore bounds how early a reminder may fire (four weeks)
+const MaxMinutesBefore = 4 * 7 * 24 * 60
+
+// Rule represents the event_reminders table: remind MinutesBefore the start
+// of every occurrence of the event
+type Rule struct {
+	ID            int       `json:"id" db:"id"`
+	EventID       int       `json:"event_id" db:"event_id"`
+	MinutesBefore int       `json:"minutes_before" db:"minutes_before"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+}
+
+// Job represents the reminder_jobs table: one pending or fired reminder
+// for one occurrence
+type Job struct {
+	ID              int        `json:"id" db:"id"`
+	EventID         int        `json:"event_id" db:"event_id"`
+	RuleID          int        `json:"rule_id" db:"rule_id"`
+	OccurrenceStart time.Time  `json:"occurrence_start" db:"occurrence_start"`
+	FireAt          time.Time  `json:"fire_at" db:"fire_at"`
+	Status          string     `json:"status" db:"status"`
+	Attempts        int        `json:"attempts" db:"attempts"`
+	LastError       string     `json:"last_error,omitempty" db:"last_error"`
+	SentAt          *time.Time `json:"sent_at,omitempty" db:"sent_at"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+// Notification is handed to a Notifier when a reminder fires
+type Notification struct {
+	Event           event.Event `json:"event"`
+	OccurrenceStart time.Time   `json:"occurrence_start"`
+	MinutesBefore   int         `json:"minutes_before"`
+	JobID           int         `json:"job_id"`
+}
+
+// Validate checks the reminder rule
+func (r *Rule) Validate() error {
+	if r.EventID == 0 {
+		return fmt.Errorf("event is required")
+	}
+	if r.MinutesBefore < 1 || r.MinutesBefore > MaxMinutesBefore {
+		return fmt.Errorf("minutes_before must be between 1 and %d", MaxMinutesBefore)
+	}
+	return nil
+}
+
+// Offset returns how long before the occurrence the reminder fires
+func (r *Rule) Offset() time.Duration {
+	return time.Duration(r.MinutesBefore) * time.Minute
+}
+
+// key identifies the job of a rule for one occurrence
+func (j *Job) key() string {
+	return fmt.Sprintf("%d/%d", j.RuleID, j.OccurrenceStart.Unix())
+}
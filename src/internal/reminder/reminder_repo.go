@@ -0,0 +1,76 @@
+package reminder
+
+import (
+	"context"
+
+	"apidesign/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	rulesCollection = "event_reminders"
+	jobsCollection  = "reminder_jobs"
+)
+
+type Repo struct {
+	db database.Database // Reference to the Database interface
+}
+
+// NewRepo creates a new reminder Repo backed by the given database
+func NewRepo(db database.Database) *Repo {
+	return &Repo{db: db}
+}
+
+// CreateRule adds a reminder rule to an event
+func (repo *Repo) CreateRule(ctx context.Context, rule *Rule) error {
+	return repo.db.Create(ctx, rulesCollection, rule)
+}
+
+// GetRule retrieves a reminder rule of an event
+func (repo *Repo) GetRule(ctx context.Context, eventID int, id int) (Rule, error) {
+	var rule Rule
+	err := repo.db.FindOne(ctx, rulesCollection, bson.M{"id": id, "event_id": eventID}, &rule)
+	return rule, err
+}
+
+// DeleteRule removes a reminder rule
+func (repo *Repo) DeleteRule(ctx context.Context, id int) error {
+	return repo.db.Delete(ctx, rulesCollection, bson.M{"id": id})
+}
+
+// DeleteRules removes every reminder rule of an event
+func (repo *Repo) DeleteRules(ctx context.Context, eventID int) error {
+	return repo.db.Delete(ctx, rulesCollection, bson.M{"event_id": eventID})
+}
+
+// FindRules retrieves reminder rules based on conditions, limit, and offset
+func (repo *Repo) FindRules(ctx context.Context, filter bson.M, limit int64, offset int64) ([]Rule, error) {
+	var rules []Rule
+	err := repo.db.Find(ctx, rulesCollection, filter, &rules, limit, offset)
+	return rules, err
+}
+
+// CreateJob persists a scheduled reminder
+func (repo *Repo) CreateJob(ctx context.Context, job *Job) error {
+	return repo.db.Create(ctx, jobsCollection, job)
+}
+
+// UpdateJob stores the outcome of a reminder job
+func (repo *Repo) UpdateJob(ctx context.Context, job *Job) error {
+	return repo.db.Update(ctx, jobsCollection, bson.M{"id": job.ID}, map[string]interface{}{
+		"fire_at":    job.FireAt,
+		"status":     job.Status,
+		"attempts":   job.Attempts,
+		"last_error": job.LastError,
+		"sent_at":    job.SentAt,
+		"updated_at": job.UpdatedAt,
+	})
+}
+
+// FindJobs retrieves reminder jobs based on conditions, limit, and offset
+func (repo *Repo) FindJobs(ctx context.Context, filter bson.M, limit int64, offset int64) ([]Job, error) {
+	var jobs []Job
+	err := repo.db.Find(ctx, jobsCollection, filter, &jobs, limit, offset)
+	return jobs, err
+}
@@ -0,0 +1,287 @@
+package reminder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"apidesign/internal/database"
+	"apidesign/internal/event"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const (
+	// ScheduleHorizon is how far ahead occurrences get reminder jobs
+	ScheduleHorizon = 30 * 24 * time.Hour
+
+	maxAttempts = 5
+	retryDelay  = time.Minute
+	batchSize   = 100
+)
+
+// Scheduler turns reminder rules into persisted jobs and fires the due
+// ones. Pending jobs live in the database, so a restarted scheduler picks
+// up where it left off. Run a single scheduler per database.
+type Scheduler struct {
+	repo     *Repo
+	events   *event.EventRepo
+	notifier Notifier
+	interval time.Duration
+	now      func() time.Time
+}
+
+// NewScheduler creates a scheduler that checks for due reminders every interval
+func NewScheduler(repo *Repo, events *event.EventRepo, notifier Notifier, interval time.Duration) *Scheduler {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	return &Scheduler{
+		repo:     repo,
+		events:   events,
+		notifier: notifier,
+		interval: interval,
+		now:      time.Now,
+	}
+}
+
+// Start runs the scheduler until ctx is done
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Tick(ctx); err != nil {
+			log.Printf("Reminder scheduler: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Tick brings the jobs of every event with reminders up to date and fires
+// the due ones. Due jobs fire even when syncing fails.
+func (s *Scheduler) Tick(ctx context.Context) error {
+	syncErr := s.Sync(ctx)
+	return errors.Join(syncErr, s.FireDue(ctx))
+}
+
+// Sync schedules the jobs of every event that has reminder rules. An event
+// that fails to schedule is logged and skipped, so it cannot hold up the
+// others.
+func (s *Scheduler) Sync(ctx context.Context) error {
+	rules, err := s.repo.FindRules(ctx, bson.M{}, 0, 0)
+	if err != nil {
+		return err
+	}
+	byEvent := make(map[int][]Rule)
+	for _, rule := range rules {
+		byEvent[rule.EventID] = append(byEvent[rule.EventID], rule)
+	}
+	for eventID, eventRules := range byEvent {
+		if err := s.schedule(ctx, eventID, eventRules); err != nil {
+			log.Printf("Reminder scheduler: event %d: %v", eventID, err)
+		}
+	}
+	return nil
+}
+
+// Schedule brings the jobs of one event in line with its rules, e.g. after
+// the rules, times or status of the event changed
+func (s *Scheduler) Schedule(ctx context.Context, eventID int) error {
+	rules, err := s.repo.FindRules(ctx, bson.M{"event_id": eventID}, 0, 0)
+	if err != nil {
+		return err
+	}
+	return s.schedule(ctx, eventID, rules)
+}
+
+// schedule creates the missing jobs for upcoming occurrences and cancels
+// pending jobs that no longer match an occurrence and rule
+func (s *Scheduler) schedule(ctx context.Context, eventID int, rules []Rule) error {
+	now := s.now()
+
+	e, err := s.events.GetEvent(ctx, eventID)
+	if database.IsNotFound(err) {
+		// The event is gone; its rules and pending jobs go with it
+		if err := s.repo.DeleteRules(ctx, eventID); err != nil {
+			return err
+		}
+		return s.cancelJobs(ctx, eventID, nil, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	wanted := make(map[string]Job)
+	// Reminders only fire for published events
+	if e.Status == event.EventStatusPublished && len(rules) > 0 {
+		exceptions, err := s.events.FindExceptions(ctx, []int{e.ID})
+		if err != nil {
+			return err
+		}
+		occurrences, err := e.Occurrences(now, now.Add(ScheduleHorizon), exceptions)
+		if err != nil {
+			return err
+		}
+		for _, occ := range occurrences {
+			if !occ.StartDate.After(now) {
+				continue
+			}
+			for _, rule := range rules {
+				job := Job{
+					EventID:         e.ID,
+					RuleID:          rule.ID,
+					OccurrenceStart: occ.StartDate.UTC(),
+					FireAt:          occ.StartDate.Add(-rule.Offset()).UTC(),
+					Status:          JobPending,
+					CreatedAt:       now,
+					UpdatedAt:       now,
+				}
+				wanted[job.key()] = job
+			}
+		}
+	}
+
+	existing, err := s.repo.FindJobs(ctx, bson.M{
+		"event_id":         e.ID,
+		"occurrence_start": bson.M{"$gt": now},
+	}, 0, 0)
+	if err != nil {
+		return err
+	}
+	// A canceled job comes back when its occurrence and rule are wanted
+	// again, e.g. after the event moved away and back
+	have := make(map[string]Job, len(existing))
+	for _, job := range existing {
+		if prev, ok := have[job.key()]; ok && prev.Status != JobCanceled {
+			continue
+		}
+		have[job.key()] = job
+	}
+
+	if err := s.cancelJobs(ctx, e.ID, wanted, now); err != nil {
+		return err
+	}
+	for key, job := range wanted {
+		prev, ok := have[key]
+		if !ok {
+			if err := s.repo.CreateJob(ctx, &job); err != nil {
+				return err
+			}
+			continue
+		}
+		if prev.Status != JobCanceled {
+			continue
+		}
+		prev.FireAt = job.FireAt
+		prev.Status = JobPending
+		prev.Attempts = 0
+		prev.LastError = ""
+		prev.UpdatedAt = now
+		if err := s.repo.UpdateJob(ctx, &prev); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cancelJobs cancels the pending jobs of an event that are not in keep
+func (s *Scheduler) cancelJobs(ctx context.Context, eventID int, keep map[string]Job, now time.Time) error {
+	pending, err := s.repo.FindJobs(ctx, bson.M{"event_id": eventID, "status": JobPending}, 0, 0)
+	if err != nil {
+		return err
+	}
+	for _, job := range pending {
+		if _, ok := keep[job.key()]; ok {
+			continue
+		}
+		job.Status = JobCanceled
+		job.UpdatedAt = now
+		if err := s.repo.UpdateJob(ctx, &job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FireDue sends every pending job whose time has come
+func (s *Scheduler) FireDue(ctx context.Context) error {
+	now := s.now()
+	jobs, err := s.repo.FindJobs(ctx, bson.M{
+		"status":  JobPending,
+		"fire_at": bson.M{"$lte": now},
+	}, batchSize, 0)
+	if err != nil {
+		return err
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].FireAt.Before(jobs[j].FireAt) })
+
+	for i := range jobs {
+		if err := s.fire(ctx, &jobs[i], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fire notifies one job and records the outcome; notifier failures are
+// retried with a growing delay until maxAttempts
+func (s *Scheduler) fire(ctx context.Context, job *Job, now time.Time) error {
+	job.UpdatedAt = now
+
+	e, err := s.events.GetEvent(ctx, job.EventID)
+	switch {
+	case database.IsNotFound(err):
+		job.Status = JobCanceled
+		job.LastError = "event no longer exists"
+		return s.repo.UpdateJob(ctx, job)
+	case err != nil:
+		return err
+	case e.Status != event.EventStatusPublished:
+		job.Status = JobCanceled
+		job.LastError = fmt.Sprintf("event is %s", e.Status)
+		return s.repo.UpdateJob(ctx, job)
+	case !now.Before(job.OccurrenceStart):
+		// Missed while the scheduler was down; too late to be useful
+		job.Status = JobExpired
+		return s.repo.UpdateJob(ctx, job)
+	}
+
+	rule, err := s.repo.GetRule(ctx, job.EventID, job.RuleID)
+	if database.IsNotFound(err) {
+		job.Status = JobCanceled
+		job.LastError = "reminder was removed"
+		return s.repo.UpdateJob(ctx, job)
+	}
+	if err != nil {
+		return err
+	}
+
+	job.Attempts++
+	err = s.notifier.Notify(ctx, Notification{
+		Event:           e,
+		OccurrenceStart: job.OccurrenceStart,
+		MinutesBefore:   rule.MinutesBefore,
+		JobID:           job.ID,
+	})
+	switch {
+	case err == nil:
+		job.Status = JobSent
+		job.LastError = ""
+		job.SentAt = &now
+	case job.Attempts >= maxAttempts:
+		job.Status = JobFailed
+		job.LastError = err.Error()
+	default:
+		job.FireAt = now.Add(retryDelay * time.Duration(job.Attempts))
+		job.LastError = err.Error()
+	}
+	return s.repo.UpdateJob(ctx, job)
+}
@@ -0,0 +1,206 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// How much a match counts relative to an exact match
+const (
+	prefixMatch = 0.8
+	fuzzyMatch  = 0.6
+)
+
+// MemoryIndex is an in-memory inverted index. It is rebuilt from the
+// primary store on startup, so it works the same over Postgres and Mongo.
+type MemoryIndex struct {
+	mu      sync.RWMutex
+	weights map[string]float64
+	// postings maps a term to the documents containing it and, per
+	// document, the weighted number of occurrences
+	postings map[string]map[int]float64
+	// terms of every document, to remove them on Put and Delete
+	docs map[int][]string
+	// sorted holds the terms in order for prefix lookups; nil when stale
+	sorted []string
+}
+
+// NewMemoryIndex creates an empty index. Fields missing from weights count 1.
+func NewMemoryIndex(weights map[string]float64) *MemoryIndex {
+	if weights == nil {
+		weights = DefaultWeights
+	}
+	return &MemoryIndex{
+		weights:  weights,
+		postings: make(map[string]map[int]float64),
+		docs:     make(map[int][]string),
+	}
+}
+
+// Put adds the document or replaces the one with the same ID
+func (m *MemoryIndex) Put(doc Document) error {
+	counts := make(map[string]float64)
+	for field, values := range doc.Fields {
+		weight, ok := m.weights[field]
+		if !ok {
+			weight = 1
+		}
+		for _, value := range values {
+			for _, term := range Tokenize(value) {
+				counts[term] += weight
+			}
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(doc.ID)
+	terms := make([]string, 0, len(counts))
+	for term, weight := range counts {
+		docs, ok := m.postings[term]
+		if !ok {
+			docs = make(map[int]float64)
+			m.postings[term] = docs
+			m.sorted = nil
+		}
+		docs[doc.ID] = weight
+		terms = append(terms, term)
+	}
+	m.docs[doc.ID] = terms
+	return nil
+}
+
+// Delete removes a document; unknown IDs are ignored
+func (m *MemoryIndex) Delete(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.remove(id)
+	return nil
+}
+
+// Search scores documents with tf-idf summed over the query words. A word
+// matches a term exactly, as its prefix or within maxTypos edits; weaker
+// matches score less. Documents must match every word.
+func (m *MemoryIndex) Search(q Query) (Result, error) {
+	words := Tokenize(q.Text)
+	if len(words) == 0 {
+		return Result{}, ErrEmptyQuery
+	}
+
+	// A Put or Delete between sorting and reading may leave the terms
+	// stale again, so check once more under the read lock
+	m.mu.RLock()
+	for m.sorted == nil {
+		m.mu.RUnlock()
+		m.mu.Lock()
+		m.sortTerms()
+		m.mu.Unlock()
+		m.mu.RLock()
+	}
+	defer m.mu.RUnlock()
+
+	total := float64(len(m.docs))
+	var scores map[int]float64
+	for _, word := range words {
+		// The best match of the word per document
+		best := make(map[int]float64)
+		for term, match := range m.candidates(word) {
+			docs := m.postings[term]
+			idf := math.Log(1 + total/float64(len(docs)))
+			for id, tf := range docs {
+				if score := match * tf * idf; score > best[id] {
+					best[id] = score
+				}
+			}
+		}
+
+		if scores == nil {
+			scores = best
+			continue
+		}
+		for id := range scores {
+			if s, ok := best[id]; ok {
+				scores[id] += s
+			} else {
+				delete(scores, id)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		hits = append(hits, Hit{ID: id, Score: math.Round(score*1000) / 1000})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].ID < hits[j].ID
+	})
+
+	result := Result{Total: len(hits), Hits: []Hit{}}
+	if q.Offset < len(hits) {
+		hits = hits[q.Offset:]
+		if q.Limit > 0 && len(hits) > q.Limit {
+			hits = hits[:q.Limit]
+		}
+		result.Hits = hits
+	}
+	return result, nil
+}
+
+// candidates returns the terms a query word matches and how well
+func (m *MemoryIndex) candidates(word string) map[string]float64 {
+	matches := make(map[string]float64)
+	if _, ok := m.postings[word]; ok {
+		matches[word] = 1
+	}
+
+	// Terms starting with word are adjacent in sorted order
+	for i := sort.SearchStrings(m.sorted, word); i < len(m.sorted) && strings.HasPrefix(m.sorted[i], word); i++ {
+		if term := m.sorted[i]; term != word {
+			// Closer to the whole term scores higher
+			matches[term] = prefixMatch * float64(len(word)) / float64(len(term))
+		}
+	}
+
+	if typos := maxTypos(word); typos > 0 {
+		for term := range m.postings {
+			if _, ok := matches[term]; ok {
+				continue
+			}
+			if d := distance(word, term, typos); d <= typos {
+				matches[term] = fuzzyMatch / float64(d)
+			}
+		}
+	}
+	return matches
+}
+
+// remove drops a document's postings; the caller holds the write lock
+func (m *MemoryIndex) remove(id int) {
+	for _, term := range m.docs[id] {
+		docs := m.postings[term]
+		delete(docs, id)
+		if len(docs) == 0 {
+			delete(m.postings, term)
+			m.sorted = nil
+		}
+	}
+	delete(m.docs, id)
+}
+
+// sortTerms rebuilds the sorted terms when stale; the caller holds the
+// write lock
+func (m *MemoryIndex) sortTerms() {
+	if m.sorted != nil {
+		return
+	}
+	m.sorted = make([]string, 0, len(m.postings))
+	for term := range m.postings {
+		m.sorted = append(m.sorted, term)
+	}
+	sort.Strings(m.sorted)
+}
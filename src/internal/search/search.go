@@ -0,0 +1,64 @@
+// Package search provides full-text search over records such as contacts.
+// Callers feed an Index with Documents and query it with free text; the
+// Index interface keeps the search API independent of the primary store.
+package search
+
+import "errors"
+
+// Searchable fields of a document
+const (
+	FieldName  = "name"
+	FieldEmail = "email"
+	FieldPhone = "phone"
+	FieldNotes = "notes"
+	FieldTags  = "tags"
+)
+
+// DefaultWeights rank name matches above email and phone matches, and those
+// above tags and notes
+var DefaultWeights = map[string]float64{
+	FieldName:  3,
+	FieldEmail: 2,
+	FieldPhone: 2,
+	FieldTags:  1.5,
+	FieldNotes: 1,
+}
+
+var ErrEmptyQuery = errors.New("search query is empty")
+
+// Document is the searchable text of a record, by field
+type Document struct {
+	ID     int
+	Fields map[string][]string
+}
+
+// Query is a free-text query. Every word must match, exactly, as a prefix
+// or within a small number of typos.
+type Query struct {
+	Text   string
+	Limit  int
+	Offset int
+}
+
+// Hit is a matching document and its relevance score
+type Hit struct {
+	ID    int     `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// Result is a page of hits, best first
+type Result struct {
+	Total int   `json:"total"`
+	Hits  []Hit `json:"hits"`
+}
+
+// Index stores documents and answers queries. Implementations must be safe
+// for concurrent use.
+type Index interface {
+	// Put adds the document or replaces the one with the same ID
+	Put(doc Document) error
+	// Delete removes a document; unknown IDs are ignored
+	Delete(id int) error
+	// Search returns the documents matching the query, best first
+	Search(q Query) (Result, error)
+}
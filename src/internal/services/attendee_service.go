@@ -0,0 +1,478 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"apidesign/internal/contact"
+	"apidesign/internal/database"
+	"apidesign/internal/event"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Service errors
+var (
+	ErrAttendeeNotFound = errors.New("attendee not found")
+	ErrInvalidAttendee  = errors.New("invalid attendee data")
+	ErrAttendeeExists   = errors.New("contact is already an attendee of this event")
+)
+
+// ContactEvent is an event a contact attends, with the attendance details
+type ContactEvent struct {
+	Event    event.Event    `json:"event"`
+	Attendee event.Attendee `json:"attendance"`
+}
+
+// WaitlistHook is called after a waitlisted attendee has been given a seat
+type WaitlistHook func(ctx context.Context, e event.Event, a event.Attendee)
+
+// AttendeeService links contacts to events and tracks their RSVP state.
+// Accepted attendees count towards the event capacity; anyone beyond it is
+// waitlisted and promoted in order as seats free up.
+type AttendeeService struct {
+	repo     *event.AttendeeRepo
+	events   *event.EventRepo
+	contacts *contact.ContactRepo
+	hooks    []WaitlistHook
+}
+
+// NewAttendeeService creates a new instance of AttendeeService
+func NewAttendeeService(repo *event.AttendeeRepo, events *event.EventRepo, contacts *contact.ContactRepo) *AttendeeService {
+	return &AttendeeService{
+		repo:     repo,
+		events:   events,
+		contacts: contacts,
+	}
+}
+
+// OnPromoted registers a hook that runs after each waitlist promotion
+func (s *AttendeeService) OnPromoted(hook WaitlistHook) {
+	s.hooks = append(s.hooks, hook)
+}
+
+// AddAttendee links a contact to an event after checking both exist.
+// An accepted registration beyond capacity is waitlisted instead.
+func (s *AttendeeService) AddAttendee(ctx context.Context, attendee *event.Attendee) error {
+	if attendee.RSVPStatus == event.RSVPWaitlisted {
+		return errors.Join(ErrInvalidAttendee, errors.New("waitlisted status is assigned automatically"))
+	}
+	if err := s.checkContact(ctx, attendee.ContactID); err != nil {
+		return err
+	}
+
+	attendee.ID = 0
+	attendee.CheckedInAt = nil
+	attendee.WaitlistedAt = nil
+	attendee.BeforeCreate()
+	if err := attendee.Validate(); err != nil {
+		return errors.Join(ErrInvalidAttendee, err)
+	}
+
+	// The event row lock serialises concurrent registrations for one event
+	return s.events.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+		events, attendees := s.events.WithTx(tx), s.repo.WithTx(tx)
+		if err := events.LockEvent(ctx, attendee.EventID); err != nil {
+			return err
+		}
+
+		e, err := getEvent(ctx, events, attendee.EventID)
+		if err != nil {
+			return err
+		}
+		if e.Status == event.EventStatusCanceled || e.Status == event.EventStatusCompleted {
+			return errors.Join(ErrInvalidAttendee, errors.New("event is "+e.Status))
+		}
+
+		if _, err := getAttendee(ctx, attendees, attendee.EventID, attendee.ContactID); err == nil {
+			return ErrAttendeeExists
+		} else if !errors.Is(err, ErrAttendeeNotFound) {
+			return err
+		}
+
+		if attendee.RSVPStatus == event.RSVPAccepted {
+			if err := seat(ctx, attendees, e, attendee); err != nil {
+				return err
+			}
+		}
+		return attendees.CreateAttendee(ctx, attendee)
+	})
+}
+
+// GetAttendee retrieves the attendance of a contact at an event
+func (s *AttendeeService) GetAttendee(ctx context.Context, eventID int, contactID int) (event.Attendee, error) {
+	return getAttendee(ctx, s.repo, eventID, contactID)
+}
+
+// UpdateAttendee changes the RSVP status and/or role of an attendance.
+// Accepting a full event waitlists the attendee; leaving an accepted seat
+// promotes the first waitlisted attendee.
+func (s *AttendeeService) UpdateAttendee(ctx context.Context, eventID int, contactID int, rsvpStatus string, role string) (event.Attendee, error) {
+	if rsvpStatus == event.RSVPWaitlisted {
+		return event.Attendee{}, errors.Join(ErrInvalidAttendee, errors.New("waitlisted status is assigned automatically"))
+	}
+
+	var attendee event.Attendee
+	var e event.Event
+	var promoted []event.Attendee
+
+	err := s.events.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+		events, attendees := s.events.WithTx(tx), s.repo.WithTx(tx)
+		if err := events.LockEvent(ctx, eventID); err != nil {
+			return err
+		}
+
+		var err error
+		if e, err = getEvent(ctx, events, eventID); err != nil {
+			return err
+		}
+		if attendee, err = getAttendee(ctx, attendees, eventID, contactID); err != nil {
+			return err
+		}
+
+		wasAccepted := attendee.RSVPStatus == event.RSVPAccepted
+		if rsvpStatus != "" && rsvpStatus != attendee.RSVPStatus {
+			attendee.RSVPStatus = rsvpStatus
+			if rsvpStatus == event.RSVPAccepted {
+				if err := seat(ctx, attendees, e, &attendee); err != nil {
+					return err
+				}
+			} else {
+				attendee.WaitlistedAt = nil
+				attendee.CheckedInAt = nil
+			}
+		}
+		if role != "" {
+			attendee.Role = role
+		}
+		if err := attendee.Validate(); err != nil {
+			return errors.Join(ErrInvalidAttendee, err)
+		}
+
+		attendee.BeforeUpdate()
+		if err := attendees.UpdateAttendee(ctx, &attendee); err != nil {
+			return err
+		}
+
+		if wasAccepted && attendee.RSVPStatus != event.RSVPAccepted {
+			promoted, err = promote(ctx, attendees, e)
+		}
+		return err
+	})
+	if err != nil {
+		return event.Attendee{}, err
+	}
+
+	s.runHooks(ctx, e, promoted)
+	return attendee, nil
+}
+
+// CheckIn records the arrival of an accepted attendee
+func (s *AttendeeService) CheckIn(ctx context.Context, eventID int, contactID int) (event.Attendee, error) {
+	var attendee event.Attendee
+
+	// The attendee is read under the event lock, so a concurrent decline or
+	// promotion is not overwritten with a stale RSVP status
+	err := s.events.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+		events, attendees := s.events.WithTx(tx), s.repo.WithTx(tx)
+		if err := events.LockEvent(ctx, eventID); err != nil {
+			return err
+		}
+
+		var err error
+		if _, err = getEvent(ctx, events, eventID); err != nil {
+			return err
+		}
+		if attendee, err = getAttendee(ctx, attendees, eventID, contactID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		attendee.CheckedInAt = &now
+		if err := attendee.Validate(); err != nil {
+			return errors.Join(ErrInvalidAttendee, err)
+		}
+
+		attendee.BeforeUpdate()
+		return attendees.UpdateAttendee(ctx, &attendee)
+	})
+	if err != nil {
+		return event.Attendee{}, err
+	}
+	return attendee, nil
+}
+
+// RemoveAttendee unlinks a contact from an event, promoting the first
+// waitlisted attendee when an accepted seat is freed
+func (s *AttendeeService) RemoveAttendee(ctx context.Context, eventID int, contactID int) error {
+	var e event.Event
+	var promoted []event.Attendee
+
+	err := s.events.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+		events, attendees := s.events.WithTx(tx), s.repo.WithTx(tx)
+		if err := events.LockEvent(ctx, eventID); err != nil {
+			return err
+		}
+
+		var err error
+		if e, err = getEvent(ctx, events, eventID); err != nil {
+			return err
+		}
+		attendee, err := getAttendee(ctx, attendees, eventID, contactID)
+		if err != nil {
+			return err
+		}
+		if err := attendees.DeleteAttendee(ctx, eventID, contactID); err != nil {
+			return err
+		}
+
+		if attendee.RSVPStatus == event.RSVPAccepted {
+			promoted, err = promote(ctx, attendees, e)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	s.runHooks(ctx, e, promoted)
+	return nil
+}
+
+// ListAttendees retrieves the attendees of an event
+func (s *AttendeeService) ListAttendees(ctx context.Context, eventID int, rsvpStatus string, limit int64, offset int64) ([]event.Attendee, error) {
+	if _, err := getEvent(ctx, s.events, eventID); err != nil {
+		return nil, err
+	}
+	if rsvpStatus != "" && !event.IsValidRSVP(rsvpStatus) {
+		return nil, errors.Join(ErrInvalidAttendee, errors.New("invalid rsvp status "+rsvpStatus))
+	}
+
+	filter := bson.M{"event_id": eventID}
+	if rsvpStatus != "" {
+		filter["rsvp_status"] = rsvpStatus
+	}
+	if limit == 0 {
+		limit = 10
+	}
+	return s.repo.FindAttendees(ctx, filter, limit, offset)
+}
+
+// ListWaitlist retrieves the waitlist of an event in promotion order
+func (s *AttendeeService) ListWaitlist(ctx context.Context, eventID int) ([]event.Attendee, error) {
+	if _, err := getEvent(ctx, s.events, eventID); err != nil {
+		return nil, err
+	}
+	return waitlist(ctx, s.repo, eventID)
+}
+
+// ListContactEvents retrieves the events a contact is linked to
+func (s *AttendeeService) ListContactEvents(ctx context.Context, contactID int, limit int64, offset int64) ([]ContactEvent, error) {
+	if err := s.checkContact(ctx, contactID); err != nil {
+		return nil, err
+	}
+	if limit == 0 {
+		limit = 10
+	}
+
+	attendees, err := s.repo.FindAttendees(ctx, bson.M{"contact_id": contactID}, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if len(attendees) == 0 {
+		return []ContactEvent{}, nil
+	}
+
+	ids := make([]int, len(attendees))
+	for i, a := range attendees {
+		ids[i] = a.EventID
+	}
+	events, err := s.events.FindEvents(ctx, bson.M{"id": bson.M{"$in": ids}}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]event.Event, len(events))
+	for _, e := range events {
+		byID[e.ID] = e
+	}
+
+	result := make([]ContactEvent, 0, len(attendees))
+	for _, a := range attendees {
+		if e, ok := byID[a.EventID]; ok {
+			result = append(result, ContactEvent{Event: e, Attendee: a})
+		}
+	}
+	return result, nil
+}
+
+// ReassignContacts moves the attendances of merged contacts to the survivor.
+// Where several of them attend the same event the strongest RSVP is kept.
+// It is a ContactService merge hook and runs inside the merge transaction.
+func (s *AttendeeService) ReassignContacts(ctx context.Context, tx database.Database, survivorID int, mergedIDs []int) error {
+	repo := s.repo.WithTx(tx)
+	contactIDs := append([]int{survivorID}, mergedIDs...)
+	attendees, err := repo.FindAttendees(ctx, bson.M{"contact_id": bson.M{"$in": contactIDs}}, 0, 0)
+	if err != nil {
+		return err
+	}
+
+	// Pick one attendance per event
+	kept := make(map[int]event.Attendee)
+	for _, a := range attendees {
+		current, ok := kept[a.EventID]
+		if !ok || rsvpRank(a.RSVPStatus) > rsvpRank(current.RSVPStatus) ||
+			(rsvpRank(a.RSVPStatus) == rsvpRank(current.RSVPStatus) && a.ContactID == survivorID) {
+			kept[a.EventID] = a
+		}
+	}
+
+	for _, a := range attendees {
+		keep := kept[a.EventID]
+		if a.ID != keep.ID {
+			if err := repo.DeleteAttendee(ctx, a.EventID, a.ContactID); err != nil {
+				return err
+			}
+		}
+	}
+	for _, a := range kept {
+		if a.ContactID == survivorID {
+			continue
+		}
+		a.ContactID = survivorID
+		a.BeforeUpdate()
+		if err := repo.UpdateAttendee(ctx, &a); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rsvpRank orders RSVP statuses from weakest to strongest commitment
+func rsvpRank(status string) int {
+	switch status {
+	case event.RSVPAccepted:
+		return 4
+	case event.RSVPTentative:
+		return 3
+	case event.RSVPInvited:
+		return 2
+	case event.RSVPWaitlisted:
+		return 1
+	}
+	return 0
+}
+
+func (s *AttendeeService) checkContact(ctx context.Context, id int) error {
+	c, err := s.contacts.GetContact(ctx, id)
+	if database.IsNotFound(err) || (err == nil && c.ID == 0) {
+		return ErrContactNotFound
+	}
+	return err
+}
+
+func (s *AttendeeService) runHooks(ctx context.Context, e event.Event, promoted []event.Attendee) {
+	for _, a := range promoted {
+		for _, hook := range s.hooks {
+			hook(ctx, e, a)
+		}
+	}
+}
+
+func getEvent(ctx context.Context, events *event.EventRepo, id int) (event.Event, error) {
+	e, err := events.GetEvent(ctx, id)
+	if database.IsNotFound(err) || (err == nil && e.ID == 0) {
+		return event.Event{}, ErrEventNotFound
+	}
+	return e, err
+}
+
+func getAttendee(ctx context.Context, attendees *event.AttendeeRepo, eventID int, contactID int) (event.Attendee, error) {
+	attendee, err := attendees.GetAttendee(ctx, eventID, contactID)
+	if database.IsNotFound(err) || (err == nil && attendee.ID == 0) {
+		return event.Attendee{}, ErrAttendeeNotFound
+	}
+	return attendee, err
+}
+
+// acceptedCount counts the seats taken at an event, ignoring one attendee
+func acceptedCount(ctx context.Context, attendees *event.AttendeeRepo, eventID int, exceptID int) (int, error) {
+	accepted, err := attendees.FindAttendees(ctx, bson.M{"event_id": eventID, "rsvp_status": event.RSVPAccepted}, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, a := range accepted {
+		if a.ID != exceptID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// seat waitlists an accepting attendee when the event is full.
+// Must run inside a transaction holding the event lock.
+func seat(ctx context.Context, attendees *event.AttendeeRepo, e event.Event, attendee *event.Attendee) error {
+	if e.Capacity == 0 {
+		attendee.WaitlistedAt = nil
+		return nil
+	}
+	taken, err := acceptedCount(ctx, attendees, e.ID, attendee.ID)
+	if err != nil {
+		return err
+	}
+	if taken < e.Capacity {
+		attendee.WaitlistedAt = nil
+		return nil
+	}
+
+	attendee.RSVPStatus = event.RSVPWaitlisted
+	attendee.CheckedInAt = nil
+	if attendee.WaitlistedAt == nil {
+		now := time.Now()
+		attendee.WaitlistedAt = &now
+	}
+	return nil
+}
+
+// promote fills free seats from the head of the waitlist.
+// Must run inside a transaction holding the event lock.
+func promote(ctx context.Context, attendees *event.AttendeeRepo, e event.Event) ([]event.Attendee, error) {
+	queue, err := waitlist(ctx, attendees, e.ID)
+	if err != nil || len(queue) == 0 {
+		return nil, err
+	}
+
+	free := len(queue)
+	if e.Capacity > 0 {
+		taken, err := acceptedCount(ctx, attendees, e.ID, 0)
+		if err != nil {
+			return nil, err
+		}
+		free = e.Capacity - taken
+	}
+
+	var promoted []event.Attendee
+	for i := 0; i < free && i < len(queue); i++ {
+		a := queue[i]
+		a.RSVPStatus = event.RSVPAccepted
+		a.WaitlistedAt = nil
+		a.WaitlistPosition = 0
+		a.BeforeUpdate()
+		if err := attendees.UpdateAttendee(ctx, &a); err != nil {
+			return nil, err
+		}
+		promoted = append(promoted, a)
+	}
+	return promoted, nil
+}
+
+// waitlist returns the waitlisted attendees of an event in promotion order
+func waitlist(ctx context.Context, attendees *event.AttendeeRepo, eventID int) ([]event.Attendee, error) {
+	queue, err := attendees.FindAttendees(ctx, bson.M{"event_id": eventID, "rsvp_status": event.RSVPWaitlisted}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	event.SortWaitlist(queue)
+	return queue, nil
+}
@@ -0,0 +1,370 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"apidesign/internal/contact"
+	"apidesign/internal/database"
+	"apidesign/internal/event"
+	"apidesign/internal/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Service errors
+var (
+	ErrCategoryNotFound    = errors.New("category not found")
+	ErrInvalidCategory     = errors.New("invalid category data")
+	ErrCategoryCycle       = errors.New("category parent would create a cycle")
+	ErrCategoryHasChildren = errors.New("category has subcategories")
+	ErrCategoryInUse       = errors.New("category is still in use")
+)
+
+// ContactCategoryService handles business logic for the contact category tree
+type ContactCategoryService struct {
+	repo     *contact.ContactCategoryRepo
+	contacts *contact.ContactRepo
+}
+
+// NewContactCategoryService creates a new instance of ContactCategoryService
+func NewContactCategoryService(repo *contact.ContactCategoryRepo, contacts *contact.ContactRepo) *ContactCategoryService {
+	return &ContactCategoryService{
+		repo:     repo,
+		contacts: contacts,
+	}
+}
+
+// CreateCategory creates a new contact category under an existing parent
+func (s *ContactCategoryService) CreateCategory(ctx context.Context, c *contact.ContactCategory) error {
+	if err := c.Validate(); err != nil {
+		return errors.Join(ErrInvalidCategory, err)
+	}
+	if c.ParentID.Valid {
+		if _, err := s.GetCategory(ctx, int(c.ParentID.Int64)); err != nil {
+			return parentError(err)
+		}
+	}
+
+	now := time.Now()
+	c.CreatedAt = now
+	c.UpdatedAt = now
+	return s.repo.CreateCategory(ctx, c)
+}
+
+// GetCategory retrieves a contact category by ID
+func (s *ContactCategoryService) GetCategory(ctx context.Context, id int) (contact.ContactCategory, error) {
+	c, err := s.repo.GetCategory(ctx, id)
+	if database.IsNotFound(err) || (err == nil && c.ID == 0) {
+		return contact.ContactCategory{}, ErrCategoryNotFound
+	}
+	return c, err
+}
+
+// UpdateCategory updates a contact category; moving it below one of its
+// own descendants is refused
+func (s *ContactCategoryService) UpdateCategory(ctx context.Context, c *contact.ContactCategory) error {
+	if err := c.Validate(); err != nil {
+		return errors.Join(ErrInvalidCategory, err)
+	}
+	existing, err := s.GetCategory(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	if c.ParentID.Valid {
+		categories, err := s.allCategories(ctx)
+		if err != nil {
+			return err
+		}
+		parentID := int(c.ParentID.Int64)
+		if models.CreatesCycle(contact.CategoryNodes(categories), c.ID, parentID) {
+			return ErrCategoryCycle
+		}
+		if _, err := s.GetCategory(ctx, parentID); err != nil {
+			return parentError(err)
+		}
+	}
+
+	c.CreatedAt = existing.CreatedAt
+	c.UpdatedAt = time.Now()
+	return s.repo.UpdateCategory(ctx, c)
+}
+
+// DeleteCategory removes a contact category that has no subcategories and
+// that no contact refers to
+func (s *ContactCategoryService) DeleteCategory(ctx context.Context, id int) error {
+	if _, err := s.GetCategory(ctx, id); err != nil {
+		return err
+	}
+	children, err := s.repo.FindCategories(ctx, bson.M{"parent_id": id}, 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		return ErrCategoryHasChildren
+	}
+	contacts, err := s.contacts.FindContacts(ctx, bson.M{"category_id": id}, 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(contacts) > 0 {
+		return ErrCategoryInUse
+	}
+	return s.repo.DeleteCategory(ctx, id)
+}
+
+// ListCategories lists contact categories; parentID 0 lists every category
+func (s *ContactCategoryService) ListCategories(ctx context.Context, parentID int, limit int64, offset int64) ([]contact.ContactCategory, error) {
+	filter := bson.M{}
+	if parentID != 0 {
+		filter["parent_id"] = parentID
+	}
+	return s.repo.FindCategories(ctx, filter, limit, offset)
+}
+
+// GetTree returns the category with all of its subcategories nested
+func (s *ContactCategoryService) GetTree(ctx context.Context, id int) (contact.ContactCategoryTree, error) {
+	categories, err := s.allCategories(ctx)
+	if err != nil {
+		return contact.ContactCategoryTree{}, err
+	}
+	tree, ok := contact.BuildCategoryTree(categories, id)
+	if !ok {
+		return contact.ContactCategoryTree{}, ErrCategoryNotFound
+	}
+	return tree, nil
+}
+
+// GetDescendants returns every category below id, breadth first
+func (s *ContactCategoryService) GetDescendants(ctx context.Context, id int) ([]contact.ContactCategory, error) {
+	categories, byID, err := s.indexCategories(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return pickCategories(byID, models.Descendants(contact.CategoryNodes(categories), id)), nil
+}
+
+// GetAncestors returns the path from the root down to the parent of id
+func (s *ContactCategoryService) GetAncestors(ctx context.Context, id int) ([]contact.ContactCategory, error) {
+	categories, byID, err := s.indexCategories(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ids := models.Ancestors(contact.CategoryNodes(categories), id)
+	reverseInts(ids)
+	return pickCategories(byID, ids), nil
+}
+
+// SubtreeIDs returns id and the IDs of all its descendants
+func (s *ContactCategoryService) SubtreeIDs(ctx context.Context, id int) ([]int, error) {
+	categories, err := s.allCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]int{id}, models.Descendants(contact.CategoryNodes(categories), id)...), nil
+}
+
+func (s *ContactCategoryService) allCategories(ctx context.Context) ([]contact.ContactCategory, error) {
+	return s.repo.FindCategories(ctx, bson.M{}, 0, 0)
+}
+
+func (s *ContactCategoryService) indexCategories(ctx context.Context, id int) ([]contact.ContactCategory, map[int]contact.ContactCategory, error) {
+	categories, err := s.allCategories(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[int]contact.ContactCategory, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+	if _, ok := byID[id]; !ok {
+		return nil, nil, ErrCategoryNotFound
+	}
+	return categories, byID, nil
+}
+
+// EventCategoryService handles business logic for the event category tree
+type EventCategoryService struct {
+	repo   *event.EventCategoryRepo
+	events *event.EventRepo
+}
+
+// NewEventCategoryService creates a new instance of EventCategoryService
+func NewEventCategoryService(repo *event.EventCategoryRepo, events *event.EventRepo) *EventCategoryService {
+	return &EventCategoryService{
+		repo:   repo,
+		events: events,
+	}
+}
+
+// CreateCategory creates a new event category under an existing parent
+func (s *EventCategoryService) CreateCategory(ctx context.Context, c *event.EventCategory) error {
+	if err := c.Validate(); err != nil {
+		return errors.Join(ErrInvalidCategory, err)
+	}
+	if c.ParentID != nil {
+		if _, err := s.GetCategory(ctx, *c.ParentID); err != nil {
+			return parentError(err)
+		}
+	}
+
+	c.BeforeCreate()
+	return s.repo.CreateCategory(ctx, c)
+}
+
+// GetCategory retrieves an event category by ID
+func (s *EventCategoryService) GetCategory(ctx context.Context, id int) (event.EventCategory, error) {
+	c, err := s.repo.GetCategory(ctx, id)
+	if database.IsNotFound(err) || (err == nil && c.ID == 0) {
+		return event.EventCategory{}, ErrCategoryNotFound
+	}
+	return c, err
+}
+
+// UpdateCategory updates an event category; moving it below one of its
+// own descendants is refused
+func (s *EventCategoryService) UpdateCategory(ctx context.Context, c *event.EventCategory) error {
+	if err := c.Validate(); err != nil {
+		return errors.Join(ErrInvalidCategory, err)
+	}
+	existing, err := s.GetCategory(ctx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	if c.ParentID != nil {
+		categories, err := s.allCategories(ctx)
+		if err != nil {
+			return err
+		}
+		if models.CreatesCycle(event.CategoryNodes(categories), c.ID, *c.ParentID) {
+			return ErrCategoryCycle
+		}
+		if _, err := s.GetCategory(ctx, *c.ParentID); err != nil {
+			return parentError(err)
+		}
+	}
+
+	c.CreatedAt = existing.CreatedAt
+	c.BeforeUpdate()
+	return s.repo.UpdateCategory(ctx, c)
+}
+
+// DeleteCategory removes an event category that has no subcategories and
+// that no event refers to
+func (s *EventCategoryService) DeleteCategory(ctx context.Context, id int) error {
+	if _, err := s.GetCategory(ctx, id); err != nil {
+		return err
+	}
+	children, err := s.repo.FindCategories(ctx, bson.M{"parent_id": id}, 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(children) > 0 {
+		return ErrCategoryHasChildren
+	}
+	events, err := s.events.FindEvents(ctx, bson.M{"category_id": id}, 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(events) > 0 {
+		return ErrCategoryInUse
+	}
+	return s.repo.DeleteCategory(ctx, id)
+}
+
+// ListCategories lists event categories; parentID 0 lists every category
+func (s *EventCategoryService) ListCategories(ctx context.Context, parentID int, limit int64, offset int64) ([]event.EventCategory, error) {
+	filter := bson.M{}
+	if parentID != 0 {
+		filter["parent_id"] = parentID
+	}
+	return s.repo.FindCategories(ctx, filter, limit, offset)
+}
+
+// GetTree returns the category with all of its subcategories nested
+func (s *EventCategoryService) GetTree(ctx context.Context, id int) (event.EventCategoryTree, error) {
+	categories, err := s.allCategories(ctx)
+	if err != nil {
+		return event.EventCategoryTree{}, err
+	}
+	tree, ok := event.BuildCategoryTree(categories, id)
+	if !ok {
+		return event.EventCategoryTree{}, ErrCategoryNotFound
+	}
+	return tree, nil
+}
+
+// GetDescendants returns every category below id, breadth first
+func (s *EventCategoryService) GetDescendants(ctx context.Context, id int) ([]event.EventCategory, error) {
+	categories, byID, err := s.indexCategories(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return pickCategories(byID, models.Descendants(event.CategoryNodes(categories), id)), nil
+}
+
+// GetAncestors returns the path from the root down to the parent of id
+func (s *EventCategoryService) GetAncestors(ctx context.Context, id int) ([]event.EventCategory, error) {
+	categories, byID, err := s.indexCategories(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	ids := models.Ancestors(event.CategoryNodes(categories), id)
+	reverseInts(ids)
+	return pickCategories(byID, ids), nil
+}
+
+// SubtreeIDs returns id and the IDs of all its descendants
+func (s *EventCategoryService) SubtreeIDs(ctx context.Context, id int) ([]int, error) {
+	categories, err := s.allCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append([]int{id}, models.Descendants(event.CategoryNodes(categories), id)...), nil
+}
+
+func (s *EventCategoryService) allCategories(ctx context.Context) ([]event.EventCategory, error) {
+	return s.repo.FindCategories(ctx, bson.M{}, 0, 0)
+}
+
+func (s *EventCategoryService) indexCategories(ctx context.Context, id int) ([]event.EventCategory, map[int]event.EventCategory, error) {
+	categories, err := s.allCategories(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[int]event.EventCategory, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+	if _, ok := byID[id]; !ok {
+		return nil, nil, ErrCategoryNotFound
+	}
+	return categories, byID, nil
+}
+
+// parentError reports a missing parent as invalid input rather than a 404
+func parentError(err error) error {
+	if errors.Is(err, ErrCategoryNotFound) {
+		return errors.Join(ErrInvalidCategory, fmt.Errorf("parent category does not exist"))
+	}
+	return err
+}
+
+// pickCategories returns the categories for ids, in order
+func pickCategories[T any](byID map[int]T, ids []int) []T {
+	result := make([]T, 0, len(ids))
+	for _, id := range ids {
+		result = append(result, byID[id])
+	}
+	return result
+}
+
+func reverseInts(values []int) {
+	for i, j := 0, len(values)-1; i < j; i, j = i+1, j-1 {
+		values[i], values[j] = values[j], values[i]
+	}
+}
@@ -2,10 +2,17 @@ package services
 
 import (
 	"context"
+	"database/sql"
 	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
 	"time"
 	"go.mongodb.org/mongo-driver/bson"
 	"apidesign/internal/contact"
+	"apidesign/internal/database"
+	"apidesign/internal/phone"
 )
 
 
@@ -14,36 +21,117 @@ var (
 	ErrContactNotFound    = errors.New("contact not found")
 	ErrInvalidContact     = errors.New("invalid contact data")
 	ErrEmailAlreadyExists = errors.New("email already exists")
+	ErrInvalidMerge       = errors.New("invalid merge request")
 )
 
+// DefaultDuplicateThreshold is the minimum score reported as a duplicate
+const DefaultDuplicateThreshold = 0.5
+
+// DefaultContactLimit is the page size of a contact search when none is given
+const DefaultContactLimit = 10
+
+// PageInfo describes a page of a list
+type PageInfo struct {
+	// Total is the number of items in the whole list
+	Total int64
+	// More reports items past the page in the direction it was read
+	More bool
+}
+
+// MergeHook re-points records of the merged contacts to the survivor. It
+// runs inside the merge transaction and must use tx for its writes.
+type MergeHook func(ctx context.Context, tx database.Database, survivorID int, mergedIDs []int) error
+
+// DeleteHook removes records that belong to a deleted contact. It runs in
+// the delete transaction and must use tx for its writes.
+type DeleteHook func(ctx context.Context, tx database.Database, contactID int) error
+
+// SaveHook runs after a contact was created (before is nil) or changed, in
+// the same transaction, and must use tx for its writes
+type SaveHook func(ctx context.Context, tx database.Database, before *contact.Contact, after contact.Contact) error
+
+// ChangeHook is told, after the change was committed, that a contact was
+// created, changed, trashed, restored or deleted. It runs outside any
+// transaction and cannot fail the change.
+type ChangeHook func(ctx context.Context, contactID int)
+
+// MergeContactsRequest selects the survivor, the contacts merged into it
+// and, per field, the contact whose value is kept
+type MergeContactsRequest struct {
+	SurvivorID int            `json:"survivor_id"`
+	MergedIDs  []int          `json:"merged_ids"`
+	Fields     map[string]int `json:"fields"`
+}
+
 // ContactService handles business logic for contacts
 type ContactService struct {
-	repo *contact.ContactRepo
+	repo        *contact.ContactRepo
+	categories  *ContactCategoryService
+	types       *ContactTypeService
+	tags        *TagService
+	mergeHooks  []MergeHook
+	deleteHooks []DeleteHook
+	saveHooks   []SaveHook
+	changeHooks []ChangeHook
+
+	// defaultRegion reads phone numbers without a country code when the
+	// request names no region
+	defaultRegion string
+}
+
+// phoneRegionKey is the context key of a request's phone region
+type phoneRegionKey struct{}
+
+// WithPhoneRegion returns a context in which phone numbers without a country
+// code are read as numbers of region, overriding the default region
+func WithPhoneRegion(ctx context.Context, region string) context.Context {
+	return context.WithValue(ctx, phoneRegionKey{}, region)
 }
 
 // NewContactService creates a new instance of ContactService
-func NewContactService(repo *contact.ContactRepo) *ContactService {
+func NewContactService(repo *contact.ContactRepo, categories *ContactCategoryService, types *ContactTypeService, tags *TagService) *ContactService {
 	return &ContactService{
-		repo: repo,
+		repo:       repo,
+		categories: categories,
+		types:      types,
+		tags:       tags,
 	}
 }
 
+// SetDefaultRegion sets the region of phone numbers written without a
+// country code, e.g. TH for 081-234-5678
+func (s *ContactService) SetDefaultRegion(region string) error {
+	if region != "" && !phone.IsSupportedRegion(region) {
+		return fmt.Errorf("%w %q", phone.ErrUnknownRegion, region)
+	}
+	s.defaultRegion = region
+	return nil
+}
+
 // CreateContact creates a new contact with validation
-func (s *ContactService) CreateContact(ctx context.Context, contact contact.Contact) error {
+func (s *ContactService) CreateContact(ctx context.Context, contact *contact.Contact) error {
+	// IDs are assigned here and contacts only reach the trash through
+	// DeleteContact
+	contact.ID = 0
+	contact.DeletedAt = nil
+
+	if err := s.normalize(ctx, contact); err != nil {
+		return err
+	}
+
 	// Validate contact data
 	if err := contact.Validate(); err != nil {
-		return ErrInvalidContact
+		return errors.Join(ErrInvalidContact, err)
 	}
 
-	// Check if email already exists
-	existingContacts, err := s.repo.FindContacts(ctx, bson.M{
-		"email": contact.Email,
-	}, 1, 0)
-	if err != nil {
+	// Referenced type and category must exist
+	if err := s.checkReferences(ctx, *contact); err != nil {
 		return err
 	}
-	if len(existingContacts) > 0 {
-		return ErrEmailAlreadyExists
+
+	// None of the contact's emails may be in use
+	if err := s.checkEmails(ctx, s.repo, contact, nil); err != nil {
+		return err
 	}
 
 	// Set created and updated timestamps
@@ -51,13 +139,23 @@ func (s *ContactService) CreateContact(ctx context.Context, contact contact.Cont
 	contact.CreatedAt = now
 	contact.UpdatedAt = now
 
-	// Create contact in repository
-	return s.repo.CreateContact(ctx, contact)
+	// Create contact and its emails, phones and addresses in repository
+	err := s.repo.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+		return s.insertContact(ctx, tx, contact)
+	})
+	if err != nil {
+		return err
+	}
+	s.changed(ctx, contact.ID)
+	return nil
 }
 
 // GetContact retrieves a contact by ID with error handling
 func (s *ContactService) GetContact(ctx context.Context, id int) (contact.Contact, error) {
 	retrievedContact, err := s.repo.GetContact(ctx, id)
+	if database.IsNotFound(err) {
+		return contact.Contact{}, ErrContactNotFound
+	}
 	if err != nil {
 		return contact.Contact{}, err
 	}
@@ -66,7 +164,36 @@ func (s *ContactService) GetContact(ctx context.Context, id int) (contact.Contac
 		return contact.Contact{}, ErrContactNotFound
 	}
 
-	return retrievedContact, nil
+	loaded := []contact.Contact{retrievedContact}
+	if err := s.repo.LoadDetails(ctx, loaded); err != nil {
+		return contact.Contact{}, err
+	}
+	return loaded[0], nil
+}
+
+// GetContactFields retrieves a contact reading only the given fields, see
+// contact.ContactFields; no fields read the whole contact
+func (s *ContactService) GetContactFields(ctx context.Context, id int, fields []string) (contact.Contact, error) {
+	for _, f := range fields {
+		if !containsString(contact.ContactFields, f) {
+			return contact.Contact{}, errors.Join(ErrInvalidSearch, fmt.Errorf("unknown field %q", f))
+		}
+	}
+	c, err := s.repo.GetContactWith(ctx, id, database.Projection(fields, contact.ContactColumns, nil))
+	if database.IsNotFound(err) || (err == nil && c.ID == 0) {
+		return contact.Contact{}, ErrContactNotFound
+	}
+	if err != nil {
+		return contact.Contact{}, err
+	}
+	if !contact.WantsDetails(fields) {
+		return c, nil
+	}
+	loaded := []contact.Contact{c}
+	if err := s.repo.LoadDetails(ctx, loaded); err != nil {
+		return contact.Contact{}, err
+	}
+	return loaded[0], nil
 }
 
 func (cs *ContactService) GetContactByID(id uint) (*contact.Contact, error) {
@@ -77,38 +204,64 @@ func (cs *ContactService) GetContactByID(id uint) (*contact.Contact, error) {
     if retrievedContact.ID == 0 {
         return nil, ErrContactNotFound
     }
-    return &retrievedContact, nil
+    loaded := []contact.Contact{retrievedContact}
+    if err := cs.repo.LoadDetails(context.Background(), loaded); err != nil {
+        return nil, err
+    }
+    return &loaded[0], nil
 }
 
-// UpdateContact updates an existing contact with validation
-func (s *ContactService) UpdateContact(ctx context.Context, contact contact.Contact) error {
-	// Validate contact data
-	if err := contact.Validate(); err != nil {
-		return ErrInvalidContact
-	}
-
+// UpdateContact updates an existing contact with validation. Emails, phones
+// or addresses left out of the request are kept; a changed Email or Phone
+// replaces the primary entry.
+func (s *ContactService) UpdateContact(ctx context.Context, contact *contact.Contact) error {
 	// Check if contact exists
-	existingContact, err := s.repo.GetContact(ctx, contact.ID)
+	existingContact, err := s.GetContact(ctx, contact.ID)
 	if err != nil {
 		return err
 	}
-	if existingContact.ID == 0 {
-		return ErrContactNotFound
+
+	// Contacts only reach the trash through DeleteContact
+	contact.DeletedAt = nil
+
+	// Numbers are compared with the stored entries in E.164
+	if err := contact.NormalizePhones(s.PhoneRegion(ctx)); err != nil {
+		return errors.Join(ErrInvalidContact, err)
 	}
 
-	// Check if new email conflicts with another contact
-	if contact.Email.String != existingContact.Email.String {
-		existingContacts, err := s.repo.FindContacts(ctx, bson.M{
-			"email": contact.Email,
-			"id":    bson.M{"$ne": contact.ID},
-		}, 1, 0)
-		if err != nil {
-			return err
+	if contact.Emails == nil {
+		contact.Emails = append(existingContact.Emails[:0:0], existingContact.Emails...)
+		if contact.Email.Valid {
+			contact.SetPrimaryEmail(contact.Email.String)
 		}
-		if len(existingContacts) > 0 {
-			return ErrEmailAlreadyExists
+	}
+	if contact.Phones == nil {
+		contact.Phones = append(existingContact.Phones[:0:0], existingContact.Phones...)
+		if contact.Phone.Valid {
+			contact.SetPrimaryPhone(contact.Phone.String)
 		}
 	}
+	if contact.Addresses == nil {
+		contact.Addresses = append(existingContact.Addresses[:0:0], existingContact.Addresses...)
+	}
+	if err := s.normalize(ctx, contact); err != nil {
+		return err
+	}
+
+	// Validate contact data
+	if err := contact.Validate(); err != nil {
+		return errors.Join(ErrInvalidContact, err)
+	}
+
+	// Referenced type and category must exist
+	if err := s.checkReferences(ctx, *contact); err != nil {
+		return err
+	}
+
+	// Check if any email conflicts with another contact
+	if err := s.checkEmails(ctx, s.repo, contact, []int{contact.ID}); err != nil {
+		return err
+	}
 
 	// Update timestamp
 	contact.UpdatedAt = time.Now()
@@ -116,72 +269,829 @@ func (s *ContactService) UpdateContact(ctx context.Context, contact contact.Cont
 	// Preserve creation timestamp
 	contact.CreatedAt = existingContact.CreatedAt
 
-	return s.repo.UpdateContact(ctx, contact)
+	err = s.repo.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.UpdateContact(ctx, *contact); err != nil {
+			return err
+		}
+		if err := repo.ReplaceDetails(ctx, contact); err != nil {
+			return err
+		}
+		return s.saved(ctx, tx, &existingContact, *contact)
+	})
+	if err != nil {
+		return err
+	}
+	s.changed(ctx, contact.ID)
+	return nil
 }
 
-// DeleteContact removes a contact by ID with validation
+// DeleteContact moves a contact to the trash. Its emails, phones,
+// addresses and related records are kept until it is purged.
 func (s *ContactService) DeleteContact(ctx context.Context, id int) error {
 	// Check if contact exists
 	contact, err := s.repo.GetContact(ctx, id)
+	if database.IsNotFound(err) || (err == nil && contact.ID == 0) {
+		return ErrContactNotFound
+	}
 	if err != nil {
 		return err
 	}
-	if contact.ID == 0 {
-		return ErrContactNotFound
+
+	if err := s.repo.SoftDeleteContact(ctx, id, time.Now()); err != nil {
+		return err
 	}
+	s.changed(ctx, id)
+	return nil
+}
+
+// TrashSort lists the most recently deleted contacts first
+var TrashSort = []database.SortField{{Field: database.DeletedAtField, Desc: true}}
 
-	return s.repo.DeleteContact(ctx, id)
+// Trash lists a page of the contacts in the trash in TrashSort order
+func (s *ContactService) Trash(ctx context.Context, limit, offset int64, cursor *database.Cursor) ([]contact.Contact, PageInfo, error) {
+	if cursor != nil && offset > 0 {
+		return nil, PageInfo{}, errors.Join(ErrInvalidSearch, errors.New("use either a cursor or an offset"))
+	}
+	if limit == 0 {
+		limit = DefaultContactLimit
+	}
+	contacts, err := s.repo.FindDeletedContactsWith(ctx, bson.M{}, database.FindOptions{
+		Limit:  limit + 1,
+		Offset: offset,
+		Sort:   TrashSort,
+		Cursor: cursor,
+	})
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	page := PageInfo{More: database.TrimPage(&contacts, limit, cursor)}
+	if err := s.repo.LoadDetails(ctx, contacts); err != nil {
+		return nil, PageInfo{}, err
+	}
+	if contacts == nil {
+		contacts = []contact.Contact{}
+	}
+	if page.Total, err = s.repo.CountDeletedContacts(ctx, bson.M{}); err != nil {
+		return nil, PageInfo{}, err
+	}
+	return contacts, page, nil
 }
 
-// SearchContacts searches contacts with pagination and filtering
-type SearchContactsParams struct {
-	FirstName   string
-	LastName    string
-	Email       string
-	Phone       string
-	ContactType int64
-	Category    int64
-	Limit       int64
-	Offset      int64
+// RestoreContact takes a contact out of the trash. It fails when its type
+// or category is gone or another contact took one of its emails meanwhile.
+func (s *ContactService) RestoreContact(ctx context.Context, id int) (contact.Contact, error) {
+	trashed, err := s.repo.FindDeletedContacts(ctx, bson.M{"id": id}, 1, 0)
+	if err != nil {
+		return contact.Contact{}, err
+	}
+	if len(trashed) == 0 {
+		return contact.Contact{}, ErrContactNotFound
+	}
+	if err := s.repo.LoadDetails(ctx, trashed); err != nil {
+		return contact.Contact{}, err
+	}
+	c := trashed[0]
+	if err := s.checkReferences(ctx, c); err != nil {
+		return contact.Contact{}, err
+	}
+	if err := s.checkEmails(ctx, s.repo, &c, []int{id}); err != nil {
+		return contact.Contact{}, err
+	}
+
+	if err := s.repo.RestoreContact(ctx, id, time.Now()); err != nil {
+		return contact.Contact{}, err
+	}
+	s.changed(ctx, id)
+	return s.GetContact(ctx, id)
+}
+
+// PurgeDeleted permanently deletes the contacts that went to the trash
+// before the cutoff, together with their details and, through the delete
+// hooks, their related records. It returns the number of contacts purged.
+func (s *ContactService) PurgeDeleted(ctx context.Context, before time.Time) (int, error) {
+	trashed, err := s.repo.FindDeletedContacts(ctx, bson.M{database.DeletedAtField: bson.M{"$lt": before}}, 0, 0)
+	if err != nil {
+		return 0, err
+	}
+	for i, c := range trashed {
+		// One transaction per contact keeps a failure from undoing the rest
+		err := s.repo.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+			repo := s.repo.WithTx(tx)
+			if err := repo.DeleteContact(ctx, c.ID); err != nil {
+				return err
+			}
+			if err := repo.DeleteDetails(ctx, c.ID); err != nil {
+				return err
+			}
+			for _, hook := range s.deleteHooks {
+				if err := hook(ctx, tx, c.ID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return i, fmt.Errorf("purging contact %d: %w", c.ID, err)
+		}
+		s.changed(ctx, c.ID)
+	}
+	return len(trashed), nil
+}
+
+// OnMerge registers a hook that moves related records to the survivor of a merge
+func (s *ContactService) OnMerge(hook MergeHook) {
+	s.mergeHooks = append(s.mergeHooks, hook)
 }
 
-func (s *ContactService) SearchContacts(ctx context.Context, params SearchContactsParams) ([]contact.Contact, error) {
-	// Build filter
+// OnSave registers a hook that runs whenever a contact is created or changed
+func (s *ContactService) OnSave(hook SaveHook) {
+	s.saveHooks = append(s.saveHooks, hook)
+}
+
+// OnDelete registers a hook that removes the records of a purged contact
+func (s *ContactService) OnDelete(hook DeleteHook) {
+	s.deleteHooks = append(s.deleteHooks, hook)
+}
+
+// OnChange registers a hook that is told about every committed change
+func (s *ContactService) OnChange(hook ChangeHook) {
+	s.changeHooks = append(s.changeHooks, hook)
+}
+
+// FindDuplicates scores likely duplicate contacts by name, email local part
+// and phone. With contactID set only pairs including that contact are returned.
+func (s *ContactService) FindDuplicates(ctx context.Context, contactID int, threshold float64, limit int) ([]contact.DuplicatePair, error) {
+	if threshold <= 0 {
+		threshold = DefaultDuplicateThreshold
+	}
+	if contactID != 0 {
+		if _, err := s.GetContact(ctx, contactID); err != nil {
+			return nil, err
+		}
+	}
+
+	contacts, err := s.repo.FindContacts(ctx, bson.M{}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	pairs := contact.FindDuplicates(contacts, threshold)
+
+	if contactID != 0 {
+		filtered := pairs[:0]
+		for _, pair := range pairs {
+			if pair.Contacts[0].ID == contactID || pair.Contacts[1].ID == contactID {
+				filtered = append(filtered, pair)
+			}
+		}
+		pairs = filtered
+	}
+	if limit > 0 && len(pairs) > limit {
+		pairs = pairs[:limit]
+	}
+	return pairs, nil
+}
+
+// MergeContacts merges contacts into the survivor. Fields named in the
+// request come from the chosen contact; other empty survivor fields are
+// filled from the merged contacts. Related records are moved by the merge
+// hooks and the merged contacts are deleted, all in one transaction.
+func (s *ContactService) MergeContacts(ctx context.Context, req MergeContactsRequest) (contact.Contact, error) {
+	if req.SurvivorID == 0 || len(req.MergedIDs) == 0 {
+		return contact.Contact{}, errors.Join(ErrInvalidMerge, errors.New("survivor_id and merged_ids are required"))
+	}
+
+	involved := map[int]*contact.Contact{}
+	survivor, err := s.GetContact(ctx, req.SurvivorID)
+	if err != nil {
+		return contact.Contact{}, err
+	}
+	involved[survivor.ID] = &survivor
+
+	merged := make([]contact.Contact, 0, len(req.MergedIDs))
+	for _, id := range req.MergedIDs {
+		if _, dup := involved[id]; dup {
+			return contact.Contact{}, errors.Join(ErrInvalidMerge, fmt.Errorf("contact %d is listed twice", id))
+		}
+		c, err := s.GetContact(ctx, id)
+		if err != nil {
+			return contact.Contact{}, fmt.Errorf("contact %d: %w", id, err)
+		}
+		merged = append(merged, c)
+		involved[id] = &merged[len(merged)-1]
+	}
+
+	for field := range req.Fields {
+		if !containsString(contact.MergeFields, field) {
+			return contact.Contact{}, errors.Join(ErrInvalidMerge, fmt.Errorf("unknown field %q", field))
+		}
+	}
+
+	// The survivor stays as it was for the save hooks
+	result := survivor
+	result.Emails = append([]contact.ContactEmail{}, survivor.Emails...)
+	result.Phones = append([]contact.ContactPhone{}, survivor.Phones...)
+	result.Addresses = append([]contact.ContactAddress{}, survivor.Addresses...)
+	for i := range merged {
+		result.AbsorbDetails(&merged[i])
+	}
+	for _, field := range contact.MergeFields {
+		if sourceID, ok := req.Fields[field]; ok {
+			source, ok := involved[sourceID]
+			if !ok {
+				return contact.Contact{}, errors.Join(ErrInvalidMerge, fmt.Errorf("field %s: contact %d is not part of the merge", field, sourceID))
+			}
+			result.CopyField(source, field)
+			continue
+		}
+		for i := 0; i < len(merged) && result.IsFieldEmpty(field); i++ {
+			result.CopyField(&merged[i], field)
+		}
+	}
+
+	// The chosen email and phone become the primary entries
+	result.SetPrimaryEmail(result.Email.String)
+	result.SetPrimaryPhone(result.Phone.String)
+	result.NormalizeDetails()
+
+	if err := result.Validate(); err != nil {
+		return contact.Contact{}, errors.Join(ErrInvalidContact, err)
+	}
+	if err := s.checkReferences(ctx, result); err != nil {
+		return contact.Contact{}, err
+	}
+
+	// The kept emails may only clash with contacts outside the merge
+	ids := append([]int{req.SurvivorID}, req.MergedIDs...)
+	if err := s.checkEmails(ctx, s.repo, &result, ids); err != nil {
+		return contact.Contact{}, err
+	}
+
+	result.UpdatedAt = time.Now()
+	err = s.repo.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.UpdateContact(ctx, result); err != nil {
+			return err
+		}
+		for _, id := range req.MergedIDs {
+			if err := repo.DeleteDetails(ctx, id); err != nil {
+				return err
+			}
+		}
+		if err := repo.ReplaceDetails(ctx, &result); err != nil {
+			return err
+		}
+		if err := s.saved(ctx, tx, &survivor, result); err != nil {
+			return err
+		}
+		for _, hook := range s.mergeHooks {
+			if err := hook(ctx, tx, req.SurvivorID, req.MergedIDs); err != nil {
+				return err
+			}
+		}
+		for _, id := range req.MergedIDs {
+			if err := repo.DeleteContact(ctx, id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return contact.Contact{}, err
+	}
+	s.changed(ctx, ids...)
+	return result, nil
+}
+
+// ContactImportReport summarises a contact import
+type ContactImportReport struct {
+	Total     int                   `json:"total"`
+	Valid     int                   `json:"valid"`
+	Imported  []int                 `json:"imported"`
+	Errors    []contact.ImportError `json:"errors"`
+	DryRun    bool                  `json:"dry_run"`
+	Committed bool                  `json:"committed"`
+
+	// Header is the header row of a CSV import, used for the error report
+	Header []string `json:"-"`
+}
+
+// CSVImportOptions controls a CSV import
+type CSVImportOptions struct {
+	// Mapping goes from Contact field to CSV header; empty auto-detects
+	Mapping map[string]string
+	// DryRun validates every row without writing anything
+	DryRun bool
+	// Atomic imports nothing when any row is rejected; otherwise the valid
+	// rows are imported and the rest reported
+	Atomic            bool
+	DefaultTypeID     int
+	DefaultCategoryID int
+}
+
+// errImportRejected rolls back an atomic import that rejected rows
+var errImportRejected = errors.New("import rejected")
+
+// ImportVCards creates a contact for every card of a vCard document. Cards
+// without the X-APIDESIGN type/category properties fall back to the
+// defaults. A card that fails is reported and skipped.
+func (s *ContactService) ImportVCards(ctx context.Context, r io.Reader, defaultTypeID, defaultCategoryID int) (ContactImportReport, error) {
+	cards, errs, err := contact.ParseVCards(r)
+	if err != nil {
+		return ContactImportReport{}, errors.Join(ErrInvalidContact, err)
+	}
+
+	report := ContactImportReport{Total: len(cards) + len(errs), Imported: []int{}, Errors: errs}
+	if report.Errors == nil {
+		report.Errors = []contact.ImportError{}
+	}
+
+	for _, card := range cards {
+		c := card.Contact
+		if !c.ContactTypeID.Valid && defaultTypeID != 0 {
+			c.ContactTypeID = sql.NullInt64{Int64: int64(defaultTypeID), Valid: true}
+		}
+		if !c.CategoryID.Valid && defaultCategoryID != 0 {
+			c.CategoryID = sql.NullInt64{Int64: int64(defaultCategoryID), Valid: true}
+		}
+		c.Sanitize()
+
+		fail := func(err error) {
+			report.Errors = append(report.Errors, contact.ImportError{
+				Index: card.Index,
+				UID:   card.UID,
+				Line:  card.Line,
+				Error: err.Error(),
+			})
+		}
+		if err := s.normalize(ctx, &c); err != nil {
+			fail(err)
+			continue
+		}
+		if err := c.Validate(); err != nil {
+			fail(err)
+			continue
+		}
+		if err := s.CreateContact(ctx, &c); err != nil {
+			if errors.Is(err, ErrInvalidContact) || errors.Is(err, ErrEmailAlreadyExists) {
+				fail(err)
+				continue
+			}
+			return report, err
+		}
+		report.Valid++
+		report.Imported = append(report.Imported, c.ID)
+	}
+	report.Committed = len(report.Imported) > 0
+	return report, nil
+}
+
+// ImportCSV streams contacts from a CSV document. Every row is sanitized and
+// validated; rejected rows are reported with their reason. Rows missing the
+// type or category take the defaults.
+func (s *ContactService) ImportCSV(ctx context.Context, r io.Reader, opts CSVImportOptions) (ContactImportReport, error) {
+	reader, err := contact.NewCSVReader(r, opts.Mapping)
+	if err != nil {
+		return ContactImportReport{}, errors.Join(ErrInvalidContact, err)
+	}
+
+	report := ContactImportReport{
+		Imported: []int{},
+		Errors:   []contact.ImportError{},
+		DryRun:   opts.DryRun,
+		Header:   reader.Header(),
+	}
+
+	// run gets the transaction of an atomic import, nil otherwise
+	run := func(ctx context.Context, tx database.Database) error {
+		repo := s.repo
+		if tx != nil {
+			repo = s.repo.WithTx(tx)
+		}
+		seen := make(map[string]int)
+		references := make(map[[2]int64]error)
+		now := time.Now()
+
+		for {
+			row, err := reader.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return errors.Join(ErrInvalidContact, err)
+			}
+			report.Total++
+
+			reject := func(err error) {
+				report.Errors = append(report.Errors, contact.ImportError{
+					Index:  row.Index,
+					Line:   row.Line,
+					Record: row.Record,
+					Error:  err.Error(),
+				})
+			}
+			if row.Err != nil {
+				reject(row.Err)
+				continue
+			}
+
+			c := row.Contact
+			if !c.ContactTypeID.Valid && opts.DefaultTypeID != 0 {
+				c.ContactTypeID = sql.NullInt64{Int64: int64(opts.DefaultTypeID), Valid: true}
+			}
+			if !c.CategoryID.Valid && opts.DefaultCategoryID != 0 {
+				c.CategoryID = sql.NullInt64{Int64: int64(opts.DefaultCategoryID), Valid: true}
+			}
+			c.Sanitize()
+			if err := s.normalize(ctx, &c); err != nil {
+				reject(err)
+				continue
+			}
+			if err := c.Validate(); err != nil {
+				reject(err)
+				continue
+			}
+
+			// Most rows share a handful of types and categories
+			key := [2]int64{c.ContactTypeID.Int64, c.CategoryID.Int64}
+			refErr, checked := references[key]
+			if !checked {
+				refErr = s.checkReferences(ctx, c)
+				if refErr != nil && !errors.Is(refErr, ErrInvalidContact) {
+					return refErr
+				}
+				references[key] = refErr
+			}
+			if refErr != nil {
+				reject(refErr)
+				continue
+			}
+
+			if line, dup := seen[c.Email.String]; dup {
+				reject(fmt.Errorf("%w: same as line %d", ErrEmailAlreadyExists, line))
+				continue
+			}
+			if err := s.checkEmails(ctx, repo, &c, nil); err != nil {
+				if !errors.Is(err, ErrEmailAlreadyExists) {
+					return err
+				}
+				reject(err)
+				continue
+			}
+			seen[c.Email.String] = row.Line
+			report.Valid++
+
+			// An atomic import keeps validating after the first rejection so
+			// the report lists every bad row
+			if opts.DryRun || (opts.Atomic && len(report.Errors) > 0) {
+				continue
+			}
+			c.CreatedAt = now
+			c.UpdatedAt = now
+			if tx != nil {
+				err = s.insertContact(ctx, tx, &c)
+			} else {
+				err = s.repo.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+					return s.insertContact(ctx, tx, &c)
+				})
+			}
+			if err != nil {
+				return err
+			}
+			report.Imported = append(report.Imported, c.ID)
+		}
+	}
+
+	if opts.DryRun || !opts.Atomic {
+		err = run(ctx, nil)
+	} else {
+		err = s.repo.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+			if err := run(ctx, tx); err != nil {
+				return err
+			}
+			if len(report.Errors) > 0 {
+				return errImportRejected
+			}
+			return nil
+		})
+		if errors.Is(err, errImportRejected) {
+			report.Imported = []int{}
+			err = nil
+		}
+	}
+	// Rows of a non-atomic import stay imported when a later row fails
+	if !opts.DryRun && (err == nil || !opts.Atomic) {
+		s.changed(ctx, report.Imported...)
+	}
+	if err != nil {
+		return report, err
+	}
+	report.Committed = !opts.DryRun && len(report.Imported) > 0
+	return report, nil
+}
+
+// checkEmails rejects emails of c that another contact uses, as its email or
+// one of its email entries. The contacts in exclude are not checked.
+func (s *ContactService) checkEmails(ctx context.Context, repo *contact.ContactRepo, c *contact.Contact, exclude []int) error {
+	emails := c.AllEmails()
+	if len(emails) == 0 {
+		return nil
+	}
+	contactFilter := bson.M{"email": bson.M{"$in": emails}}
+	entryFilter := bson.M{"email": bson.M{"$in": emails}}
+	if len(exclude) > 0 {
+		contactFilter["id"] = bson.M{"$nin": exclude}
+		entryFilter["contact_id"] = bson.M{"$nin": exclude}
+	}
+
+	clashes, err := repo.FindContacts(ctx, contactFilter, 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(clashes) > 0 {
+		return fmt.Errorf("%w: %s is used by contact %d", ErrEmailAlreadyExists, clashes[0].Email.String, clashes[0].ID)
+	}
+	entries, err := repo.FindEmails(ctx, entryFilter)
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+
+	// Contacts in the trash do not hold on to their emails
+	owners := make([]int, len(entries))
+	for i, e := range entries {
+		owners[i] = e.ContactID
+	}
+	live, err := repo.FindContacts(ctx, bson.M{"id": bson.M{"$in": owners}}, 0, 0)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		for _, owner := range live {
+			if owner.ID == e.ContactID {
+				return fmt.Errorf("%w: %s is used by contact %d", ErrEmailAlreadyExists, e.Email, e.ContactID)
+			}
+		}
+	}
+	return nil
+}
+
+// normalize syncs the contact with its primary entries and rewrites its
+// phone numbers to E.164
+func (s *ContactService) normalize(ctx context.Context, c *contact.Contact) error {
+	c.NormalizeDetails()
+	if err := c.NormalizePhones(s.PhoneRegion(ctx)); err != nil {
+		return errors.Join(ErrInvalidContact, err)
+	}
+	return nil
+}
+
+// PhoneRegion is the phone region of the request, or the default region
+func (s *ContactService) PhoneRegion(ctx context.Context) string {
+	if region, ok := ctx.Value(phoneRegionKey{}).(string); ok && region != "" {
+		return region
+	}
+	return s.defaultRegion
+}
+
+// insertContact creates the contact and its emails, phones and addresses
+// inside tx
+func (s *ContactService) insertContact(ctx context.Context, tx database.Database, c *contact.Contact) error {
+	repo := s.repo.WithTx(tx)
+	if err := repo.CreateContact(ctx, c); err != nil {
+		return err
+	}
+	if err := repo.ReplaceDetails(ctx, c); err != nil {
+		return err
+	}
+	return s.saved(ctx, tx, nil, *c)
+}
+
+// changed runs the change hooks for each contact
+func (s *ContactService) changed(ctx context.Context, ids ...int) {
+	for _, id := range ids {
+		for _, hook := range s.changeHooks {
+			hook(ctx, id)
+		}
+	}
+}
+
+// saved runs the save hooks
+func (s *ContactService) saved(ctx context.Context, tx database.Database, before *contact.Contact, after contact.Contact) error {
+	for _, hook := range s.saveHooks {
+		if err := hook(ctx, tx, before, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReferences rejects unknown contact type and category IDs
+func (s *ContactService) checkReferences(ctx context.Context, c contact.Contact) error {
+	if _, err := s.types.GetContactType(ctx, int(c.ContactTypeID.Int64)); err != nil {
+		if errors.Is(err, ErrContactTypeNotFound) {
+			return errors.Join(ErrInvalidContact, fmt.Errorf("contact type %d does not exist", c.ContactTypeID.Int64))
+		}
+		return err
+	}
+	if _, err := s.categories.GetCategory(ctx, int(c.CategoryID.Int64)); err != nil {
+		if errors.Is(err, ErrCategoryNotFound) {
+			return errors.Join(ErrInvalidContact, fmt.Errorf("category %d does not exist", c.CategoryID.Int64))
+		}
+		return err
+	}
+	return nil
+}
+
+// SearchContacts returns a page of the contacts matching the filters
+func (s *ContactService) SearchContacts(ctx context.Context, params contact.SearchContactsParams) ([]contact.Contact, error) {
+	contacts, _, err := s.findContacts(ctx, params, false)
+	return contacts, err
+}
+
+// ListContacts is SearchContacts that also counts every matching contact
+// and tells whether more follow the page
+func (s *ContactService) ListContacts(ctx context.Context, params contact.SearchContactsParams) ([]contact.Contact, PageInfo, error) {
+	return s.findContacts(ctx, params, true)
+}
+
+// findContacts runs a search, counting the matches when count is set
+func (s *ContactService) findContacts(ctx context.Context, params contact.SearchContactsParams, count bool) ([]contact.Contact, PageInfo, error) {
+	if params.Phone != "" {
+		// A complete number matches however it was written
+		if e164, err := phone.Normalize(params.Phone, s.PhoneRegion(ctx)); err == nil {
+			params.Phone = e164
+		}
+	}
+	params.Email = strings.TrimSpace(strings.ToLower(params.Email))
+	if err := contact.ValidateSearchParams(params); err != nil {
+		return nil, PageInfo{}, errors.Join(ErrInvalidSearch, err)
+	}
+
+	filter, ok, err := s.searchFilter(ctx, params)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	if !ok {
+		return []contact.Contact{}, PageInfo{}, nil
+	}
+
+	// Set default limit if not provided
+	if params.Limit == 0 {
+		params.Limit = DefaultContactLimit
+	}
+
+	// One contact more than the page tells whether another page follows
+	contacts, err := s.repo.FindContactsWith(ctx, filter, database.FindOptions{
+		Limit:  params.Limit + 1,
+		Offset: params.Offset,
+		Sort:   params.Sort,
+		Cursor: params.Cursor,
+		Fields: database.Projection(params.Fields, contact.ContactColumns, params.Sort),
+	})
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	page := PageInfo{More: database.TrimPage(&contacts, params.Limit, params.Cursor)}
+	if contact.WantsDetails(params.Fields) {
+		if err := s.repo.LoadDetails(ctx, contacts); err != nil {
+			return nil, PageInfo{}, err
+		}
+	}
+	if contacts == nil {
+		contacts = []contact.Contact{}
+	}
+
+	if count {
+		if page.Total, err = s.repo.CountContacts(ctx, filter); err != nil {
+			return nil, PageInfo{}, err
+		}
+	}
+	return contacts, page, nil
+}
+
+// searchFilter builds the filter of a search. It reports false when no
+// contact can match, e.g. when no contact carries the tags.
+func (s *ContactService) searchFilter(ctx context.Context, params contact.SearchContactsParams) (bson.M, bool, error) {
 	filter := bson.M{}
+	var ids []int // contacts the filters are restricted to, nil for any
+	restrict := func(matching []int) {
+		if ids == nil {
+			ids = matching
+			return
+		}
+		keep := make(map[int]bool, len(matching))
+		for _, id := range matching {
+			keep[id] = true
+		}
+		narrowed := []int{}
+		for _, id := range ids {
+			if keep[id] {
+				narrowed = append(narrowed, id)
+			}
+		}
+		ids = narrowed
+	}
 
 	if params.FirstName != "" {
-		filter["first_name"] = bson.M{"$regex": params.FirstName, "$options": "i"}
+		filter["first_name"] = bson.M{"$regex": regexp.QuoteMeta(params.FirstName), "$options": "i"}
 	}
 	if params.LastName != "" {
-		filter["last_name"] = bson.M{"$regex": params.LastName, "$options": "i"}
+		filter["last_name"] = bson.M{"$regex": regexp.QuoteMeta(params.LastName), "$options": "i"}
 	}
 	if params.Email != "" {
-		filter["email"] = bson.M{"$regex": params.Email, "$options": "i"}
+		// Match the primary email or any other email entry
+		entries, err := s.repo.FindEmails(ctx, bson.M{"email": params.Email})
+		if err != nil {
+			return nil, false, err
+		}
+		matching := make([]int, 0, len(entries))
+		for _, e := range entries {
+			matching = append(matching, e.ContactID)
+		}
+		primary, err := s.repo.FindContacts(ctx, bson.M{"email": params.Email}, 0, 0)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, c := range primary {
+			matching = append(matching, c.ID)
+		}
+		restrict(matching)
 	}
 	if params.Phone != "" {
-		filter["phone"] = bson.M{"$regex": params.Phone, "$options": "i"}
+		// Match the primary phone or any other phone entry
+		entries, err := s.repo.FindPhones(ctx, bson.M{"number": params.Phone})
+		if err != nil {
+			return nil, false, err
+		}
+		matching := make([]int, 0, len(entries))
+		for _, p := range entries {
+			matching = append(matching, p.ContactID)
+		}
+		primary, err := s.repo.FindContacts(ctx, bson.M{"phone": params.Phone}, 0, 0)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, c := range primary {
+			matching = append(matching, c.ID)
+		}
+		restrict(matching)
 	}
 	if params.ContactType != 0 {
 		filter["contact_type_id"] = params.ContactType
 	}
 	if params.Category != 0 {
 		filter["category_id"] = params.Category
+		if params.IncludeSubcategories {
+			subtree, err := s.categories.SubtreeIDs(ctx, int(params.Category))
+			if err != nil {
+				return nil, false, err
+			}
+			filter["category_id"] = bson.M{"$in": subtree}
+		}
 	}
-
-	// Set default limit if not provided
-	if params.Limit == 0 {
-		params.Limit = 10
+	if len(params.Tags) > 0 {
+		tagged, err := s.tags.ContactIDs(ctx, params.Tags, params.TagMatch)
+		if err != nil {
+			return nil, false, err
+		}
+		restrict(tagged)
 	}
 
-	return s.repo.FindContacts(ctx, filter, params.Limit, params.Offset)
+	if ids != nil {
+		if len(ids) == 0 {
+			return nil, false, nil
+		}
+		filter["id"] = bson.M{"$in": ids}
+	}
+	return filter, true, nil
 }
 
 // BulkCreateContacts creates multiple contacts in a single operation
 func (s *ContactService) BulkCreateContacts(ctx context.Context, contacts []contact.Contact) error {
+	for i := range contacts {
+		if err := s.normalize(ctx, &contacts[i]); err != nil {
+			return err
+		}
+	}
 	for _, contact := range contacts {
 		if err := contact.Validate(); err != nil {
 			return ErrInvalidContact
 		}
+		if err := s.checkReferences(ctx, contact); err != nil {
+			return err
+		}
 	}
 
 	// Check for duplicate emails in the batch
@@ -217,12 +1127,20 @@ func (s *ContactService) BulkCreateContacts(ctx context.Context, contacts []cont
 	}
 
 	// Create all contacts
-	for _, contact := range contacts {
-		if err := s.repo.CreateContact(ctx, contact); err != nil {
-			return err
+	err := s.repo.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+		for i := range contacts {
+			if err := s.insertContact(ctx, tx, &contacts[i]); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, c := range contacts {
+		s.changed(ctx, c.ID)
 	}
-
 	return nil
 }
 
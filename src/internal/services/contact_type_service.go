@@ -0,0 +1,116 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"time"
+
+	"apidesign/internal/contact"
+	"apidesign/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Service errors
+var (
+	ErrContactTypeNotFound = errors.New("contact type not found")
+	ErrInvalidContactType  = errors.New("invalid contact type data")
+	ErrContactTypeExists   = errors.New("contact type name already exists")
+	ErrContactTypeInUse    = errors.New("contact type is still used by contacts")
+)
+
+// ContactTypeService handles business logic for contact types
+type ContactTypeService struct {
+	repo     *contact.ContactTypeRepo
+	contacts *contact.ContactRepo
+}
+
+// NewContactTypeService creates a new instance of ContactTypeService
+func NewContactTypeService(repo *contact.ContactTypeRepo, contacts *contact.ContactRepo) *ContactTypeService {
+	return &ContactTypeService{
+		repo:     repo,
+		contacts: contacts,
+	}
+}
+
+// CreateContactType creates a new contact type with a unique name
+func (s *ContactTypeService) CreateContactType(ctx context.Context, t *contact.ContactType) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if err := t.Validate(); err != nil {
+		return errors.Join(ErrInvalidContactType, err)
+	}
+	if err := s.checkName(ctx, t); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	return s.repo.CreateContactType(ctx, t)
+}
+
+// GetContactType retrieves a contact type by ID
+func (s *ContactTypeService) GetContactType(ctx context.Context, id int) (contact.ContactType, error) {
+	t, err := s.repo.GetContactType(ctx, id)
+	if database.IsNotFound(err) || (err == nil && t.ID == 0) {
+		return contact.ContactType{}, ErrContactTypeNotFound
+	}
+	return t, err
+}
+
+// UpdateContactType updates an existing contact type
+func (s *ContactTypeService) UpdateContactType(ctx context.Context, t *contact.ContactType) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if err := t.Validate(); err != nil {
+		return errors.Join(ErrInvalidContactType, err)
+	}
+	existing, err := s.GetContactType(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkName(ctx, t); err != nil {
+		return err
+	}
+
+	t.CreatedAt = existing.CreatedAt
+	t.UpdatedAt = time.Now()
+	return s.repo.UpdateContactType(ctx, t)
+}
+
+// DeleteContactType removes a contact type that no contact refers to
+func (s *ContactTypeService) DeleteContactType(ctx context.Context, id int) error {
+	if _, err := s.GetContactType(ctx, id); err != nil {
+		return err
+	}
+	contacts, err := s.contacts.FindContacts(ctx, bson.M{"contact_type_id": id}, 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(contacts) > 0 {
+		return ErrContactTypeInUse
+	}
+	return s.repo.DeleteContactType(ctx, id)
+}
+
+// ListContactTypes lists contact types with pagination
+func (s *ContactTypeService) ListContactTypes(ctx context.Context, limit int64, offset int64) ([]contact.ContactType, error) {
+	return s.repo.FindContactTypes(ctx, bson.M{}, limit, offset)
+}
+
+// checkName refuses a name already used by another contact type
+func (s *ContactTypeService) checkName(ctx context.Context, t *contact.ContactType) error {
+	filter := bson.M{"name": bson.M{"$regex": "^" + regexp.QuoteMeta(t.Name) + "$", "$options": "i"}}
+	if t.ID != 0 {
+		filter["id"] = bson.M{"$ne": t.ID}
+	}
+	existing, err := s.repo.FindContactTypes(ctx, filter, 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return ErrContactTypeExists
+	}
+	return nil
+}
@@ -0,0 +1,808 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"apidesign/internal/database"
+	"apidesign/internal/event"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Service errors
+var (
+	ErrEventNotFound      = errors.New("event not found")
+	ErrInvalidEvent       = errors.New("invalid event data")
+	ErrOccurrenceNotFound = errors.New("occurrence not found")
+)
+
+// DefaultEventLimit is the page size of an event list when none is given
+const DefaultEventLimit = 10
+
+// EventService handles business logic for events
+type EventService struct {
+	repo       *event.EventRepo
+	categories *EventCategoryService
+	attendees  *event.AttendeeRepo
+	states     *event.StateMachine
+}
+
+// NewEventService creates a new instance of EventService
+func NewEventService(repo *event.EventRepo, categories *EventCategoryService, attendees *event.AttendeeRepo) *EventService {
+	return &EventService{
+		repo:       repo,
+		categories: categories,
+		attendees:  attendees,
+		states:     event.NewStateMachine(),
+	}
+}
+
+// States exposes the lifecycle state machine so callers can add guards and hooks
+func (s *EventService) States() *event.StateMachine {
+	return s.states
+}
+
+// CreateEvent creates a new event with validation.
+// Events start as draft; creating one as published runs the publish guards.
+// Overlapping events are refused with a ConflictError unless allowConflicts is set.
+func (s *EventService) CreateEvent(ctx context.Context, e *event.Event, allowConflicts bool) error {
+	return s.createEvent(ctx, e, allowConflicts, false)
+}
+
+// createEvent creates an event. Imported events may also be created as
+// completed; they pass through publish without its guards.
+func (s *EventService) createEvent(ctx context.Context, e *event.Event, allowConflicts bool, imported bool) error {
+	status := e.Status
+	e.Status = event.EventStatusDraft
+	e.BeforeCreate()
+	if err := e.Validate(); err != nil {
+		return errors.Join(ErrInvalidEvent, err)
+	}
+
+	var transitions []event.EventTransition
+	if status != "" && status != event.EventStatusDraft {
+		if !event.IsValidStatus(status) {
+			return errors.Join(ErrInvalidEvent, fmt.Errorf("invalid status %q", status))
+		}
+		apply := s.states.Apply
+		var actions []string
+		if imported && status == event.EventStatusCompleted {
+			apply = s.states.ApplyUnguarded
+			actions = []string{event.ActionPublish, event.ActionComplete}
+		} else {
+			action, ok := s.states.ActionFor(event.EventStatusDraft, status)
+			if !ok {
+				return &event.TransitionError{Action: "create", From: status}
+			}
+			actions = []string{action}
+		}
+		for _, action := range actions {
+			t, err := apply(e, action)
+			if err != nil {
+				return err
+			}
+			transitions = append(transitions, t)
+		}
+	}
+
+	if !allowConflicts {
+		if err := s.checkConflicts(ctx, e); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.CreateEvent(ctx, e); err != nil {
+		return err
+	}
+
+	for i := range transitions {
+		transitions[i].EventID = e.ID
+		if err := s.repo.CreateTransition(ctx, &transitions[i]); err != nil {
+			return err
+		}
+		s.states.RunHooks(ctx, e, transitions[i])
+	}
+	return nil
+}
+
+// GetEvent retrieves an event by ID with error handling
+func (s *EventService) GetEvent(ctx context.Context, id int) (event.Event, error) {
+	retrievedEvent, err := s.repo.GetEvent(ctx, id)
+	if database.IsNotFound(err) {
+		return event.Event{}, ErrEventNotFound
+	}
+	if err != nil {
+		return event.Event{}, err
+	}
+
+	if retrievedEvent.ID == 0 {
+		return event.Event{}, ErrEventNotFound
+	}
+
+	return retrievedEvent, nil
+}
+
+// UpdateEvent updates an existing event with validation.
+// Overlapping events are refused with a ConflictError unless allowConflicts is set.
+func (s *EventService) UpdateEvent(ctx context.Context, e *event.Event, allowConflicts bool) error {
+	// Check if event exists
+	existingEvent, err := s.GetEvent(ctx, e.ID)
+	if err != nil {
+		return err
+	}
+
+	// Status only changes through the transition endpoints
+	if e.Status == "" {
+		e.Status = existingEvent.Status
+	}
+	if e.Status != existingEvent.Status {
+		return &event.TransitionError{
+			Action: "update",
+			From:   existingEvent.Status,
+			Reason: "use the publish, cancel or complete endpoints to change status",
+		}
+	}
+	if err := e.Validate(); err != nil {
+		return errors.Join(ErrInvalidEvent, err)
+	}
+	if !allowConflicts {
+		if err := s.checkConflicts(ctx, e); err != nil {
+			return err
+		}
+	}
+
+	// Preserve creation timestamp
+	e.CreatedAt = existingEvent.CreatedAt
+	e.BeforeUpdate()
+
+	return s.repo.UpdateEvent(ctx, e)
+}
+
+// DeleteEvent removes an event by ID with validation
+func (s *EventService) DeleteEvent(ctx context.Context, id int) error {
+	if _, err := s.GetEvent(ctx, id); err != nil {
+		return err
+	}
+
+	return s.repo.DeleteEvent(ctx, id)
+}
+
+// TransitionEvent applies a lifecycle action (publish, cancel, complete) to an event
+// and records it in the transition history
+func (s *EventService) TransitionEvent(ctx context.Context, id int, action string, reason string) (event.Event, error) {
+	var e event.Event
+	var transition event.EventTransition
+
+	// The event is re-read under its lock, so concurrent transitions check
+	// their guards one after another and the history matches the status
+	err := s.repo.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+		repo := s.repo.WithTx(tx)
+		if err := repo.LockEvent(ctx, id); err != nil {
+			return err
+		}
+
+		var err error
+		if e, err = getEvent(ctx, repo, id); err != nil {
+			return err
+		}
+		if transition, err = s.states.Apply(&e, action); err != nil {
+			return err
+		}
+		transition.Reason = reason
+
+		e.BeforeUpdate()
+		if err := repo.UpdateEvent(ctx, &e); err != nil {
+			return err
+		}
+		return repo.CreateTransition(ctx, &transition)
+	})
+	if err != nil {
+		return event.Event{}, err
+	}
+
+	s.states.RunHooks(ctx, &e, transition)
+	return e, nil
+}
+
+// FindConflicts returns the events overlapping e that share its category,
+// venue or attendees. Canceled events never conflict.
+func (s *EventService) FindConflicts(ctx context.Context, e *event.Event) ([]event.Conflict, error) {
+	conflicts := []event.Conflict{}
+	if e.Status == event.EventStatusCanceled {
+		return conflicts, nil
+	}
+
+	from, to := e.ConflictWindow()
+	var ownExceptions []event.EventException
+	if e.ID != 0 && e.IsRecurring() {
+		var err error
+		if ownExceptions, err = s.repo.FindExceptions(ctx, []int{e.ID}); err != nil {
+			return nil, err
+		}
+	}
+	own, err := e.Occurrences(from, to, ownExceptions)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidEvent, err)
+	}
+	if len(own) == 0 {
+		return conflicts, nil
+	}
+
+	filter := buildEventFilter(event.ListEventsParams{From: from, To: to})
+	filter["status"] = bson.M{"$ne": event.EventStatusCanceled}
+	if e.ID != 0 {
+		filter["id"] = bson.M{"$ne": e.ID}
+	}
+	candidates, err := s.repo.FindEvents(ctx, filter, 0, 0)
+	if err != nil || len(candidates) == 0 {
+		return conflicts, err
+	}
+
+	ids := make([]int, 0, len(candidates)+1)
+	for _, c := range candidates {
+		ids = append(ids, c.ID)
+	}
+	if e.ID != 0 {
+		ids = append(ids, e.ID)
+	}
+	contacts, err := s.attendingContacts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := s.EventExceptions(ctx, candidates)
+	if err != nil {
+		return nil, err
+	}
+	byEvent := make(map[int][]event.EventException)
+	for _, ex := range exceptions {
+		byEvent[ex.EventID] = append(byEvent[ex.EventID], ex)
+	}
+
+	for _, c := range candidates {
+		var reasons []string
+		if c.CategoryID == e.CategoryID {
+			reasons = append(reasons, event.ConflictCategory)
+		}
+		if e.SharesVenue(&c) {
+			reasons = append(reasons, event.ConflictVenue)
+		}
+		shared := sharedContacts(contacts[e.ID], contacts[c.ID])
+		if len(shared) > 0 {
+			reasons = append(reasons, event.ConflictAttendee)
+		}
+		if len(reasons) == 0 {
+			continue
+		}
+
+		theirs, err := c.Occurrences(from, to, byEvent[c.ID])
+		if err != nil {
+			// A stored series that no longer parses cannot be compared
+			continue
+		}
+		occ, ok := event.FirstOverlap(own, theirs)
+		if !ok {
+			continue
+		}
+		conflicts = append(conflicts, event.Conflict{
+			EventID:    c.ID,
+			Title:      occ.Title,
+			StartDate:  occ.StartDate,
+			EndDate:    occ.EndDate,
+			Reasons:    reasons,
+			ContactIDs: shared,
+		})
+	}
+	return conflicts, nil
+}
+
+// checkConflicts refuses e when it overlaps another event
+func (s *EventService) checkConflicts(ctx context.Context, e *event.Event) error {
+	conflicts, err := s.FindConflicts(ctx, e)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return &event.ConflictError{Conflicts: conflicts}
+	}
+	return nil
+}
+
+// attendingContacts maps event IDs to the contacts expected to attend them;
+// declined and waitlisted attendees do not take part in the event
+func (s *EventService) attendingContacts(ctx context.Context, eventIDs []int) (map[int][]int, error) {
+	attendees, err := s.attendees.FindAttendees(ctx, bson.M{
+		"event_id":    bson.M{"$in": eventIDs},
+		"rsvp_status": bson.M{"$nin": []string{event.RSVPDeclined, event.RSVPWaitlisted}},
+	}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	contacts := make(map[int][]int)
+	for _, a := range attendees {
+		contacts[a.EventID] = append(contacts[a.EventID], a.ContactID)
+	}
+	return contacts, nil
+}
+
+// sharedContacts returns the contact IDs present in both lists, sorted
+func sharedContacts(a, b []int) []int {
+	if len(a) == 0 || len(b) == 0 {
+		return nil
+	}
+	inA := make(map[int]bool, len(a))
+	for _, id := range a {
+		inA[id] = true
+	}
+	var shared []int
+	for _, id := range b {
+		if inA[id] {
+			shared = append(shared, id)
+			inA[id] = false
+		}
+	}
+	sort.Ints(shared)
+	return shared
+}
+
+// Calendar groups the occurrences of matching events into the day buckets
+// of the view containing date, in loc. Limit and offset are ignored.
+func (s *EventService) Calendar(ctx context.Context, params event.ListEventsParams, view string, date time.Time, loc *time.Location) (event.CalendarView, error) {
+	from, to, err := event.CalendarRange(view, date, loc)
+	if err != nil {
+		return event.CalendarView{}, errors.Join(ErrInvalidEvent, err)
+	}
+	params.From, params.To = from, to
+	params.Limit, params.Offset = 0, 0
+	if err := params.Validate(); err != nil {
+		return event.CalendarView{}, errors.Join(ErrInvalidEvent, err)
+	}
+
+	filter, err := s.eventFilter(ctx, params)
+	if err != nil {
+		return event.CalendarView{}, err
+	}
+	events, err := s.repo.FindEvents(ctx, filter, 0, 0)
+	if err != nil {
+		return event.CalendarView{}, err
+	}
+	occurrences, err := s.expand(ctx, events, from, to)
+	if err != nil {
+		return event.CalendarView{}, err
+	}
+	return event.BuildCalendar(view, from, to, occurrences), nil
+}
+
+// FreeBusy computes the merged busy intervals of the given contacts and
+// categories. Canceled events and declined or waitlisted attendees are free.
+func (s *EventService) FreeBusy(ctx context.Context, params event.FreeBusyParams) (event.FreeBusy, error) {
+	if err := params.Validate(); err != nil {
+		return event.FreeBusy{}, errors.Join(ErrInvalidEvent, err)
+	}
+
+	result := event.FreeBusy{From: params.From, To: params.To}
+	var all []event.Occurrence
+
+	if len(params.ContactIDs) > 0 {
+		attendees, err := s.attendees.FindAttendees(ctx, bson.M{
+			"contact_id":  bson.M{"$in": params.ContactIDs},
+			"rsvp_status": bson.M{"$nin": []string{event.RSVPDeclined, event.RSVPWaitlisted}},
+		}, 0, 0)
+		if err != nil {
+			return event.FreeBusy{}, err
+		}
+		eventIDs := make([]int, 0, len(attendees))
+		for _, a := range attendees {
+			eventIDs = append(eventIDs, a.EventID)
+		}
+		byEvent := make(map[int][]event.Occurrence)
+		if len(eventIDs) > 0 {
+			if byEvent, err = s.busyOccurrences(ctx, bson.M{"id": bson.M{"$in": eventIDs}}, params.From, params.To); err != nil {
+				return event.FreeBusy{}, err
+			}
+		}
+
+		byContact := make(map[int][]event.Occurrence)
+		for _, a := range attendees {
+			byContact[a.ContactID] = append(byContact[a.ContactID], byEvent[a.EventID]...)
+		}
+		result.Contacts = make(map[int][]event.BusyInterval, len(params.ContactIDs))
+		for _, id := range params.ContactIDs {
+			result.Contacts[id] = event.MergeBusy(byContact[id], params.From, params.To)
+			all = append(all, byContact[id]...)
+		}
+	}
+
+	if len(params.CategoryIDs) > 0 {
+		result.Categories = make(map[int][]event.BusyInterval, len(params.CategoryIDs))
+		for _, id := range params.CategoryIDs {
+			categoryIDs := []int{id}
+			if params.IncludeSubcategories {
+				var err error
+				if categoryIDs, err = s.categories.SubtreeIDs(ctx, id); err != nil {
+					return event.FreeBusy{}, err
+				}
+			}
+			byEvent, err := s.busyOccurrences(ctx, bson.M{"category_id": bson.M{"$in": categoryIDs}}, params.From, params.To)
+			if err != nil {
+				return event.FreeBusy{}, err
+			}
+			var occurrences []event.Occurrence
+			for _, occ := range byEvent {
+				occurrences = append(occurrences, occ...)
+			}
+			result.Categories[id] = event.MergeBusy(occurrences, params.From, params.To)
+			all = append(all, occurrences...)
+		}
+	}
+
+	result.Busy = event.MergeBusy(all, params.From, params.To)
+	return result, nil
+}
+
+// busyOccurrences expands the non-canceled events matching filter within
+// [from, to), grouped by event ID
+func (s *EventService) busyOccurrences(ctx context.Context, filter bson.M, from, to time.Time) (map[int][]event.Occurrence, error) {
+	window := buildEventFilter(event.ListEventsParams{From: from, To: to})
+	for key, value := range window {
+		filter[key] = value
+	}
+	filter["status"] = bson.M{"$ne": event.EventStatusCanceled}
+
+	events, err := s.repo.FindEvents(ctx, filter, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	occurrences, err := s.expand(ctx, events, from, to)
+	if err != nil {
+		return nil, err
+	}
+	byEvent := make(map[int][]event.Occurrence)
+	for _, occ := range occurrences {
+		byEvent[occ.EventID] = append(byEvent[occ.EventID], occ)
+	}
+	return byEvent, nil
+}
+
+// GetEventFields retrieves an event reading only the given fields, see
+// event.EventColumns; no fields read the whole event
+func (s *EventService) GetEventFields(ctx context.Context, id int, fields []string) (event.Event, error) {
+	for _, f := range fields {
+		if !event.IsColumn(f) {
+			return event.Event{}, errors.Join(ErrInvalidEvent, fmt.Errorf("unknown field %q", f))
+		}
+	}
+	e, err := s.repo.GetEventWith(ctx, id, database.Projection(fields, event.EventColumns, nil, "time_zone"))
+	if database.IsNotFound(err) || (err == nil && e.ID == 0) {
+		return event.Event{}, ErrEventNotFound
+	}
+	return e, err
+}
+
+// GetTransitions retrieves the status history of an event
+func (s *EventService) GetTransitions(ctx context.Context, id int, limit int64, offset int64) ([]event.EventTransition, error) {
+	if _, err := s.GetEvent(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.repo.FindTransitions(ctx, id, limit, offset)
+}
+
+// ListEvents lists events with pagination and filtering
+func (s *EventService) ListEvents(ctx context.Context, params event.ListEventsParams) ([]event.Event, PageInfo, error) {
+	if err := params.Validate(); err != nil {
+		return nil, PageInfo{}, errors.Join(ErrInvalidEvent, err)
+	}
+
+	// Set default limit if not provided
+	if params.Limit == 0 {
+		params.Limit = DefaultEventLimit
+	}
+
+	filter, err := s.eventFilter(ctx, params)
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	// One event more than the page tells whether another page follows
+	events, err := s.repo.FindEventsWith(ctx, filter, database.FindOptions{
+		Limit:  params.Limit + 1,
+		Offset: params.Offset,
+		Sort:   params.Sort,
+		Cursor: params.Cursor,
+		// Times are shown in the event's zone unless the request picks one
+		Fields: database.Projection(params.Fields, event.EventColumns, params.Sort, "time_zone"),
+	})
+	if err != nil {
+		return nil, PageInfo{}, err
+	}
+	page := PageInfo{More: database.TrimPage(&events, params.Limit, params.Cursor)}
+	if page.Total, err = s.repo.CountEvents(ctx, filter); err != nil {
+		return nil, PageInfo{}, err
+	}
+	return events, page, nil
+}
+
+// ListOccurrences expands a single event into its occurrences within [from, to)
+func (s *EventService) ListOccurrences(ctx context.Context, id int, from, to time.Time) ([]event.Occurrence, error) {
+	if err := validateWindow(from, to); err != nil {
+		return nil, err
+	}
+
+	e, err := s.GetEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	exceptions, err := s.repo.FindExceptions(ctx, []int{e.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	occurrences, err := e.Occurrences(from, to, exceptions)
+	if err != nil {
+		return nil, errors.Join(ErrInvalidEvent, err)
+	}
+	return occurrences, nil
+}
+
+// ExpandEvents lists the occurrences of every matching event within the
+// params' From/To window, sorted by start
+func (s *EventService) ExpandEvents(ctx context.Context, params event.ListEventsParams) ([]event.Occurrence, error) {
+	if err := validateWindow(params.From, params.To); err != nil {
+		return nil, err
+	}
+	if err := params.Validate(); err != nil {
+		return nil, errors.Join(ErrInvalidEvent, err)
+	}
+
+	// Limit applies to series, not occurrences
+	if params.Limit == 0 {
+		params.Limit = 100
+	}
+
+	filter, err := s.eventFilter(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	events, err := s.repo.FindEvents(ctx, filter, params.Limit, params.Offset)
+	if err != nil {
+		return nil, err
+	}
+	return s.expand(ctx, events, params.From, params.To)
+}
+
+// expand turns events into occurrences within [from, to), sorted by start
+func (s *EventService) expand(ctx context.Context, events []event.Event, from, to time.Time) ([]event.Occurrence, error) {
+	exceptions, err := s.EventExceptions(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+	byEvent := make(map[int][]event.EventException)
+	for _, ex := range exceptions {
+		byEvent[ex.EventID] = append(byEvent[ex.EventID], ex)
+	}
+
+	occurrences := []event.Occurrence{}
+	for _, e := range events {
+		expanded, err := e.Occurrences(from, to, byEvent[e.ID])
+		if err != nil {
+			return nil, errors.Join(ErrInvalidEvent, fmt.Errorf("event %d: %w", e.ID, err))
+		}
+		occurrences = append(occurrences, expanded...)
+	}
+
+	sort.SliceStable(occurrences, func(i, j int) bool {
+		return occurrences[i].StartDate.Before(occurrences[j].StartDate)
+	})
+	return occurrences, nil
+}
+
+// UpdateOccurrence edits a single occurrence of a recurring event without
+// affecting the rest of the series
+func (s *EventService) UpdateOccurrence(ctx context.Context, id int, recurrenceID time.Time, override event.EventException) (event.EventException, error) {
+	if _, err := s.getOccurrence(ctx, id, recurrenceID); err != nil {
+		return event.EventException{}, err
+	}
+
+	if override.StartDate != nil && override.EndDate != nil && override.EndDate.Before(*override.StartDate) {
+		return event.EventException{}, errors.Join(ErrInvalidEvent, errors.New("end date must be after start date"))
+	}
+
+	now := time.Now()
+	override.ID = 0
+	override.EventID = id
+	override.RecurrenceID = recurrenceID
+	override.Canceled = false
+	override.CreatedAt = now
+	override.UpdatedAt = now
+
+	if err := s.repo.SaveException(ctx, &override); err != nil {
+		return event.EventException{}, err
+	}
+	return override, nil
+}
+
+// CancelOccurrence cancels a single occurrence of a recurring event
+func (s *EventService) CancelOccurrence(ctx context.Context, id int, recurrenceID time.Time) error {
+	if _, err := s.getOccurrence(ctx, id, recurrenceID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	return s.repo.SaveException(ctx, &event.EventException{
+		EventID:      id,
+		RecurrenceID: recurrenceID,
+		Canceled:     true,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	})
+}
+
+// getOccurrence checks that recurrenceID is an instance of a recurring event
+func (s *EventService) getOccurrence(ctx context.Context, id int, recurrenceID time.Time) (event.Event, error) {
+	e, err := s.GetEvent(ctx, id)
+	if err != nil {
+		return event.Event{}, err
+	}
+	if !e.IsRecurring() {
+		return event.Event{}, errors.Join(ErrInvalidEvent, errors.New("event is not recurring"))
+	}
+
+	ok, err := e.IsOccurrence(recurrenceID)
+	if err != nil {
+		return event.Event{}, errors.Join(ErrInvalidEvent, err)
+	}
+	if !ok {
+		return event.Event{}, ErrOccurrenceNotFound
+	}
+	return e, nil
+}
+
+// ImportReport summarises an iCalendar import
+type ImportReport struct {
+	Imported []int            `json:"imported"`
+	Errors   []event.ICSError `json:"errors"`
+	// Error is set when the import stopped early; the events listed in
+	// Imported were created before it did
+	Error string `json:"error,omitempty"`
+}
+
+// ImportEvents creates an event for every VEVENT in an iCalendar document.
+// Components without the X-APIDESIGN type/category properties fall back to
+// the defaults. A component that fails is reported and skipped. Confirmed
+// events that are already over are imported as completed. When the import
+// stops early the partial report is returned with the error.
+func (s *EventService) ImportEvents(ctx context.Context, r io.Reader, defaultTypeID, defaultCategoryID int) (ImportReport, error) {
+	parsed, errs, err := event.ParseICS(r)
+	if err != nil {
+		return ImportReport{}, errors.Join(ErrInvalidEvent, err)
+	}
+
+	report := ImportReport{Imported: []int{}, Errors: errs}
+	if report.Errors == nil {
+		report.Errors = []event.ICSError{}
+	}
+
+	now := time.Now()
+	for _, item := range parsed {
+		e := item.Event
+		if e.EventTypeID == 0 {
+			e.EventTypeID = defaultTypeID
+		}
+		if e.CategoryID == 0 {
+			e.CategoryID = defaultCategoryID
+		}
+
+		if item.UID != "" {
+			existing, err := s.repo.GetEventByUID(ctx, item.UID)
+			if err != nil && !database.IsNotFound(err) {
+				return report.sorted(), err
+			}
+			if err == nil && existing.ID != 0 {
+				report.Errors = append(report.Errors, event.ICSError{
+					Index: item.Index,
+					UID:   item.UID,
+					Error: fmt.Sprintf("already imported as event %d", existing.ID),
+				})
+				continue
+			}
+		}
+
+		// A confirmed event that is over could no longer be published
+		if e.Status == event.EventStatusPublished && !e.EndDate.After(now) {
+			e.Status = event.EventStatusCompleted
+		}
+
+		// Imported calendars are taken as they are, overlaps included
+		if err := s.createEvent(ctx, &e, true, true); err != nil {
+			report.Errors = append(report.Errors, event.ICSError{Index: item.Index, UID: item.UID, Error: err.Error()})
+			continue
+		}
+		for _, ex := range item.Exceptions {
+			ex.EventID = e.ID
+			ex.CreatedAt = e.CreatedAt
+			ex.UpdatedAt = e.UpdatedAt
+			if err := s.repo.SaveException(ctx, &ex); err != nil {
+				// The event itself was created
+				report.Imported = append(report.Imported, e.ID)
+				return report.sorted(), err
+			}
+		}
+		report.Imported = append(report.Imported, e.ID)
+	}
+	return report.sorted(), nil
+}
+
+// sorted orders the errors by component
+func (r ImportReport) sorted() ImportReport {
+	sort.Slice(r.Errors, func(i, j int) bool { return r.Errors[i].Index < r.Errors[j].Index })
+	return r
+}
+
+// EventExceptions retrieves the occurrence exceptions of the given events
+func (s *EventService) EventExceptions(ctx context.Context, events []event.Event) ([]event.EventException, error) {
+	ids := make([]int, 0, len(events))
+	for _, e := range events {
+		if e.IsRecurring() {
+			ids = append(ids, e.ID)
+		}
+	}
+	return s.repo.FindExceptions(ctx, ids)
+}
+
+// eventFilter builds the list filter, widening the category to its subtree
+// when requested
+func (s *EventService) eventFilter(ctx context.Context, params event.ListEventsParams) (bson.M, error) {
+	filter := buildEventFilter(params)
+	if params.CategoryID != 0 && params.IncludeSubcategories {
+		ids, err := s.categories.SubtreeIDs(ctx, params.CategoryID)
+		if err != nil {
+			return nil, err
+		}
+		filter["category_id"] = bson.M{"$in": ids}
+	}
+	return filter, nil
+}
+
+// buildEventFilter translates list params into a repository filter
+func buildEventFilter(params event.ListEventsParams) bson.M {
+	filter := bson.M{}
+
+	if params.EventTypeID != 0 {
+		filter["event_type_id"] = params.EventTypeID
+	}
+	if params.CategoryID != 0 {
+		filter["category_id"] = params.CategoryID
+	}
+	if params.Status != "" {
+		filter["status"] = params.Status
+	}
+
+	// Date range matches every event overlapping [From, To]; recurring series
+	// can have occurrences long after their first one ends
+	if !params.From.IsZero() {
+		filter["$or"] = []bson.M{
+			{"end_date": bson.M{"$gte": params.From}},
+			{"rrule": bson.M{"$gt": ""}},
+			{"rdate": bson.M{"$gt": ""}},
+		}
+	}
+	if !params.To.IsZero() {
+		filter["start_date"] = bson.M{"$lte": params.To}
+	}
+
+	return filter
+}
+
+// validateWindow checks a required [from, to) expansion window
+func validateWindow(from, to time.Time) error {
+	if from.IsZero() || to.IsZero() {
+		return errors.Join(ErrInvalidEvent, errors.New("from and to are required"))
+	}
+	if !to.After(from) {
+		return errors.Join(ErrInvalidEvent, errors.New("to must be after from"))
+	}
+	return nil
+}
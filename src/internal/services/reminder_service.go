@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"apidesign/internal/database"
+	"apidesign/internal/event"
+	"apidesign/internal/reminder"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Service errors
+var (
+	ErrReminderNotFound = errors.New("reminder not found")
+	ErrInvalidReminder  = errors.New("invalid reminder data")
+)
+
+// ReminderService manages the reminder rules of events
+type ReminderService struct {
+	repo      *reminder.Repo
+	events    *event.EventRepo
+	scheduler *reminder.Scheduler
+}
+
+// NewReminderService creates a new instance of ReminderService
+func NewReminderService(repo *reminder.Repo, events *event.EventRepo, scheduler *reminder.Scheduler) *ReminderService {
+	return &ReminderService{
+		repo:      repo,
+		events:    events,
+		scheduler: scheduler,
+	}
+}
+
+// AddReminder adds a reminder rule to an event and schedules its jobs
+func (s *ReminderService) AddReminder(ctx context.Context, rule *reminder.Rule) error {
+	if _, err := getEvent(ctx, s.events, rule.EventID); err != nil {
+		return err
+	}
+	if err := rule.Validate(); err != nil {
+		return errors.Join(ErrInvalidReminder, err)
+	}
+
+	existing, err := s.repo.FindRules(ctx, bson.M{"event_id": rule.EventID, "minutes_before": rule.MinutesBefore}, 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		*rule = existing[0]
+		return nil
+	}
+
+	rule.CreatedAt = time.Now()
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return err
+	}
+	return s.scheduler.Schedule(ctx, rule.EventID)
+}
+
+// ListReminders lists the reminder rules of an event
+func (s *ReminderService) ListReminders(ctx context.Context, eventID int) ([]reminder.Rule, error) {
+	if _, err := getEvent(ctx, s.events, eventID); err != nil {
+		return nil, err
+	}
+	return s.repo.FindRules(ctx, bson.M{"event_id": eventID}, 0, 0)
+}
+
+// RemoveReminder removes a reminder rule and cancels its pending jobs
+func (s *ReminderService) RemoveReminder(ctx context.Context, eventID int, id int) error {
+	rule, err := s.repo.GetRule(ctx, eventID, id)
+	if database.IsNotFound(err) || (err == nil && rule.ID == 0) {
+		return ErrReminderNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.repo.DeleteRule(ctx, id); err != nil {
+		return err
+	}
+	return s.scheduler.Schedule(ctx, eventID)
+}
+
+// ListJobs lists the scheduled and fired reminders of an event
+func (s *ReminderService) ListJobs(ctx context.Context, eventID int, status string, limit int64, offset int64) ([]reminder.Job, error) {
+	if _, err := getEvent(ctx, s.events, eventID); err != nil {
+		return nil, err
+	}
+	filter := bson.M{"event_id": eventID}
+	if status != "" {
+		filter["status"] = status
+	}
+	return s.repo.FindJobs(ctx, filter, limit, offset)
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"apidesign/internal/contact"
+	"apidesign/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Service errors
+var (
+	ErrRevisionNotFound  = errors.New("revision not found")
+	ErrRestoreIncomplete = errors.New("contact does not match the restored revision")
+)
+
+// RevisionDiff lists the fields changed between two revisions
+type RevisionDiff struct {
+	From    int                   `json:"from"`
+	To      int                   `json:"to"`
+	Changes []contact.FieldChange `json:"changes"`
+}
+
+// RevisionService keeps the history of contact changes
+type RevisionService struct {
+	repo     *contact.RevisionRepo
+	contacts *ContactService
+	// actor returns the user making the change of a request context
+	actor func(ctx context.Context) string
+}
+
+// NewRevisionService creates a new instance of RevisionService
+func NewRevisionService(repo *contact.RevisionRepo, contacts *ContactService, actor func(ctx context.Context) string) *RevisionService {
+	return &RevisionService{
+		repo:     repo,
+		contacts: contacts,
+		actor:    actor,
+	}
+}
+
+// Record is a contact save hook that stores the new state as the next
+// revision. Contacts saved before revisions were kept get their previous
+// state recorded first, so the change itself shows up in the history.
+func (s *RevisionService) Record(ctx context.Context, tx database.Database, before *contact.Contact, after contact.Contact) error {
+	repo := s.repo.WithTx(tx)
+	if err := repo.LockContact(ctx, after.ID); err != nil {
+		return err
+	}
+	top, err := repo.LatestRevision(ctx, after.ID)
+	if err != nil {
+		return err
+	}
+	latest := top.Revision
+
+	if latest == 0 && before != nil {
+		baseline, err := contact.NewContactRevision(*before, 1, "", before.UpdatedAt)
+		if err != nil {
+			return err
+		}
+		if err := repo.CreateRevision(ctx, &baseline); err != nil {
+			return err
+		}
+		latest = 1
+	}
+
+	revision, err := contact.NewContactRevision(after, latest+1, s.actor(ctx), time.Now())
+	if err != nil {
+		return err
+	}
+	return repo.CreateRevision(ctx, &revision)
+}
+
+// Revisions returns the history of a contact, newest first
+func (s *RevisionService) Revisions(ctx context.Context, contactID int) ([]contact.ContactRevision, error) {
+	if _, err := s.contacts.GetContact(ctx, contactID); err != nil {
+		return nil, err
+	}
+	revisions, err := s.repo.FindRevisions(ctx, bson.M{"contact_id": contactID})
+	if err != nil {
+		return nil, err
+	}
+	for i := range revisions {
+		if err := revisions[i].Decode(); err != nil {
+			return nil, err
+		}
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision > revisions[j].Revision
+	})
+	return revisions, nil
+}
+
+// GetRevision returns revision rev of a contact
+func (s *RevisionService) GetRevision(ctx context.Context, contactID, rev int) (contact.ContactRevision, error) {
+	if _, err := s.contacts.GetContact(ctx, contactID); err != nil {
+		return contact.ContactRevision{}, err
+	}
+	revision, err := s.repo.GetRevision(ctx, contactID, rev)
+	if database.IsNotFound(err) || (err == nil && revision.ID == 0) {
+		return contact.ContactRevision{}, ErrRevisionNotFound
+	}
+	if err != nil {
+		return contact.ContactRevision{}, err
+	}
+	if err := revision.Decode(); err != nil {
+		return contact.ContactRevision{}, err
+	}
+	return revision, nil
+}
+
+// Diff lists the fields that changed from revision from to revision to
+func (s *RevisionService) Diff(ctx context.Context, contactID, from, to int) (RevisionDiff, error) {
+	a, err := s.GetRevision(ctx, contactID, from)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	b, err := s.GetRevision(ctx, contactID, to)
+	if err != nil {
+		return RevisionDiff{}, err
+	}
+	return RevisionDiff{From: from, To: to, Changes: contact.DiffContacts(a.Contact, b.Contact)}, nil
+}
+
+// Restore rolls a contact back to revision rev. The rollback is an update
+// like any other and is recorded as a new revision.
+func (s *RevisionService) Restore(ctx context.Context, contactID, rev int) (contact.Contact, error) {
+	revision, err := s.GetRevision(ctx, contactID, rev)
+	if err != nil {
+		return contact.Contact{}, err
+	}
+	c := *revision.Contact
+	c.ID = contactID
+	if err := s.contacts.UpdateContact(ctx, &c); err != nil {
+		return contact.Contact{}, err
+	}
+	restored, err := s.contacts.GetContact(ctx, contactID)
+	if err != nil {
+		return contact.Contact{}, err
+	}
+
+	// Cleared fields must be cleared in the stored contact too, or the new
+	// revision would not match it
+	if changes := contact.DiffFields(revision.Contact, &restored, contact.MergeFields); len(changes) > 0 {
+		fields := make([]string, len(changes))
+		for i, change := range changes {
+			fields[i] = change.Field
+		}
+		return restored, fmt.Errorf("%w: %s", ErrRestoreIncomplete, strings.Join(fields, ", "))
+	}
+	return restored, nil
+}
+
+// RemoveMerged is a contact merge hook that drops the history of the merged
+// contacts; the survivor's history records the merge
+func (s *RevisionService) RemoveMerged(ctx context.Context, tx database.Database, survivorID int, mergedIDs []int) error {
+	return s.repo.WithTx(tx).DeleteRevisions(ctx, bson.M{"contact_id": bson.M{"$in": mergedIDs}})
+}
+
+// RemoveContact is a contact delete hook that drops the contact's history
+func (s *RevisionService) RemoveContact(ctx context.Context, tx database.Database, contactID int) error {
+	return s.repo.WithTx(tx).DeleteRevisions(ctx, bson.M{"contact_id": contactID})
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"unicode"
+
+	"apidesign/internal/contact"
+	"apidesign/internal/database"
+	"apidesign/internal/phone"
+	"apidesign/internal/search"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Service errors
+var (
+	ErrInvalidSearch = errors.New("invalid search")
+)
+
+// DefaultSearchLimit is the page size when none is given
+const DefaultSearchLimit = 20
+
+// SearchHit is a matching contact and its relevance score
+type SearchHit struct {
+	Contact contact.Contact `json:"contact"`
+	Score   float64         `json:"score"`
+}
+
+// SearchResult is a page of search hits, best first
+type SearchResult struct {
+	Total int         `json:"total"`
+	Hits  []SearchHit `json:"hits"`
+}
+
+// SearchService keeps the search index in step with the contacts and
+// answers full-text queries. The index holds names, emails, phones, tags
+// and notes; the contacts themselves are read from the database.
+type SearchService struct {
+	index    search.Index
+	contacts *contact.ContactRepo
+	tags     *contact.TagRepo
+	timeline *contact.TimelineRepo
+	// region returns the phone region of a request context
+	region func(ctx context.Context) string
+}
+
+// NewSearchService creates a new instance of SearchService
+func NewSearchService(index search.Index, contacts *contact.ContactRepo, tags *contact.TagRepo, timeline *contact.TimelineRepo, region func(ctx context.Context) string) *SearchService {
+	return &SearchService{
+		index:    index,
+		contacts: contacts,
+		tags:     tags,
+		timeline: timeline,
+		region:   region,
+	}
+}
+
+// Search returns a page of the contacts matching the query, best first
+func (s *SearchService) Search(ctx context.Context, text string, limit, offset int) (SearchResult, error) {
+	if limit < 0 || offset < 0 {
+		return SearchResult{}, errors.Join(ErrInvalidSearch, errors.New("limit and offset must be non-negative"))
+	}
+	if limit == 0 {
+		limit = DefaultSearchLimit
+	}
+	// A complete phone number matches however it was written
+	if e164, err := phone.Normalize(text, s.region(ctx)); err == nil {
+		text = e164
+	}
+
+	result, err := s.index.Search(search.Query{Text: text, Limit: limit, Offset: offset})
+	if errors.Is(err, search.ErrEmptyQuery) {
+		return SearchResult{}, errors.Join(ErrInvalidSearch, err)
+	}
+	if err != nil {
+		return SearchResult{}, err
+	}
+
+	found := SearchResult{Total: result.Total, Hits: []SearchHit{}}
+	if len(result.Hits) == 0 {
+		return found, nil
+	}
+	ids := make([]int, len(result.Hits))
+	for i, hit := range result.Hits {
+		ids[i] = hit.ID
+	}
+	contacts, err := s.contacts.FindContacts(ctx, bson.M{"id": bson.M{"$in": ids}}, 0, 0)
+	if err != nil {
+		return SearchResult{}, err
+	}
+	if err := s.contacts.LoadDetails(ctx, contacts); err != nil {
+		return SearchResult{}, err
+	}
+	byID := make(map[int]contact.Contact, len(contacts))
+	for _, c := range contacts {
+		byID[c.ID] = c
+	}
+	for _, hit := range result.Hits {
+		// A hit can outlive its contact until the index catches up
+		if c, ok := byID[hit.ID]; ok {
+			found.Hits = append(found.Hits, SearchHit{Contact: c, Score: hit.Score})
+		}
+	}
+	return found, nil
+}
+
+// Reindex is a change hook that refreshes the contact in the index, or
+// drops it when the contact is trashed or gone
+func (s *SearchService) Reindex(ctx context.Context, contactID int) {
+	if err := s.reindex(ctx, contactID); err != nil {
+		log.Printf("Search: indexing contact %d: %v", contactID, err)
+	}
+}
+
+// Rebuild indexes every live contact
+func (s *SearchService) Rebuild(ctx context.Context) error {
+	contacts, err := s.contacts.FindContacts(ctx, bson.M{}, 0, 0)
+	if err != nil {
+		return err
+	}
+	if err := s.contacts.LoadDetails(ctx, contacts); err != nil {
+		return err
+	}
+
+	tags, err := s.tags.FindTags(ctx, bson.M{}, 0, 0)
+	if err != nil {
+		return err
+	}
+	tagNames := make(map[int]string, len(tags))
+	for _, t := range tags {
+		tagNames[t.ID] = t.Name
+	}
+	links, err := s.tags.FindContactTags(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	contactTags := make(map[int][]string)
+	for _, link := range links {
+		if name, ok := tagNames[link.TagID]; ok {
+			contactTags[link.ContactID] = append(contactTags[link.ContactID], name)
+		}
+	}
+
+	notes, err := s.timeline.FindEntries(ctx, bson.M{"kind": contact.TimelineNote})
+	if err != nil {
+		return err
+	}
+	contactNotes := make(map[int][]contact.TimelineEntry)
+	for _, n := range notes {
+		contactNotes[n.ContactID] = append(contactNotes[n.ContactID], n)
+	}
+
+	for _, c := range contacts {
+		if err := s.index.Put(contactDocument(c, contactTags[c.ID], contactNotes[c.ID])); err != nil {
+			return err
+		}
+	}
+	log.Printf("Search: indexed %d contacts", len(contacts))
+	return nil
+}
+
+func (s *SearchService) reindex(ctx context.Context, contactID int) error {
+	c, err := s.contacts.GetContact(ctx, contactID)
+	if database.IsNotFound(err) || (err == nil && c.ID == 0) {
+		return s.index.Delete(contactID)
+	}
+	if err != nil {
+		return err
+	}
+	loaded := []contact.Contact{c}
+	if err := s.contacts.LoadDetails(ctx, loaded); err != nil {
+		return err
+	}
+
+	links, err := s.tags.FindContactTags(ctx, bson.M{"contact_id": contactID})
+	if err != nil {
+		return err
+	}
+	var names []string
+	if len(links) > 0 {
+		ids := make([]int, len(links))
+		for i, link := range links {
+			ids[i] = link.TagID
+		}
+		tags, err := s.tags.FindTags(ctx, bson.M{"id": bson.M{"$in": ids}}, 0, 0)
+		if err != nil {
+			return err
+		}
+		for _, t := range tags {
+			names = append(names, t.Name)
+		}
+	}
+
+	notes, err := s.timeline.FindEntries(ctx, bson.M{"contact_id": contactID, "kind": contact.TimelineNote})
+	if err != nil {
+		return err
+	}
+	return s.index.Put(contactDocument(loaded[0], names, notes))
+}
+
+// contactDocument collects the searchable text of a contact. Phone numbers
+// are indexed in E.164 and in national form, with and without separators.
+func contactDocument(c contact.Contact, tags []string, notes []contact.TimelineEntry) search.Document {
+	doc := search.Document{ID: c.ID, Fields: map[string][]string{
+		search.FieldName:  {c.FirstName.String, c.LastName.String},
+		search.FieldEmail: c.AllEmails(),
+		search.FieldTags:  tags,
+	}}
+
+	numbers := []string{c.Phone.String}
+	for _, p := range c.Phones {
+		numbers = append(numbers, p.Number)
+	}
+	seen := make(map[string]bool)
+	for _, number := range numbers {
+		if number == "" || seen[number] {
+			continue
+		}
+		seen[number] = true
+		doc.Fields[search.FieldPhone] = append(doc.Fields[search.FieldPhone], number)
+		if n, err := phone.Parse(number, ""); err == nil {
+			national := n.National()
+			doc.Fields[search.FieldPhone] = append(doc.Fields[search.FieldPhone], national, digits(national))
+		}
+	}
+
+	for _, n := range notes {
+		doc.Fields[search.FieldNotes] = append(doc.Fields[search.FieldNotes], n.Subject, n.Body)
+	}
+	return doc
+}
+
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
@@ -0,0 +1,406 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"apidesign/internal/contact"
+	"apidesign/internal/database"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Service errors
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrInvalidTag  = errors.New("invalid tag data")
+	ErrTagExists   = errors.New("tag already exists")
+)
+
+// How SearchContacts matches a set of tags
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+)
+
+// TagService handles business logic for contact tags
+type TagService struct {
+	repo        *contact.TagRepo
+	contacts    *contact.ContactRepo
+	changeHooks []ChangeHook
+}
+
+// NewTagService creates a new instance of TagService
+func NewTagService(repo *contact.TagRepo, contacts *contact.ContactRepo) *TagService {
+	return &TagService{
+		repo:     repo,
+		contacts: contacts,
+	}
+}
+
+// CreateTag creates a curated tag
+func (s *TagService) CreateTag(ctx context.Context, t *contact.Tag) error {
+	t.Name = contact.NormalizeTagName(t.Name)
+	t.Curated = true
+	if err := t.Validate(); err != nil {
+		return errors.Join(ErrInvalidTag, err)
+	}
+	if err := s.checkName(ctx, t); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	t.CreatedAt = now
+	t.UpdatedAt = now
+	return s.repo.CreateTag(ctx, t)
+}
+
+// GetTag retrieves a tag by ID
+func (s *TagService) GetTag(ctx context.Context, id int) (contact.Tag, error) {
+	t, err := s.repo.GetTag(ctx, id)
+	if database.IsNotFound(err) || (err == nil && t.ID == 0) {
+		return contact.Tag{}, ErrTagNotFound
+	}
+	return t, err
+}
+
+// UpdateTag renames a tag or changes whether it is curated
+func (s *TagService) UpdateTag(ctx context.Context, t *contact.Tag) error {
+	t.Name = contact.NormalizeTagName(t.Name)
+	if err := t.Validate(); err != nil {
+		return errors.Join(ErrInvalidTag, err)
+	}
+	existing, err := s.GetTag(ctx, t.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkName(ctx, t); err != nil {
+		return err
+	}
+
+	t.CreatedAt = existing.CreatedAt
+	t.UpdatedAt = time.Now()
+	if err := s.repo.UpdateTag(ctx, t); err != nil {
+		return err
+	}
+	if existing.Name != t.Name {
+		return s.changedTag(ctx, t.ID)
+	}
+	return nil
+}
+
+// DeleteTag removes a tag from every contact and deletes it
+func (s *TagService) DeleteTag(ctx context.Context, id int) error {
+	if _, err := s.GetTag(ctx, id); err != nil {
+		return err
+	}
+	links, err := s.repo.FindContactTags(ctx, bson.M{"tag_id": id})
+	if err != nil {
+		return err
+	}
+	err = s.repo.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+		return s.repo.WithTx(tx).DeleteTag(ctx, id)
+	})
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		s.changed(ctx, link.ContactID)
+	}
+	return nil
+}
+
+// OnChange registers a hook that is told about contacts whose tags changed
+func (s *TagService) OnChange(hook ChangeHook) {
+	s.changeHooks = append(s.changeHooks, hook)
+}
+
+// ListTags returns every tag with the number of contacts carrying it, most
+// used first. curated filters on the kind of tag when set.
+func (s *TagService) ListTags(ctx context.Context, curated *bool) ([]contact.TagUsage, error) {
+	filter := bson.M{}
+	if curated != nil {
+		filter["curated"] = *curated
+	}
+	tags, err := s.repo.FindTags(ctx, filter, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	links, err := s.repo.FindContactTags(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Contacts in the trash do not count
+	linked := make([]int, 0, len(links))
+	for _, link := range links {
+		linked = append(linked, link.ContactID)
+	}
+	live := make(map[int]bool)
+	if len(linked) > 0 {
+		contacts, err := s.contacts.FindContacts(ctx, bson.M{"id": bson.M{"$in": linked}}, 0, 0)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range contacts {
+			live[c.ID] = true
+		}
+	}
+
+	counts := make(map[int]int)
+	for _, link := range links {
+		if live[link.ContactID] {
+			counts[link.TagID]++
+		}
+	}
+	usage := make([]contact.TagUsage, 0, len(tags))
+	for _, t := range tags {
+		usage = append(usage, contact.TagUsage{Tag: t, Count: counts[t.ID]})
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Count != usage[j].Count {
+			return usage[i].Count > usage[j].Count
+		}
+		return usage[i].Name < usage[j].Name
+	})
+	return usage, nil
+}
+
+// ContactTags returns the tags of a contact sorted by name
+func (s *TagService) ContactTags(ctx context.Context, contactID int) ([]contact.Tag, error) {
+	if err := s.checkContact(ctx, contactID); err != nil {
+		return nil, err
+	}
+	links, err := s.repo.FindContactTags(ctx, bson.M{"contact_id": contactID})
+	if err != nil {
+		return nil, err
+	}
+	return s.linkedTags(ctx, links)
+}
+
+// SetContactTags replaces the tags of a contact. Names that match no tag
+// create free-form tags.
+func (s *TagService) SetContactTags(ctx context.Context, contactID int, names []string) ([]contact.Tag, error) {
+	if err := s.checkContact(ctx, contactID); err != nil {
+		return nil, err
+	}
+
+	wanted := make([]string, 0, len(names))
+	seen := make(map[string]bool)
+	for _, name := range names {
+		t := contact.Tag{Name: contact.NormalizeTagName(name)}
+		if err := t.Validate(); err != nil {
+			return nil, errors.Join(ErrInvalidTag, fmt.Errorf("%q: %w", name, err))
+		}
+		if !seen[t.Name] {
+			seen[t.Name] = true
+			wanted = append(wanted, t.Name)
+		}
+	}
+
+	var tags []contact.Tag
+	err := s.repo.Transaction(ctx, func(ctx context.Context, tx database.Database) error {
+		repo := s.repo.WithTx(tx)
+		existing, err := repo.FindTags(ctx, bson.M{"name": bson.M{"$in": wanted}}, 0, 0)
+		if err != nil {
+			return err
+		}
+		byName := make(map[string]contact.Tag)
+		for _, t := range existing {
+			byName[t.Name] = t
+		}
+
+		now := time.Now()
+		keep := make(map[int]bool)
+		for _, name := range wanted {
+			t, ok := byName[name]
+			if !ok {
+				t = contact.Tag{Name: name}
+				t.CreatedAt = now
+				t.UpdatedAt = now
+				if err := repo.CreateTag(ctx, &t); err != nil {
+					return err
+				}
+			}
+			keep[t.ID] = true
+			tags = append(tags, t)
+		}
+
+		links, err := repo.FindContactTags(ctx, bson.M{"contact_id": contactID})
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			if keep[link.TagID] {
+				delete(keep, link.TagID)
+				continue
+			}
+			if err := repo.DeleteContactTags(ctx, bson.M{"contact_id": contactID, "tag_id": link.TagID}); err != nil {
+				return err
+			}
+		}
+		for tagID := range keep {
+			link := contact.ContactTag{ContactID: contactID, TagID: tagID, CreatedAt: now}
+			if err := repo.AddContactTag(ctx, &link); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.changed(ctx, contactID)
+
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	if tags == nil {
+		tags = []contact.Tag{}
+	}
+	return tags, nil
+}
+
+// ContactIDs returns the contacts carrying any (TagMatchAny) or all
+// (TagMatchAll) of the named tags
+func (s *TagService) ContactIDs(ctx context.Context, names []string, match string) ([]int, error) {
+	if match == "" {
+		match = TagMatchAny
+	}
+	if match != TagMatchAny && match != TagMatchAll {
+		return nil, errors.Join(ErrInvalidTag, fmt.Errorf("tag match must be %s or %s", TagMatchAny, TagMatchAll))
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		if name = contact.NormalizeTagName(name); name != "" {
+			wanted[name] = true
+		}
+	}
+	list := make([]string, 0, len(wanted))
+	for name := range wanted {
+		list = append(list, name)
+	}
+	tags, err := s.repo.FindTags(ctx, bson.M{"name": bson.M{"$in": list}}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	// An unknown tag can never be matched by every contact
+	if len(tags) == 0 || (match == TagMatchAll && len(tags) < len(wanted)) {
+		return []int{}, nil
+	}
+
+	tagIDs := make([]int, len(tags))
+	for i, t := range tags {
+		tagIDs[i] = t.ID
+	}
+	links, err := s.repo.FindContactTags(ctx, bson.M{"tag_id": bson.M{"$in": tagIDs}})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[int]int)
+	for _, link := range links {
+		counts[link.ContactID]++
+	}
+	ids := []int{}
+	for id, count := range counts {
+		if match == TagMatchAny || count == len(tagIDs) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// ReassignContacts is a contact merge hook that moves the tags of the merged
+// contacts to the survivor
+func (s *TagService) ReassignContacts(ctx context.Context, tx database.Database, survivorID int, mergedIDs []int) error {
+	repo := s.repo.WithTx(tx)
+	links, err := repo.FindContactTags(ctx, bson.M{"contact_id": bson.M{"$in": append([]int{survivorID}, mergedIDs...)}})
+	if err != nil {
+		return err
+	}
+
+	has := make(map[int]bool)
+	for _, link := range links {
+		if link.ContactID == survivorID {
+			has[link.TagID] = true
+		}
+	}
+	if err := repo.DeleteContactTags(ctx, bson.M{"contact_id": bson.M{"$in": mergedIDs}}); err != nil {
+		return err
+	}
+	for _, link := range links {
+		if has[link.TagID] {
+			continue
+		}
+		has[link.TagID] = true
+		moved := contact.ContactTag{ContactID: survivorID, TagID: link.TagID, CreatedAt: link.CreatedAt}
+		if err := repo.AddContactTag(ctx, &moved); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveContact is a contact delete hook that drops the tag links of the contact
+func (s *TagService) RemoveContact(ctx context.Context, tx database.Database, contactID int) error {
+	return s.repo.WithTx(tx).DeleteContactTags(ctx, bson.M{"contact_id": contactID})
+}
+
+func (s *TagService) linkedTags(ctx context.Context, links []contact.ContactTag) ([]contact.Tag, error) {
+	if len(links) == 0 {
+		return []contact.Tag{}, nil
+	}
+	ids := make([]int, len(links))
+	for i, link := range links {
+		ids[i] = link.TagID
+	}
+	tags, err := s.repo.FindTags(ctx, bson.M{"id": bson.M{"$in": ids}}, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i].Name < tags[j].Name })
+	return tags, nil
+}
+
+// changedTag runs the change hooks for every contact carrying the tag
+func (s *TagService) changedTag(ctx context.Context, tagID int) error {
+	links, err := s.repo.FindContactTags(ctx, bson.M{"tag_id": tagID})
+	if err != nil {
+		return err
+	}
+	for _, link := range links {
+		s.changed(ctx, link.ContactID)
+	}
+	return nil
+}
+
+// changed runs the change hooks for a contact
+func (s *TagService) changed(ctx context.Context, contactID int) {
+	for _, hook := range s.changeHooks {
+		hook(ctx, contactID)
+	}
+}
+
+// checkName rejects a name already used by another tag
+func (s *TagService) checkName(ctx context.Context, t *contact.Tag) error {
+	existing, err := s.repo.FindTags(ctx, bson.M{"name": t.Name}, 1, 0)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 && existing[0].ID != t.ID {
+		return ErrTagExists
+	}
+	return nil
+}
+
+func (s *TagService) checkContact(ctx context.Context, id int) error {
+	c, err := s.contacts.GetContact(ctx, id)
+	if database.IsNotFound(err) || (err == nil && c.ID == 0) {
+		return ErrContactNotFound
+	}
+	return err
+}
@@ -0,0 +1,234 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"apidesign/internal/contact"
+	"apidesign/internal/database"
+	"apidesign/internal/event"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Service errors
+var (
+	ErrTimelineEntryNotFound = errors.New("timeline entry not found")
+	ErrInvalidTimelineEntry  = errors.New("invalid timeline entry")
+	ErrTimelineForbidden     = errors.New("only the author can change a timeline entry")
+	ErrNoAuthor              = errors.New("an authenticated subject is required")
+)
+
+// DefaultTimelineLimit is the page size when none is given
+const DefaultTimelineLimit = 20
+
+// TimelineService handles notes, calls, meetings and event participation
+// recorded against contacts
+type TimelineService struct {
+	repo        *contact.TimelineRepo
+	contacts    *contact.ContactRepo
+	events      *event.EventRepo
+	changeHooks []ChangeHook
+}
+
+// NewTimelineService creates a new instance of TimelineService
+func NewTimelineService(repo *contact.TimelineRepo, contacts *contact.ContactRepo, events *event.EventRepo) *TimelineService {
+	return &TimelineService{
+		repo:     repo,
+		contacts: contacts,
+		events:   events,
+	}
+}
+
+// AddEntry records an entry on the contact's timeline, authored by author.
+// OccurredAt defaults to now.
+func (s *TimelineService) AddEntry(ctx context.Context, contactID int, author string, entry *contact.TimelineEntry) error {
+	if author == "" {
+		return ErrNoAuthor
+	}
+	if err := s.checkContact(ctx, contactID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	entry.ID = 0
+	entry.ContactID = contactID
+	entry.Author = author
+	if entry.OccurredAt.IsZero() {
+		entry.OccurredAt = now
+	}
+	if err := s.validate(ctx, entry); err != nil {
+		return err
+	}
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	if err := s.repo.CreateEntry(ctx, entry); err != nil {
+		return err
+	}
+	s.changed(ctx, contactID)
+	return nil
+}
+
+// GetEntry retrieves an entry of the contact's timeline
+func (s *TimelineService) GetEntry(ctx context.Context, contactID, id int) (contact.TimelineEntry, error) {
+	entry, err := s.repo.GetEntry(ctx, id)
+	if database.IsNotFound(err) || (err == nil && (entry.ID == 0 || entry.ContactID != contactID)) {
+		return contact.TimelineEntry{}, ErrTimelineEntryNotFound
+	}
+	return entry, err
+}
+
+// UpdateEntry changes an entry; only its author may do so
+func (s *TimelineService) UpdateEntry(ctx context.Context, contactID int, author string, entry *contact.TimelineEntry) error {
+	if author == "" {
+		return ErrNoAuthor
+	}
+	existing, err := s.GetEntry(ctx, contactID, entry.ID)
+	if err != nil {
+		return err
+	}
+	if existing.Author != author {
+		return ErrTimelineForbidden
+	}
+
+	entry.ContactID = contactID
+	entry.Author = existing.Author
+	if entry.OccurredAt.IsZero() {
+		entry.OccurredAt = existing.OccurredAt
+	}
+	if err := s.validate(ctx, entry); err != nil {
+		return err
+	}
+	entry.CreatedAt = existing.CreatedAt
+	entry.UpdatedAt = time.Now()
+	if err := s.repo.UpdateEntry(ctx, entry); err != nil {
+		return err
+	}
+	s.changed(ctx, contactID)
+	return nil
+}
+
+// DeleteEntry removes an entry; only its author may do so
+func (s *TimelineService) DeleteEntry(ctx context.Context, contactID int, author string, id int) error {
+	if author == "" {
+		return ErrNoAuthor
+	}
+	existing, err := s.GetEntry(ctx, contactID, id)
+	if err != nil {
+		return err
+	}
+	if existing.Author != author {
+		return ErrTimelineForbidden
+	}
+	if err := s.repo.DeleteEntries(ctx, bson.M{"id": id}); err != nil {
+		return err
+	}
+	s.changed(ctx, contactID)
+	return nil
+}
+
+// OnChange registers a hook that is told about contacts whose timeline changed
+func (s *TimelineService) OnChange(hook ChangeHook) {
+	s.changeHooks = append(s.changeHooks, hook)
+}
+
+// Timeline returns a page of the contact's entries in chronological order,
+// newest first unless params.Order asks for the oldest
+func (s *TimelineService) Timeline(ctx context.Context, contactID int, params contact.TimelineParams) ([]contact.TimelineEntry, error) {
+	if err := params.Validate(); err != nil {
+		return nil, errors.Join(ErrInvalidTimelineEntry, err)
+	}
+	if err := s.checkContact(ctx, contactID); err != nil {
+		return nil, err
+	}
+
+	filter := bson.M{"contact_id": contactID}
+	if params.Kind != "" {
+		filter["kind"] = params.Kind
+	}
+	occurred := bson.M{}
+	if !params.From.IsZero() {
+		occurred["$gte"] = params.From
+	}
+	if !params.To.IsZero() {
+		occurred["$lt"] = params.To
+	}
+	if len(occurred) > 0 {
+		filter["occurred_at"] = occurred
+	}
+
+	limit := params.Limit
+	if limit == 0 {
+		limit = DefaultTimelineLimit
+	}
+	desc := params.Order != contact.TimelineOldestFirst
+	entries, err := s.repo.FindEntriesWith(ctx, filter, database.FindOptions{
+		Limit:  limit,
+		Offset: params.Offset,
+		Sort: []database.SortField{
+			{Field: "occurred_at", Desc: desc},
+			{Field: "id", Desc: desc},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []contact.TimelineEntry{}
+	}
+	return entries, nil
+}
+
+// ReassignContacts is a contact merge hook that moves the timelines of the
+// merged contacts to the survivor
+func (s *TimelineService) ReassignContacts(ctx context.Context, tx database.Database, survivorID int, mergedIDs []int) error {
+	repo := s.repo.WithTx(tx)
+	entries, err := repo.FindEntries(ctx, bson.M{"contact_id": bson.M{"$in": mergedIDs}})
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		entry.ContactID = survivorID
+		if err := repo.UpdateEntry(ctx, &entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveContact is a contact delete hook that drops the contact's timeline
+func (s *TimelineService) RemoveContact(ctx context.Context, tx database.Database, contactID int) error {
+	return s.repo.WithTx(tx).DeleteEntries(ctx, bson.M{"contact_id": contactID})
+}
+
+// validate checks the entry and the event it refers to
+func (s *TimelineService) validate(ctx context.Context, entry *contact.TimelineEntry) error {
+	if err := entry.Validate(); err != nil {
+		return errors.Join(ErrInvalidTimelineEntry, err)
+	}
+	if entry.EventID == 0 {
+		return nil
+	}
+	e, err := s.events.GetEvent(ctx, entry.EventID)
+	if database.IsNotFound(err) || (err == nil && e.ID == 0) {
+		return errors.Join(ErrInvalidTimelineEntry, fmt.Errorf("event %d does not exist", entry.EventID))
+	}
+	return err
+}
+
+// changed runs the change hooks for a contact
+func (s *TimelineService) changed(ctx context.Context, contactID int) {
+	for _, hook := range s.changeHooks {
+		hook(ctx, contactID)
+	}
+}
+
+func (s *TimelineService) checkContact(ctx context.Context, id int) error {
+	c, err := s.contacts.GetContact(ctx, id)
+	if database.IsNotFound(err) || (err == nil && c.ID == 0) {
+		return ErrContactNotFound
+	}
+	return err
+}
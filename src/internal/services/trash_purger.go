@@ -0,0 +1,62 @@
+package services
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Defaults of the trash purger
+const (
+	DefaultTrashRetention = 30 * 24 * time.Hour
+	DefaultPurgeInterval  = time.Hour
+)
+
+// TrashPurger permanently deletes contacts that stayed in the trash longer
+// than the retention
+type TrashPurger struct {
+	contacts  *ContactService
+	retention time.Duration
+	interval  time.Duration
+	now       func() time.Time
+}
+
+// NewTrashPurger creates a purger that checks the trash every interval
+func NewTrashPurger(contacts *ContactService, retention, interval time.Duration) *TrashPurger {
+	if retention <= 0 {
+		retention = DefaultTrashRetention
+	}
+	if interval <= 0 {
+		interval = DefaultPurgeInterval
+	}
+	return &TrashPurger{
+		contacts:  contacts,
+		retention: retention,
+		interval:  interval,
+		now:       time.Now,
+	}
+}
+
+// Start runs the purger until ctx is done
+func (p *TrashPurger) Start(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := p.Purge(ctx); err != nil {
+			log.Printf("Trash purger: %v", err)
+		} else if n > 0 {
+			log.Printf("Trash purger: purged %d contacts", n)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Purge deletes the contacts whose retention has passed
+func (p *TrashPurger) Purge(ctx context.Context) (int, error) {
+	return p.contacts.PurgeDeleted(ctx, p.now().Add(-p.retention))
+}
@@ -6,10 +6,13 @@ import (
 	"log"
 	"net/http"
 	"path/filepath"
+	"time"
 
 	"apidesign/config"
 	"apidesign/internal/database"
+	"apidesign/internal/event"
 	"apidesign/internal/middleware"
+	"apidesign/internal/reminder"
 
 	"github.com/gorilla/mux"
 )
@@ -34,9 +37,18 @@ func main() {
 	}
 	defer db.Close(ctx)
 
+	// Start the reminder scheduler
+	var notifier reminder.Notifier = &reminder.LogNotifier{}
+	if cfg.Reminders.Notifier == "file" {
+		notifier = reminder.NewFileNotifier(cfg.Reminders.File)
+	}
+	scheduler := reminder.NewScheduler(reminder.NewRepo(db), event.NewEventRepo(db), notifier,
+		time.Duration(cfg.Reminders.IntervalSeconds)*time.Second)
+	go scheduler.Start(ctx)
+
 	// Setup router
 	r := mux.NewRouter()
-	middleware.SetupRoutes(r, db)
+	middleware.SetupRoutes(ctx, r, db, scheduler, cfg)
 
 	// Start server
 	log.Printf("Starting server on %s", cfg.Port)            // Updated to use port from config